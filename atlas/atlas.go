@@ -48,7 +48,8 @@ func (a *Atlas) AllMaps() []Map {
 //	configured cache backend
 func (a *Atlas) SeedMapTile(ctx context.Context, m Map, z, x, y uint64) error {
 	//	confirm we have a cache backend
-	if a.cacher == nil {
+	cacher := a.GetCache()
+	if cacher == nil {
 		return ErrMissingCache
 	}
 
@@ -68,24 +69,76 @@ func (a *Atlas) SeedMapTile(ctx context.Context, m Map, z, x, y uint64) error {
 		Y:       int(y),
 	}
 
-	return a.cacher.Set(&key, b)
+	return cache.SetTTL(cacher, &key, b, m.TileTTL())
 }
 
-//	PurgeMapTile will purge a map tile from the configured cache backend
+//	PurgeMapTile will purge a map tile, at every high-DPI scale, from the configured cache backend
 func (a *Atlas) PurgeMapTile(m Map, tile *tegola.Tile) error {
-	if a.cacher == nil {
+	cacher := a.GetCache()
+	if cacher == nil {
 		return ErrMissingCache
 	}
 
-	//	cache key
-	key := cache.Key{
-		MapName: m.Name,
-		Z:       tile.Z,
-		X:       tile.X,
-		Y:       tile.Y,
+	for scale := 1; scale <= tegola.MaxTileScale; scale++ {
+		//	cache key
+		key := cache.Key{
+			MapName: m.Name,
+			Z:       tile.Z,
+			X:       tile.X,
+			Y:       tile.Y,
+			Scale:   scale,
+		}
+
+		if err := cacher.Purge(&key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//	PurgeMapTileRange will purge the map tiles of zoom z from the columns minX to maxX and the rows minY to maxY
+//	from the configured cache backend, at every high-DPI scale. caches which implement cache.RangePurger purge the tiles in bulk
+func (a *Atlas) PurgeMapTileRange(m Map, z, minX, minY, maxX, maxY uint64) error {
+	cacher := a.GetCache()
+	if cacher == nil {
+		return ErrMissingCache
+	}
+
+	for scale := 1; scale <= tegola.MaxTileScale; scale++ {
+		tr := cache.TileRange{
+			MapName: m.Name,
+			Z:       int(z),
+			MinX:    int(minX),
+			MinY:    int(minY),
+			MaxX:    int(maxX),
+			MaxY:    int(maxY),
+			Scale:   scale,
+		}
+
+		if err := cache.PurgeRange(cacher, tr); err != nil {
+			return err
+		}
 	}
 
-	return a.cacher.Purge(&key)
+	return nil
+}
+
+//	WriteCacheMetadata writes the TileJSON of each map to the configured cache backend,
+//	if the cache stores map details alongside the tiles (i.e. mbtiles)
+func (a *Atlas) WriteCacheMetadata() error {
+	mw, ok := a.GetCache().(cache.MetadataWriter)
+	if !ok {
+		return nil
+	}
+
+	for _, m := range a.AllMaps() {
+		if err := mw.WriteMetadata(m.Name, m.TileJSON(nil)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Map looks up a Map by name and returns a copy of the Map
@@ -122,14 +175,34 @@ func (a *Atlas) AddMap(m Map) {
 
 //	GetCache returns the registered cache if one is registered, otherwise nil
 func (a *Atlas) GetCache() cache.Interface {
+	a.RLock()
+	defer a.RUnlock()
+
 	return a.cacher
 }
 
 //	SetCache sets the cache backend
 func (a *Atlas) SetCache(c cache.Interface) {
+	a.Lock()
+	defer a.Unlock()
+
 	a.cacher = c
 }
 
+//	Swap atomically replaces the maps and cache backend of the Atlas with those of other.
+//	Maps previously returned by the Atlas are copies, so in-flight work on them is not affected
+func (a *Atlas) Swap(other *Atlas) {
+	other.RLock()
+	maps, cacher := other.maps, other.cacher
+	other.RUnlock()
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.maps = maps
+	a.cacher = cacher
+}
+
 //	AllMaps returns all registered maps in DefaultAtlas
 func AllMaps() []Map {
 	return DefaultAtlas.AllMaps()
@@ -161,6 +234,18 @@ func SeedMapTile(ctx context.Context, m Map, z, x, y uint64) error {
 	return DefaultAtlas.SeedMapTile(ctx, m, z, x, y)
 }
 
+//	PurgeMapTileRange will purge a range of map tiles from the configured cache backend
+//	for the DefaultAtlas
+func PurgeMapTileRange(m Map, z, minX, minY, maxX, maxY uint64) error {
+	return DefaultAtlas.PurgeMapTileRange(m, z, minX, minY, maxX, maxY)
+}
+
+//	WriteCacheMetadata writes the TileJSON of each map to the configured cache backend
+//	for the DefaultAtlas
+func WriteCacheMetadata() error {
+	return DefaultAtlas.WriteCacheMetadata()
+}
+
 //	PurgeMapTile will purge a map tile from the configured cache backend
 //	for the DefaultAtlas
 func PurgeMapTile(m Map, tile *tegola.Tile) error {
@@ -1,7 +1,12 @@
 package atlas_test
 
 import (
+	"testing"
+
+	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/memory"
 	"github.com/go-spatial/tegola/geom"
 	"github.com/go-spatial/tegola/provider/test"
 )
@@ -50,3 +55,103 @@ var testMap = atlas.Map{
 		testLayer3,
 	},
 }
+
+func TestAtlasSwap(t *testing.T) {
+	a := atlas.Atlas{}
+	a.AddMap(testMap)
+	a.SetCache(memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0))
+
+	newMap := atlas.NewWebMercatorMap("new-map")
+	b := atlas.Atlas{}
+	b.AddMap(newMap)
+
+	a.Swap(&b)
+
+	if _, err := a.Map(testMap.Name); err == nil {
+		t.Errorf("expected map (%v) to be removed by the swap", testMap.Name)
+	}
+
+	if _, err := a.Map(newMap.Name); err != nil {
+		t.Errorf("expected map (%v) to be added by the swap. err: %v", newMap.Name, err)
+	}
+
+	if a.GetCache() != nil {
+		t.Errorf("expected the cache to be replaced by the swap")
+	}
+}
+
+func TestAtlasPurgeScales(t *testing.T) {
+	type tcase struct {
+		purge func(a *atlas.Atlas) error
+		//	the tiles expected to be left in the cache
+		expected []cache.Key
+	}
+
+	//	every scale of the tile 2/1/1 and its neighbour 2/2/1
+	var keys []cache.Key
+	for scale := 1; scale <= tegola.MaxTileScale; scale++ {
+		keys = append(keys,
+			cache.Key{MapName: testMap.Name, Z: 2, X: 1, Y: 1, Scale: scale},
+			cache.Key{MapName: testMap.Name, Z: 2, X: 2, Y: 1, Scale: scale},
+		)
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		c := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+		a := atlas.Atlas{}
+		a.AddMap(testMap)
+		a.SetCache(c)
+
+		for i := range keys {
+			if err := c.Set(&keys[i], []byte{1}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		if err := tc.purge(&a); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var left []cache.Key
+		for i := range keys {
+			if _, hit, _ := c.Get(&keys[i]); hit {
+				left = append(left, keys[i])
+			}
+		}
+		if len(left) != len(tc.expected) {
+			t.Fatalf("expected %v tiles left got %v", tc.expected, left)
+		}
+		for i := range left {
+			if left[i] != tc.expected[i] {
+				t.Errorf("expected %v tiles left got %v", tc.expected, left)
+				break
+			}
+		}
+	}
+
+	var neighbours []cache.Key
+	for i := 1; i < len(keys); i += 2 {
+		neighbours = append(neighbours, keys[i])
+	}
+
+	tests := map[string]tcase{
+		"tile": {
+			purge: func(a *atlas.Atlas) error {
+				return a.PurgeMapTile(testMap, tegola.NewTile(2, 1, 1))
+			},
+			expected: neighbours,
+		},
+		"range": {
+			purge: func(a *atlas.Atlas) error {
+				return a.PurgeMapTileRange(testMap, 2, 1, 1, 2, 1)
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package atlas
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/mvt"
+)
+
+//	supported strategies for dropping features when a tile exceeds the map's MaxTileBytes
+const (
+	//	DropSmallestArea drops the features with the smallest area first. lines are measured by their
+	//	bounding box and points have no area so they are dropped before lines and polygons
+	DropSmallestArea = "smallest_area"
+	//	DropPriority drops the features with the lowest numeric value for the layer's DropPriorityTag first.
+	//	features without a numeric value for the tag are dropped first
+	DropPriority = "priority"
+	//	DropUniform thins the features evenly across the layer
+	DropUniform = "uniform"
+)
+
+//	budgetSlack is applied to the estimated number of features to keep so a tile
+//	converges on the budget in fewer encoding passes
+const budgetSlack = 0.9
+
+//	EncodeStats reports on the encoding of a tile
+type EncodeStats struct {
+	//	Bytes is the size of the encoded tile
+	Bytes int
+	//	DroppedFeatures is the number of features dropped to fit the tile within the map's MaxTileBytes
+	DroppedFeatures int
+	//	RepairedGeometries is the number of invalid polygons which were made valid
+	RepairedGeometries int
+	//	InvalidDroppedFeatures is the number of features dropped because their polygons were invalid
+	InvalidDroppedFeatures int
+}
+
+//	dropFeatures removes features from the layer using the layer's DropStrategy so the layer is
+//	approximately ratio of its current size. the number of features dropped is returned.
+//	layers without a DropStrategy are not modified.
+func (l Layer) dropFeatures(mvtLayer *mvt.Layer, ratio float64) int {
+	if mvtLayer == nil || l.DropStrategy == "" {
+		return 0
+	}
+
+	features := mvtLayer.Features()
+	if len(features) == 0 {
+		return 0
+	}
+
+	keep := int(float64(len(features)) * ratio * budgetSlack)
+	//	always make progress
+	if keep >= len(features) {
+		keep = len(features) - 1
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	var drop []int
+	switch l.DropStrategy {
+	case DropUniform:
+		drop = uniformDrops(len(features), keep)
+	default:
+		//	rank the features, lowest first, and drop from the bottom
+		ranks := make([]float64, len(features))
+		for i := range features {
+			if l.DropStrategy == DropPriority {
+				v, ok := numericTag(features[i].Tags[l.DropPriorityTag])
+				if !ok {
+					v = math.Inf(-1)
+				}
+				ranks[i] = v
+				continue
+			}
+			ranks[i] = geometryArea(features[i].Geometry)
+		}
+
+		idxs := make([]int, len(features))
+		for i := range idxs {
+			idxs[i] = i
+		}
+		//	stable so features with the same rank are dropped in the order they were fetched
+		sort.SliceStable(idxs, func(i, j int) bool {
+			return ranks[idxs[i]] < ranks[idxs[j]]
+		})
+
+		drop = idxs[:len(features)-keep]
+	}
+
+	mvtLayer.RemoveFeature(drop...)
+
+	return len(drop)
+}
+
+//	uniformDrops returns the indexes to drop from n items so keep items, evenly distributed, remain
+func uniformDrops(n, keep int) (drop []int) {
+	kept := make(map[int]bool, keep)
+	for i := 0; i < keep; i++ {
+		kept[i*n/keep] = true
+	}
+
+	for i := 0; i < n; i++ {
+		if !kept[i] {
+			drop = append(drop, i)
+		}
+	}
+
+	return drop
+}
+
+//	geometryArea returns the area of polygons and the bounding box area of other geometries
+func geometryArea(g tegola.Geometry) float64 {
+	switch geo := g.(type) {
+	case tegola.Polygon:
+		var area float64
+		for i, ring := range geo.Sublines() {
+			a := math.Abs(ringArea(ring))
+			//	the first ring is the exterior ring, the rest are holes
+			if i == 0 {
+				area += a
+			} else {
+				area -= a
+			}
+		}
+		return area
+	case tegola.MultiPolygon:
+		var area float64
+		for _, p := range geo.Polygons() {
+			area += geometryArea(p)
+		}
+		return area
+	default:
+		pts := geometryPoints(g)
+		if len(pts) == 0 {
+			return 0
+		}
+
+		minx, miny, maxx, maxy := pts[0].X(), pts[0].Y(), pts[0].X(), pts[0].Y()
+		for _, pt := range pts[1:] {
+			minx, maxx = math.Min(minx, pt.X()), math.Max(maxx, pt.X())
+			miny, maxy = math.Min(miny, pt.Y()), math.Max(maxy, pt.Y())
+		}
+		return (maxx - minx) * (maxy - miny)
+	}
+}
+
+//	geometryPoints returns the points of point and line geometries
+func geometryPoints(g tegola.Geometry) []tegola.Point {
+	switch geo := g.(type) {
+	case tegola.Point:
+		return []tegola.Point{geo}
+	case tegola.MultiPoint:
+		return geo.Points()
+	case tegola.LineString:
+		return geo.Subpoints()
+	case tegola.MultiLine:
+		var pts []tegola.Point
+		for _, l := range geo.Lines() {
+			pts = append(pts, l.Subpoints()...)
+		}
+		return pts
+	default:
+		return nil
+	}
+}
+
+//	ringArea calculates the signed area of a ring using the shoelace formula
+func ringArea(ring tegola.LineString) float64 {
+	pts := ring.Subpoints()
+
+	var area float64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].X()*pts[j].Y() - pts[j].X()*pts[i].Y()
+	}
+
+	return area / 2
+}
@@ -0,0 +1,77 @@
+package atlas
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/mvt"
+)
+
+func TestLayerDropFeatures(t *testing.T) {
+	id := func(i uint64) *uint64 { return &i }
+
+	square := func(size float64) basic.Polygon {
+		return basic.Polygon{basic.Line{{0, 0}, {size, 0}, {size, size}, {0, size}}}
+	}
+
+	features := []mvt.Feature{
+		{ID: id(1), Tags: map[string]interface{}{"rank": 3}, Geometry: square(4)},
+		{ID: id(2), Tags: map[string]interface{}{"rank": 1}, Geometry: square(1)},
+		{ID: id(3), Tags: map[string]interface{}{}, Geometry: basic.Point{1, 1}},
+		{ID: id(4), Tags: map[string]interface{}{"rank": 2.5}, Geometry: basic.Line{{0, 0}, {2, 2}}},
+	}
+
+	testcases := []struct {
+		layer    Layer
+		ratio    float64
+		expected []uint64
+	}{
+		{
+			//	no strategy, nothing is dropped
+			layer:    Layer{},
+			ratio:    0.5,
+			expected: []uint64{1, 2, 3, 4},
+		},
+		{
+			layer:    Layer{DropStrategy: DropSmallestArea},
+			ratio:    0.6,
+			expected: []uint64{1, 4},
+		},
+		{
+			layer:    Layer{DropStrategy: DropPriority, DropPriorityTag: "rank"},
+			ratio:    0.6,
+			expected: []uint64{1, 4},
+		},
+		{
+			layer:    Layer{DropStrategy: DropUniform},
+			ratio:    0.6,
+			expected: []uint64{1, 3},
+		},
+		{
+			//	at least one feature is always dropped
+			layer:    Layer{DropStrategy: DropSmallestArea},
+			ratio:    0.99,
+			expected: []uint64{1, 2, 4},
+		},
+	}
+
+	for i, tc := range testcases {
+		var l mvt.Layer
+		l.AddFeatures(features...)
+
+		dropped := tc.layer.dropFeatures(&l, tc.ratio)
+		if dropped != len(features)-len(tc.expected) {
+			t.Errorf("[%v] dropped, expected %v got %v", i, len(features)-len(tc.expected), dropped)
+		}
+
+		var ids []uint64
+		for _, f := range l.Features() {
+			ids = append(ids, *f.ID)
+		}
+
+		if !reflect.DeepEqual(ids, tc.expected) {
+			t.Errorf("[%v] remaining features, expected %v got %v", i, tc.expected, ids)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package atlas
+
+import (
+	"math"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/mvt"
+)
+
+const (
+	//	DefaultClusterRadius is the radius, in pixels, points are clustered within
+	DefaultClusterRadius = 40
+
+	//	ClusterPointCountTag is the tag holding the number of points a cluster represents
+	ClusterPointCountTag = "point_count"
+)
+
+//	supported cluster aggregation operations
+const (
+	ClusterAggregationSum  = "sum"
+	ClusterAggregationMin  = "min"
+	ClusterAggregationMax  = "max"
+	ClusterAggregationMean = "mean"
+)
+
+//	Cluster configures how the point features of a layer are clustered
+type Cluster struct {
+	//	Radius in pixels points are clustered within. Default: 40
+	Radius uint
+	//	MaxZoom is the highest zoom points are clustered at
+	MaxZoom uint
+	//	Aggregations are computed over the tags of the points in a cluster
+	Aggregations []ClusterAggregation
+}
+
+//	ClusterAggregation computes the tag Name of a cluster by applying Op (i.e. sum) to the Tag values
+//	of the points in the cluster. Non numeric values are ignored
+type ClusterAggregation struct {
+	Name string
+	Op   string
+	Tag  string
+}
+
+func (c Cluster) radius() float64 {
+	if c.Radius == 0 {
+		return DefaultClusterRadius
+	}
+
+	return float64(c.Radius)
+}
+
+//	clusterFeatures groups the point features within the cluster radius of each other into a single point
+//	feature positioned at the centroid of its points. pixelSize is the size of a pixel in the units of the
+//	feature geometries. Points which are not part of a cluster and non point features are returned untouched.
+//	Points are clustered in the order they were fetched so the same input always produces the same clusters.
+func (c Cluster) clusterFeatures(features []mvt.Feature, pixelSize float64) []mvt.Feature {
+	radius := c.radius() * pixelSize
+	if radius <= 0 {
+		return features
+	}
+
+	type cell [2]int64
+
+	cellOf := func(pt tegola.Point) cell {
+		return cell{int64(math.Floor(pt.X() / radius)), int64(math.Floor(pt.Y() / radius))}
+	}
+
+	//	index the points by grid cells the size of the radius so only neighboring cells are searched
+	grid := map[cell][]int{}
+	for i := range features {
+		pt, ok := features[i].Geometry.(tegola.Point)
+		if !ok {
+			continue
+		}
+		cl := cellOf(pt)
+		grid[cl] = append(grid[cl], i)
+	}
+
+	clustered := make([]bool, len(features))
+	out := make([]mvt.Feature, 0, len(features))
+
+	for i := range features {
+		if clustered[i] {
+			continue
+		}
+
+		pt, ok := features[i].Geometry.(tegola.Point)
+		if !ok {
+			out = append(out, features[i])
+			continue
+		}
+
+		members := []int{i}
+		clustered[i] = true
+
+		cl := cellOf(pt)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, j := range grid[cell{cl[0] + dx, cl[1] + dy}] {
+					if clustered[j] {
+						continue
+					}
+
+					npt := features[j].Geometry.(tegola.Point)
+					if math.Hypot(npt.X()-pt.X(), npt.Y()-pt.Y()) <= radius {
+						members = append(members, j)
+						clustered[j] = true
+					}
+				}
+			}
+		}
+
+		if len(members) == 1 {
+			out = append(out, features[i])
+			continue
+		}
+
+		out = append(out, c.clusterFeature(features, members))
+	}
+
+	return out
+}
+
+//	clusterFeature builds the feature representing the features at the members indexes
+func (c Cluster) clusterFeature(features []mvt.Feature, members []int) mvt.Feature {
+	var x, y float64
+	for _, m := range members {
+		pt := features[m].Geometry.(tegola.Point)
+		x += pt.X()
+		y += pt.Y()
+	}
+	n := float64(len(members))
+
+	tags := map[string]interface{}{
+		ClusterPointCountTag: len(members),
+	}
+
+	for _, agg := range c.Aggregations {
+		var val float64
+		var count int
+
+		for _, m := range members {
+			v, ok := numericTag(features[m].Tags[agg.Tag])
+			if !ok {
+				continue
+			}
+
+			switch {
+			case count == 0:
+				val = v
+			case agg.Op == ClusterAggregationSum, agg.Op == ClusterAggregationMean:
+				val += v
+			case agg.Op == ClusterAggregationMin:
+				val = math.Min(val, v)
+			case agg.Op == ClusterAggregationMax:
+				val = math.Max(val, v)
+			}
+			count++
+		}
+
+		//	none of the points had a numeric value for the tag
+		if count == 0 {
+			continue
+		}
+
+		if agg.Op == ClusterAggregationMean {
+			val = val / float64(count)
+		}
+
+		tags[agg.Name] = val
+	}
+
+	return mvt.Feature{
+		Tags:     tags,
+		Geometry: basic.Point{x / n, y / n},
+	}
+}
+
+//	numericTag converts a tag value to a float64
+func numericTag(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int8:
+		return float64(t), true
+	case int16:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint:
+		return float64(t), true
+	case uint8:
+		return float64(t), true
+	case uint16:
+		return float64(t), true
+	case uint32:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,81 @@
+package atlas
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/mvt"
+)
+
+func TestClusterFeatures(t *testing.T) {
+	id := func(i uint64) *uint64 { return &i }
+
+	testcases := []struct {
+		cluster   Cluster
+		pixelSize float64
+		features  []mvt.Feature
+		expected  []mvt.Feature
+	}{
+		{
+			//	two points within the radius and one outside of it
+			cluster: Cluster{
+				Radius: 10,
+				Aggregations: []ClusterAggregation{
+					{Name: "total", Op: ClusterAggregationSum, Tag: "value"},
+					{Name: "avg", Op: ClusterAggregationMean, Tag: "value"},
+					{Name: "largest", Op: ClusterAggregationMax, Tag: "value"},
+				},
+			},
+			pixelSize: 2,
+			features: []mvt.Feature{
+				{ID: id(1), Tags: map[string]interface{}{"value": 2}, Geometry: basic.Point{0, 0}},
+				{ID: id(2), Tags: map[string]interface{}{"value": 4.0}, Geometry: basic.Point{10, 0}},
+				{ID: id(3), Tags: map[string]interface{}{"value": 8}, Geometry: basic.Point{100, 100}},
+			},
+			expected: []mvt.Feature{
+				{
+					Tags: map[string]interface{}{
+						ClusterPointCountTag: 2,
+						"total":              6.0,
+						"avg":                3.0,
+						"largest":            4.0,
+					},
+					Geometry: basic.Point{5, 0},
+				},
+				{ID: id(3), Tags: map[string]interface{}{"value": 8}, Geometry: basic.Point{100, 100}},
+			},
+		},
+		{
+			//	non point features are not clustered and non numeric values are ignored
+			cluster: Cluster{
+				Aggregations: []ClusterAggregation{
+					{Name: "smallest", Op: ClusterAggregationMin, Tag: "value"},
+				},
+			},
+			pixelSize: 1,
+			features: []mvt.Feature{
+				{ID: id(1), Geometry: basic.Line{{0, 0}, {1, 1}}},
+				{ID: id(2), Tags: map[string]interface{}{"value": "foo"}, Geometry: basic.Point{0, 0}},
+				{ID: id(3), Tags: map[string]interface{}{"value": 7}, Geometry: basic.Point{20, 20}},
+			},
+			expected: []mvt.Feature{
+				{ID: id(1), Geometry: basic.Line{{0, 0}, {1, 1}}},
+				{
+					Tags: map[string]interface{}{
+						ClusterPointCountTag: 2,
+						"smallest":           7.0,
+					},
+					Geometry: basic.Point{10, 10},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testcases {
+		output := tc.cluster.clusterFeatures(tc.features, tc.pixelSize)
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("[%v] expected %+v got %+v", i, tc.expected, output)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package atlas
 
 import (
+	"time"
+
 	"github.com/go-spatial/tegola/geom"
 	"github.com/go-spatial/tegola/provider"
 )
@@ -10,7 +12,8 @@ type Layer struct {
 	Name              string
 	ProviderLayerName string
 	MinZoom           int
-	MaxZoom           int
+	//	MaxZoom of 0 means the layer is not bounded
+	MaxZoom int
 	//	instantiated provider
 	Provider provider.Tiler
 	//	default tags to include when encoding the layer. provider tags take precedence
@@ -19,6 +22,79 @@ type Layer struct {
 	//	DontSimplify indicates wheather feature simplification should be applied.
 	//	We use a negative in the name so the default is to simplify
 	DontSimplify bool
+	//	DontDeduplicate turns off skipping features whose ID has already been fetched for the tile.
+	//	useful for providers whose feature IDs are not unique
+	DontDeduplicate bool
+	//	TileExtent and TileBuffer override the map's TileExtent and TileBuffer
+	//	for this layer. a zero value means the map's value is used
+	TileExtent uint64
+	TileBuffer uint64
+	//	Cluster enables clustering the layer's point features. nil disables clustering
+	Cluster *Cluster
+	//	DropStrategy is how features are dropped from the layer when a tile exceeds the map's
+	//	MaxTileBytes (i.e. DropSmallestArea). an empty value means features are never dropped
+	DropStrategy string
+	//	DropPriorityTag is the numeric tag features are ranked by for the DropPriority strategy
+	DropPriorityTag string
+	//	MergeLinesBy are the tags touching lines must share the values of to be merged into a single feature
+	MergeLinesBy []string
+	//	DissolvePolygonsBy are the tags adjacent polygons must share the values of to be dissolved into a single feature
+	DissolvePolygonsBy []string
+	//	GeneralizeMaxZoom is the highest zoom lines are merged and polygons are dissolved at
+	GeneralizeMaxZoom uint
+	//	LabelLayer is the name of a companion point layer with a label point for each of the layer's polygons.
+	//	an empty value means no label layer is encoded
+	LabelLayer string
+	//	SimplifyTolerance overrides the default simplification tolerance (tegola.DefaultEpislon). 0 means the default is used
+	SimplifyTolerance float64
+	//	SimplifyTolerances sets the tolerance from a zoom on. the entry with the highest zoom
+	//	less than or equal to the tile's zoom is used. zooms without an entry use SimplifyTolerance
+	SimplifyTolerances map[uint]float64
+	//	SimplifyAlgorithm is the algorithm polygons are simplified with (i.e. mvt.SimplifyVisvalingam)
+	SimplifyAlgorithm string
+	//	PreserveTopology simplifies the boundaries shared between the layer's polygons identically
+	PreserveTopology bool
+	//	Validation is how the layer's geometries are validated (i.e. mvt.ValidationClipOnly). Default: mvt.ValidationMakeValid
+	Validation string
+	//	ComplexTags is how map tag values (i.e. hstore columns) are encoded (i.e. mvt.ComplexTagsFlatten). Default: mvt.ComplexTagsMerge
+	ComplexTags string
+	//	TagKeySeparator joins the keys of flattened maps. Default: mvt.DefaultTagKeySeparator
+	TagKeySeparator string
+	//	IntegerTags is the value type integer tags are encoded as (i.e. mvt.IntegerTagsSint). an empty value
+	//	means the type follows the provider's value
+	IntegerTags string
+	//	IDStrategy is how the provider's feature IDs are encoded (i.e. provider.FeatureIDHash). Default: provider.FeatureIDUint
+	IDStrategy string
+	//	IDTag is the tag the provider's feature ID is copied into. an empty value means the ID is not copied
+	IDTag string
+	//	CacheTTL is how long tiles with the layer are cached for. 0 means the layer doesn't shorten the map's CacheTTL
+	CacheTTL time.Duration
+}
+
+//	simplifyTolerance returns the simplification tolerance for the zoom. false is returned when the
+//	layer does not override the default tolerance
+func (l Layer) simplifyTolerance(zoom uint) (float64, bool) {
+	tolerance, found := l.SimplifyTolerance, l.SimplifyTolerance != 0
+
+	//	the entry with the highest zoom not greater than the tile's zoom wins
+	best := -1
+	for z, t := range l.SimplifyTolerances {
+		if z <= zoom && int(z) > best {
+			best, tolerance, found = int(z), t, true
+		}
+	}
+
+	return tolerance, found
+}
+
+//	featureID encodes the ID of the provider feature using the layer's IDStrategy
+func (l Layer) featureID(f *provider.Feature) (*uint64, error) {
+	var v interface{} = f.ID
+	if f.IDValue != nil {
+		v = f.IDValue
+	}
+
+	return provider.EncodeFeatureID(v, l.IDStrategy)
 }
 
 //	MVTName will return the value that will be encoded in the Name field when the layer is encoded as MVT
@@ -6,6 +6,7 @@ import (
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 
@@ -29,6 +30,9 @@ func NewWebMercatorMap(name string) Map {
 		SRID:       tegola.WebMercator,
 		TileExtent: 4096,
 		TileBuffer: 64,
+		TileSize:   tegola.DefaultTileSize,
+		MinZoom:    0,
+		MaxZoom:    MaxZoom,
 	}
 }
 
@@ -50,6 +54,21 @@ type Map struct {
 	//	MVT output values
 	TileExtent uint64
 	TileBuffer uint64
+	//	TileSize is the size in pixels the map's tiles are intended to be rendered at.
+	//	tiles larger than tegola.DefaultTileSize have their extent and buffer scaled up
+	//	accordingly so clipping and simplification retain the extra detail.
+	//	Default: 256
+	TileSize uint64
+	//	MinZoom and MaxZoom bound the zoom levels tiles are available for.
+	//	Default: 0 - 22
+	MinZoom uint
+	MaxZoom uint
+	//	MaxTileBytes is the budget, in bytes, for an encoded tile. when a tile exceeds the budget
+	//	features are dropped from the layers which have a DropStrategy until the tile fits.
+	//	Default: 0 (no budget)
+	MaxTileBytes uint64
+	//	CacheTTL is how long the map's tiles are cached for. 0 means the tiles don't expire
+	CacheTTL time.Duration
 }
 
 // AddDebugLayers returns a copy of a Map with the debug layers appended to the layer list
@@ -84,12 +103,13 @@ func (m Map) AddDebugLayers() Map {
 	return m
 }
 
-// FilterLayersByZoom returns a copy of a Map with a subset of layers that match the given zoom
+// FilterLayersByZoom returns a copy of a Map with a subset of layers that match the given zoom.
+// A layer MaxZoom of 0 means the layer is not bounded
 func (m Map) FilterLayersByZoom(zoom int) Map {
 	var layers []Layer
 
 	for i := range m.Layers {
-		if (m.Layers[i].MinZoom <= zoom || m.Layers[i].MinZoom == 0) && (m.Layers[i].MaxZoom >= zoom || m.Layers[i].MaxZoom == 0) {
+		if m.Layers[i].MinZoom <= zoom && (m.Layers[i].MaxZoom >= zoom || m.Layers[i].MaxZoom == 0) {
 			layers = append(layers, m.Layers[i])
 			continue
 		}
@@ -101,6 +121,33 @@ func (m Map) FilterLayersByZoom(zoom int) Map {
 	return m
 }
 
+// ValidTile reports whether the tile is within the map's zoom range and exists in the tile grid at its zoom
+func (m Map) ValidTile(z, x, y uint64) bool {
+	if z < uint64(m.MinZoom) || z > uint64(m.MaxZoom) {
+		return false
+	}
+
+	max := uint64(1) << z
+	return x < max && y < max
+}
+
+// HasTile reports whether the tile is valid for the map and intersects the map's Bounds.
+// Tiles outside of the map don't need to be fetched from the providers or the cache
+func (m Map) HasTile(z, x, y uint64) bool {
+	if !m.ValidTile(z, x, y) {
+		return false
+	}
+
+	//	tile bounds in WGS84. the top left corner of the tile and the top left corner of the tile diagonal to it
+	nw := tegola.Tile{Z: int(z), X: int(x), Y: int(y)}
+	se := tegola.Tile{Z: int(z), X: int(x) + 1, Y: int(y) + 1}
+	maxLat, minLng := nw.Num2Deg()
+	minLat, maxLng := se.Num2Deg()
+
+	//	Bounds are in the order: left, bottom, right, top
+	return minLng <= m.Bounds[2] && maxLng >= m.Bounds[0] && minLat <= m.Bounds[3] && maxLat >= m.Bounds[1]
+}
+
 // FilterLayersByName returns a copy of a Map witha subset of layers that match the supplied list of layer names
 func (m Map) FilterLayersByName(names ...string) Map {
 	var layers []Layer
@@ -123,16 +170,98 @@ func (m Map) FilterLayersByName(names ...string) Map {
 	return m
 }
 
+//	TileTTL returns how long the map's tiles are cached for: the shortest CacheTTL of the map and its layers.
+//	0 means the tiles don't expire. Filter the layers by zoom or name first for the TTL of those tiles
+func (m Map) TileTTL() time.Duration {
+	ttl := m.CacheTTL
+	for i := range m.Layers {
+		if l := m.Layers[i].CacheTTL; l != 0 && (ttl == 0 || l < ttl) {
+			ttl = l
+		}
+	}
+
+	return ttl
+}
+
+// ScaleTileSize returns a copy of a Map with the TileSize multiplied by scale. This is used
+// to serve high-DPI (i.e. @2x) variants of a map
+func (m Map) ScaleTileSize(scale uint64) Map {
+	m.TileSize = m.tileSize() * scale
+
+	return m
+}
+
+func (m Map) tileSize() uint64 {
+	if m.TileSize == 0 {
+		return tegola.DefaultTileSize
+	}
+
+	return m.TileSize
+}
+
+//	tileScale returns the factor the map's tile size is larger than tegola.DefaultTileSize
+func (m Map) tileScale() uint64 {
+	if m.tileSize() <= tegola.DefaultTileSize {
+		return 1
+	}
+
+	return m.tileSize() / tegola.DefaultTileSize
+}
+
+//	LayerTileExtent returns the MVT extent the layer will be encoded with. The layer's
+//	TileExtent takes precedence over the map's TileExtent. If neither is set
+//	tegola.DefaultExtent is used. The extent is scaled by the map's TileSize
+func (m Map) LayerTileExtent(l Layer) uint64 {
+	if l.TileExtent != 0 {
+		return l.TileExtent * m.tileScale()
+	}
+	if m.TileExtent != 0 {
+		return m.TileExtent * m.tileScale()
+	}
+
+	return tegola.DefaultExtent * m.tileScale()
+}
+
+//	LayerTileBuffer returns the buffer, in tile extent units, the layer will be fetched and clipped with.
+//	The layer's TileBuffer takes precedence over the map's TileBuffer. If neither is set
+//	tegola.DefaultTileBuffer is used. The buffer is scaled by the map's TileSize
+func (m Map) LayerTileBuffer(l Layer) uint64 {
+	if l.TileBuffer != 0 {
+		return l.TileBuffer * m.tileScale()
+	}
+	if m.TileBuffer != 0 {
+		return m.TileBuffer * m.tileScale()
+	}
+
+	return tegola.DefaultTileBuffer * m.tileScale()
+}
+
 //	TODO (arolek): support for max zoom
 func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
-	// tile container
-	var mvtTile mvt.Tile
+	b, _, err := m.EncodeWithStats(ctx, tile)
+	return b, err
+}
+
+//	EncodeWithStats encodes the tile like Encode and additionally reports the size of the tile,
+//	the number of features dropped to fit the tile within the map's MaxTileBytes and the invalid
+//	geometries which were repaired or dropped
+func (m Map) EncodeWithStats(ctx context.Context, tile *slippy.Tile) ([]byte, EncodeStats, error) {
+	var stats EncodeStats
+
 	// wait group for concurrent layer fetching
 	var wg sync.WaitGroup
 
 	// layer stack
 	mvtLayers := make([]*mvt.Layer, len(m.Layers))
 
+	z, x, y := tile.ZXY()
+
+	// TODO (arolek): change out the tile type for VTile. tegola.Tile will be deprecated
+	tegolaTile := tegola.NewTile(int(z), int(x), int(y))
+	tegolaTile.Extent = float64(m.LayerTileExtent(Layer{}))
+	tegolaTile.Buffer = float64(m.LayerTileBuffer(Layer{}))
+	tegolaTile.Init()
+
 	// set our waitgroup count
 	wg.Add(len(m.Layers))
 
@@ -142,19 +271,64 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 		// go routine for fetching the layer concurrently
 		go func(i int, l Layer) {
 			mvtLayer := mvt.Layer{
-				Name:         l.MVTName(),
-				DontSimplify: l.DontSimplify,
+				Name:              l.MVTName(),
+				DontSimplify:      l.DontSimplify,
+				DontDeduplicate:   l.DontDeduplicate,
+				LabelLayer:        l.LabelLayer,
+				SimplifyAlgorithm: l.SimplifyAlgorithm,
+				PreserveTopology:  l.PreserveTopology,
+				Validation:        l.Validation,
+				ComplexTags:       l.ComplexTags,
+				TagKeySeparator:   l.TagKeySeparator,
+				IntegerTags:       l.IntegerTags,
+			}
+
+			extent, buffer := m.LayerTileExtent(l), m.LayerTileBuffer(l)
+			mvtLayer.SetExtent(int(extent))
+			mvtLayer.SetBuffer(int(buffer))
+
+			if tolerance, ok := l.simplifyTolerance(uint(z)); ok {
+				mvtLayer.SetTolerance(tolerance)
+			}
+
+			if uint(z) <= l.GeneralizeMaxZoom {
+				mvtLayer.MergeLinesBy = l.MergeLinesBy
+				mvtLayer.DissolvePolygonsBy = l.DissolvePolygonsBy
 			}
 
 			// on completion let the wait group know
 			defer wg.Done()
 
+			// the provider expects the buffer relative to the default extent, so scale the layer buffer accordingly
+			layerTile := slippy.NewTile(z, x, y, float64(buffer)*tegola.DefaultExtent/float64(extent), tile.SRID)
+
+			//	when clustering, the features are collected and clustered once they have all been fetched
+			cluster := l.Cluster != nil && uint(z) <= l.Cluster.MaxZoom
+			var features []mvt.Feature
+
 			//	fetch layer from data provider
-			err := l.Provider.TileFeatures(ctx, l.ProviderLayerName, tile, func(f *provider.Feature) error {
-				// TODO: remove this geom conversion step once the mvt package has adopted the new geom package
-				geo, err := convert.ToTegola(f.Geometry)
-				if err != nil {
-					return err
+			//	the mvt package encodes the geom types natively. clustering and dropping features still need the legacy types
+			legacy := cluster || (m.MaxTileBytes > 0 && l.DropStrategy != "")
+
+			//	unless the features are clustered or dropped, they are encoded as they are fetched so the
+			//	layer doesn't hold on to their geometries. layers which need all of their features at once
+			//	to be encoded don't support streaming and fall back to encoding the features at the end
+			if !legacy {
+				if err := mvtLayer.Stream(ctx, tegolaTile); err != nil && err != mvt.ErrStreamingUnsupported {
+					log.Printf("err streaming tile (z: %v, x: %v, y: %v) layer (%v): %v", z, x, y, l.MVTName(), err)
+					return
+				}
+			}
+
+			err := l.Provider.TileFeatures(ctx, l.ProviderLayerName, layerTile, func(f *provider.Feature) error {
+				var geo tegola.Geometry = f.Geometry
+
+				// TODO: remove this geom conversion step once reprojection has adopted the new geom package
+				if legacy || f.SRID != m.SRID {
+					var err error
+					if geo, err = convert.ToTegola(f.Geometry); err != nil {
+						return err
+					}
 				}
 
 				// check if the feature SRID and map SRID are different. If they are then reporject
@@ -167,6 +341,10 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 					geo = g.Geometry
 				}
 
+				//	expand map tags (i.e. hstore columns) first so their entries take precedence over the
+				//	default tags and can be read to rank, cluster or merge the features
+				f.Tags = mvtLayer.ExpandTags(f.Tags)
+
 				// add default tags, but don't overwrite a tag that already exists
 				for k, v := range l.DefaultTags {
 					if _, ok := f.Tags[k]; !ok {
@@ -174,11 +352,38 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 					}
 				}
 
-				mvtLayer.AddFeatures(mvt.Feature{
-					ID:       &f.ID,
-					Tags:     f.Tags,
+				id, err := l.featureID(f)
+				if err != nil {
+					return fmt.Errorf("unable to encode the id of feature (%v) of layer (%v): %v", f.IDValue, l.MVTName(), err)
+				}
+
+				//	copy the provider's ID into the id tag, replacing any tag with the same name. the tags are
+				//	copied first as the provider's map may be shared (i.e. by the features of a cached query)
+				tags := f.Tags
+				if l.IDTag != "" {
+					tags = make(map[string]interface{}, len(f.Tags)+1)
+					for k, v := range f.Tags {
+						tags[k] = v
+					}
+					if f.IDValue != nil {
+						tags[l.IDTag] = f.IDValue
+					} else {
+						tags[l.IDTag] = f.ID
+					}
+				}
+
+				feature := mvt.Feature{
+					ID:       id,
+					Tags:     tags,
 					Geometry: geo,
-				})
+				}
+
+				if cluster {
+					features = append(features, feature)
+					return nil
+				}
+
+				mvtLayer.AddFeatures(feature)
 
 				return nil
 			})
@@ -187,7 +392,6 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 				case context.Canceled:
 					// TODO (arolek): add debug logs
 				default:
-					z, x, y := tile.ZXY()
 					// TODO (arolek): should we return an error to the response or just log the error?
 					// we can't just write to the response as the waitgroup is going to write to the response as well
 					log.Printf("err fetching tile (z: %v, x: %v, y: %v) features: %v", z, x, y, err)
@@ -195,6 +399,14 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 				return
 			}
 
+			if cluster {
+				//	cluster in pixel space: the size of a pixel in the units of the tile extent
+				ext, _ := layerTile.Extent()
+				pixelSize := (ext[1][0] - ext[0][0]) / float64(m.tileSize())
+
+				mvtLayer.AddFeatures(l.Cluster.clusterFeatures(features, pixelSize)...)
+			}
+
 			// add the layer to the slice position
 			mvtLayers[i] = &mvtLayer
 		}(i, layer)
@@ -207,19 +419,53 @@ func (m Map) Encode(ctx context.Context, tile *slippy.Tile) ([]byte, error) {
 	// otherwise the server continues processing even if the request was canceled
 	// as the waitgroup was not notified of the cancel
 	if ctx.Err() != nil {
-		return nil, ctx.Err()
+		return nil, stats, ctx.Err()
 	}
 
-	//	add layers to our tile
-	mvtTile.AddLayers(mvtLayers...)
+	b, err := encodeLayers(ctx, tegolaTile, mvtLayers)
+	if err != nil {
+		return nil, stats, err
+	}
 
-	z, x, y := tile.ZXY()
+	//	drop features until the tile fits the budget or there is nothing left to drop
+	for m.MaxTileBytes > 0 && uint64(len(b)) > m.MaxTileBytes {
+		ratio := float64(m.MaxTileBytes) / float64(len(b))
 
-	// TODO (arolek): change out the tile type for VTile. tegola.Tile will be deprecated
-	tegolaTile := tegola.NewTile(int(z), int(x), int(y))
+		var dropped int
+		for i := range m.Layers {
+			dropped += m.Layers[i].dropFeatures(mvtLayers[i], ratio)
+		}
+		if dropped == 0 {
+			break
+		}
+		stats.DroppedFeatures += dropped
+
+		if b, err = encodeLayers(ctx, tegolaTile, mvtLayers); err != nil {
+			return nil, stats, err
+		}
+	}
+
+	stats.Bytes = len(b)
+
+	for i := range mvtLayers {
+		vs := mvtLayers[i].ValidationStats()
+		stats.RepairedGeometries += vs.Repaired
+		stats.InvalidDroppedFeatures += vs.Dropped
+	}
+
+	return b, stats, nil
+}
+
+//	encodeLayers encodes the layers into a vector tile
+func encodeLayers(ctx context.Context, tile *tegola.Tile, layers []*mvt.Layer) ([]byte, error) {
+	// tile container
+	var mvtTile mvt.Tile
+
+	//	add layers to our tile
+	mvtTile.AddLayers(layers...)
 
 	// generate our tile
-	vtile, err := mvtTile.VTile(ctx, tegolaTile)
+	vtile, err := mvtTile.VTile(ctx, tile)
 	if err != nil {
 		return nil, err
 	}
@@ -10,8 +10,11 @@ import (
 
 	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/geom"
 	"github.com/go-spatial/tegola/geom/slippy"
+	"github.com/go-spatial/tegola/mvt"
 	"github.com/go-spatial/tegola/mvt/vector_tile"
+	"github.com/go-spatial/tegola/provider"
 	"github.com/go-spatial/tegola/provider/test"
 )
 
@@ -78,6 +81,29 @@ func TestMapFilterLayersByZoom(t *testing.T) {
 				},
 			},
 		},
+		{
+			//	a max zoom of 0 is not bounded
+			atlasMap: atlas.Map{
+				Layers: []atlas.Layer{
+					{
+						Name: "layer1",
+					},
+					{
+						Name:    "layer2",
+						MinZoom: 3,
+						MaxZoom: 5,
+					},
+				},
+			},
+			zoom: 10,
+			expected: atlas.Map{
+				Layers: []atlas.Layer{
+					{
+						Name: "layer1",
+					},
+				},
+			},
+		},
 	}
 
 	for i, tc := range testcases {
@@ -89,6 +115,38 @@ func TestMapFilterLayersByZoom(t *testing.T) {
 	}
 }
 
+func TestMapHasTile(t *testing.T) {
+	m := atlas.NewWebMercatorMap("bounded")
+	m.MinZoom = 2
+	m.MaxZoom = 10
+	m.Bounds = [4]float64{-10, 35, 30, 60}
+
+	testcases := []struct {
+		z, x, y  uint64
+		valid    bool
+		expected bool
+	}{
+		{z: 2, x: 1, y: 1, valid: true, expected: true},
+		{z: 2, x: 2, y: 1, valid: true, expected: true},
+		//	outside of the bounds
+		{z: 2, x: 0, y: 2, valid: true, expected: false},
+		//	outside of the zoom range
+		{z: 1, x: 0, y: 0, valid: false, expected: false},
+		{z: 11, x: 1024, y: 680, valid: false, expected: false},
+		//	outside of the tile grid
+		{z: 2, x: 4, y: 1, valid: false, expected: false},
+	}
+
+	for i, tc := range testcases {
+		if valid := m.ValidTile(tc.z, tc.x, tc.y); valid != tc.valid {
+			t.Errorf("[%v] valid tile, expected %v got %v", i, tc.valid, valid)
+		}
+		if has := m.HasTile(tc.z, tc.x, tc.y); has != tc.expected {
+			t.Errorf("[%v] has tile, expected %v got %v", i, tc.expected, has)
+		}
+	}
+}
+
 func TestMapFilterLayersByName(t *testing.T) {
 	testcases := []struct {
 		grid     atlas.Map
@@ -211,6 +269,46 @@ func TestEncode(t *testing.T) {
 				},
 			},
 		},
+		{
+			grid: atlas.Map{
+				TileExtent: 4096,
+				TileBuffer: 64,
+				Layers: []atlas.Layer{
+					{
+						Name:       "layer1",
+						MinZoom:    0,
+						MaxZoom:    2,
+						Provider:   &test.TileProvider{},
+						TileExtent: 8192,
+						TileBuffer: 128,
+					},
+				},
+			},
+			tile: slippy.NewTile(2, 3, 4, 64, tegola.WebMercator),
+			expected: vectorTile.Tile{
+				Layers: []*vectorTile.Tile_Layer{
+					{
+						Version: p.Uint32(2),
+						Name:    p.String("layer1"),
+						Features: []*vectorTile.Tile_Feature{
+							{
+								Id:       p.Uint64(0),
+								Tags:     []uint32{0, 0},
+								Type:     &polygon,
+								Geometry: []uint32{9, 0, 0, 26, 16384, 0, 0, 16384, 16383, 0, 15},
+							},
+						},
+						Keys: []string{"type"},
+						Values: []*vectorTile.Tile_Value{
+							{
+								StringValue: p.String("debug_buffer_outline"),
+							},
+						},
+						Extent: p.Uint32(8192),
+					},
+				},
+			},
+		},
 	}
 
 	for i, tc := range testcases {
@@ -317,3 +415,192 @@ func TestEncode(t *testing.T) {
 		}
 	}
 }
+
+// sharedTagsProvider returns two features of the tile which share a tags map, as providers
+// reusing the tags of a query do
+type sharedTagsProvider struct {
+	test.TileProvider
+	tags map[string]interface{}
+}
+
+func (tp *sharedTagsProvider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	ext, srid := t.Extent()
+
+	for i, id := range []string{"a", "b"} {
+		f := provider.Feature{
+			IDValue: id,
+			Geometry: geom.Point{
+				ext[0][0] + (ext[1][0]-ext[0][0])*float64(i+1)/3,
+				ext[0][1] + (ext[1][1]-ext[0][1])/2,
+			},
+			SRID: srid,
+			Tags: tp.tags,
+		}
+		if err := fn(&f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestEncodeIDTag(t *testing.T) {
+	tp := sharedTagsProvider{
+		tags: map[string]interface{}{"name": "shared"},
+	}
+
+	m := atlas.Map{
+		Layers: []atlas.Layer{
+			{
+				Name:       "layer",
+				MinZoom:    0,
+				MaxZoom:    2,
+				Provider:   &tp,
+				IDStrategy: provider.FeatureIDHash,
+				IDTag:      "id",
+			},
+		},
+	}
+
+	out, err := m.Encode(context.Background(), slippy.NewTile(2, 3, 4, 64, tegola.WebMercator))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	//	the provider's tags are left as is
+	if expected := map[string]interface{}{"name": "shared"}; !reflect.DeepEqual(tp.tags, expected) {
+		t.Errorf("provider tags, expected %v got %v", expected, tp.tags)
+	}
+
+	var tile vectorTile.Tile
+	if err := proto.Unmarshal(out, &tile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tile.Layers) != 1 {
+		t.Fatalf("expected 1 layer got %v", len(tile.Layers))
+	}
+	layer := tile.Layers[0]
+
+	//	each feature is tagged with its own id
+	var ids []string
+	for _, f := range layer.Features {
+		for i := 0; i+1 < len(f.Tags); i += 2 {
+			if layer.Keys[f.Tags[i]] == "id" {
+				ids = append(ids, layer.Values[f.Tags[i+1]].GetStringValue())
+			}
+		}
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("id tags, expected %v got %v", expected, ids)
+	}
+}
+
+// invalidPolygonProvider returns a self intersecting polygon for every tile
+type invalidPolygonProvider struct{}
+
+func (invalidPolygonProvider) Layers() ([]provider.LayerInfo, error) { return nil, nil }
+
+func (invalidPolygonProvider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	ext, srid := t.Extent()
+	minx, miny := ext[0][0], ext[0][1]
+	dx, dy := (ext[1][0]-ext[0][0])/4, (ext[1][1]-ext[0][1])/4
+
+	//	a bowtie
+	f := provider.Feature{
+		ID: 1,
+		Geometry: geom.Polygon{{
+			{minx + dx, miny + dy},
+			{minx + 3*dx, miny + 3*dy},
+			{minx + 3*dx, miny + dy},
+			{minx + dx, miny + 3*dy},
+		}},
+		SRID: srid,
+	}
+
+	return fn(&f)
+}
+
+func TestEncodeWithStatsValidation(t *testing.T) {
+	type tcase struct {
+		layer atlas.Layer
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		tc.layer.Name = "layer"
+		tc.layer.MaxZoom = 2
+		tc.layer.Provider = invalidPolygonProvider{}
+		tc.layer.Validation = mvt.ValidationStrictReject
+
+		m := atlas.Map{Layers: []atlas.Layer{tc.layer}}
+
+		_, stats, err := m.EncodeWithStats(context.Background(), slippy.NewTile(2, 1, 1, 64, tegola.WebMercator))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats.InvalidDroppedFeatures != 1 {
+			t.Errorf("expected 1 dropped feature got %v", stats.InvalidDroppedFeatures)
+		}
+	}
+
+	tests := map[string]tcase{
+		"streaming": {},
+		//	label layers are not streamed
+		"not streaming": {
+			layer: atlas.Layer{LabelLayer: "labels"},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestEncodeMapTags(t *testing.T) {
+	tp := sharedTagsProvider{
+		tags: map[string]interface{}{
+			//	i.e. an hstore column
+			"tags": map[string]interface{}{"name": "provider", "surface": "asphalt"},
+		},
+	}
+
+	m := atlas.Map{
+		Layers: []atlas.Layer{
+			{
+				Name:        "layer",
+				MaxZoom:     2,
+				Provider:    &tp,
+				IDStrategy:  provider.FeatureIDHash,
+				DefaultTags: map[string]interface{}{"name": "default", "class": "road"},
+			},
+		},
+	}
+
+	out, err := m.Encode(context.Background(), slippy.NewTile(2, 3, 4, 64, tegola.WebMercator))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tile vectorTile.Tile
+	if err := proto.Unmarshal(out, &tile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tile.Layers) != 1 || len(tile.Layers[0].Features) == 0 {
+		t.Fatalf("expected 1 layer with features got %v", tile.Layers)
+	}
+	layer := tile.Layers[0]
+
+	//	the provider's values take precedence over the default tags
+	expected := map[string]string{"name": "provider", "surface": "asphalt", "class": "road"}
+	for _, f := range layer.Features {
+		tags := map[string]string{}
+		for i := 0; i+1 < len(f.Tags); i += 2 {
+			tags[layer.Keys[f.Tags[i]]] = layer.Values[f.Tags[i+1]].GetStringValue()
+		}
+		if !reflect.DeepEqual(tags, expected) {
+			t.Errorf("expected tags %v got %v", expected, tags)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package atlas
+
+import (
+	"github.com/go-spatial/tegola/geom"
+	"github.com/go-spatial/tegola/mapbox/tilejson"
+)
+
+//	TileJSON returns the details of the map according to the
+//	tileJSON spec (https://github.com/mapbox/tilejson-spec/tree/master/2.1.0)
+//
+//	tileURL returns the URL template of the tiles of a layer, or of the map when layerName is empty.
+//	when tileURL is nil the TileJSON has no tile URLs
+func (m Map) TileJSON(tileURL func(layerName string) string) tilejson.TileJSON {
+	tileJSON := tilejson.TileJSON{
+		Attribution: &m.Attribution,
+		Bounds:      m.Bounds,
+		Center:      m.Center,
+		Format:      "pbf",
+		Name:        &m.Name,
+		Scheme:      tilejson.SchemeXYZ,
+		TileJSON:    tilejson.Version,
+		Version:     "1.0.0",
+		TileSize:    int(m.TileSize),
+		Grids:       make([]string, 0),
+		Data:        make([]string, 0),
+	}
+
+	for i := range m.Layers {
+		//	check if the layer already exists in our slice. this can happen if the config
+		//	is using the "name" param for a layer to override the providerLayerName
+		var skip bool
+		for j := range tileJSON.VectorLayers {
+			if tileJSON.VectorLayers[j].ID == m.Layers[i].MVTName() {
+				//	we need to use the min and max of all layers with this name
+				if tileJSON.VectorLayers[j].MinZoom > m.Layers[i].MinZoom {
+					tileJSON.VectorLayers[j].MinZoom = m.Layers[i].MinZoom
+				}
+
+				if tileJSON.VectorLayers[j].MaxZoom < m.Layers[i].MaxZoom {
+					tileJSON.VectorLayers[j].MaxZoom = m.Layers[i].MaxZoom
+				}
+
+				skip = true
+				break
+			}
+		}
+		//	entry for layer already exists. move on
+		if skip {
+			continue
+		}
+
+		//	the first layer sets the initial min / max otherwise they default to 0/0
+		if len(tileJSON.VectorLayers) == 0 {
+			tileJSON.MinZoom = m.Layers[i].MinZoom
+			tileJSON.MaxZoom = m.Layers[i].MaxZoom
+		}
+
+		//	check if we have a min zoom lower then our current min
+		if tileJSON.MinZoom > m.Layers[i].MinZoom {
+			tileJSON.MinZoom = m.Layers[i].MinZoom
+		}
+
+		//	check if we have a max zoom higher then our current max
+		if tileJSON.MaxZoom < m.Layers[i].MaxZoom {
+			tileJSON.MaxZoom = m.Layers[i].MaxZoom
+		}
+
+		//	build our vector layer details
+		layer := tilejson.VectorLayer{
+			Version: 2,
+			Extent:  int(m.LayerTileExtent(m.Layers[i])),
+			ID:      m.Layers[i].MVTName(),
+			Name:    m.Layers[i].MVTName(),
+			MinZoom: m.Layers[i].MinZoom,
+			MaxZoom: m.Layers[i].MaxZoom,
+		}
+
+		if tileURL != nil {
+			layer.Tiles = []string{tileURL(m.Layers[i].MVTName())}
+		}
+
+		switch m.Layers[i].GeomType.(type) {
+		case geom.Point, geom.MultiPoint:
+			layer.GeometryType = tilejson.GeomTypePoint
+		case geom.Line, geom.LineString, geom.MultiLineString:
+			layer.GeometryType = tilejson.GeomTypeLine
+		case geom.Polygon, geom.MultiPolygon:
+			layer.GeometryType = tilejson.GeomTypePolygon
+		default:
+			layer.GeometryType = tilejson.GeomTypeUnknown
+			//	TODO: debug log
+		}
+
+		//	add our layer to our tile layer response
+		tileJSON.VectorLayers = append(tileJSON.VectorLayers, layer)
+
+		//	the label layer is encoded alongside the layer
+		if m.Layers[i].LabelLayer != "" {
+			labelLayer := layer
+			labelLayer.ID = m.Layers[i].LabelLayer
+			labelLayer.Name = m.Layers[i].LabelLayer
+			labelLayer.GeometryType = tilejson.GeomTypePoint
+
+			tileJSON.VectorLayers = append(tileJSON.VectorLayers, labelLayer)
+		}
+	}
+
+	//	tiles are not served outside of the map's zoom range
+	if tileJSON.MinZoom < int(m.MinZoom) {
+		tileJSON.MinZoom = int(m.MinZoom)
+	}
+	if tileJSON.MaxZoom > int(m.MaxZoom) {
+		tileJSON.MaxZoom = int(m.MaxZoom)
+	}
+
+	//	build our URL scheme for the tile grid
+	if tileURL != nil {
+		tileJSON.Tiles = append(tileJSON.Tiles, tileURL(""))
+	}
+
+	return tileJSON
+}
@@ -2,11 +2,15 @@ package cache
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/go-spatial/tegola/mapbox/tilejson"
 )
 
 //	Interface defines a cache back end
@@ -16,6 +20,132 @@ type Interface interface {
 	Purge(key *Key) error
 }
 
+//	Expirer is implemented by caches which support expiring tiles natively
+type Expirer interface {
+	//	SetTTL writes the tile to the cache. the tile expires once it's older than the ttl.
+	//	a ttl of 0 means the tile doesn't expire
+	SetTTL(key *Key, val []byte, ttl time.Duration) error
+	//	GetTTL reads the tile from the cache. tiles older than the ttl are stale. stale tiles the cache
+	//	still holds are returned with stale set, so they can be served while they are regenerated.
+	//	a ttl of 0 means the ttl the tile was written with is used
+	GetTTL(key *Key, ttl time.Duration) (val []byte, hit bool, stale bool, err error)
+}
+
+//	SetTTL writes the tile to the cache with the ttl. the ttl is ignored by caches which don't implement Expirer
+func SetTTL(c Interface, key *Key, val []byte, ttl time.Duration) error {
+	if e, ok := c.(Expirer); ok && ttl > 0 {
+		return e.SetTTL(key, val, ttl)
+	}
+
+	return c.Set(key, val)
+}
+
+//	GetTTL reads the tile from the cache with the ttl. tiles of caches which don't implement Expirer are never stale
+func GetTTL(c Interface, key *Key, ttl time.Duration) (val []byte, hit bool, stale bool, err error) {
+	if e, ok := c.(Expirer); ok {
+		return e.GetTTL(key, ttl)
+	}
+
+	val, hit, err = c.Get(key)
+	return val, hit, false, err
+}
+
+//	Ager is implemented by caches which know when their tiles were written
+type Ager interface {
+	//	Written returns when the tile was written. hit is false when the cache doesn't have the tile
+	Written(key *Key) (written time.Time, hit bool, err error)
+}
+
+//	Written returns when the tile was written to the cache. ok is false when the cache doesn't have the tile
+//	or doesn't implement Ager
+func Written(c Interface, key *Key) (written time.Time, ok bool, err error) {
+	if a, isAger := c.(Ager); isAger {
+		return a.Written(key)
+	}
+
+	return time.Time{}, false, nil
+}
+
+//	Flusher is implemented by caches which write in the background (i.e. tiered caches with write behind tiers).
+//	Flush blocks until the pending writes have completed
+type Flusher interface {
+	Flush()
+}
+
+//	Close releases the resources of caches which hold them (i.e. open files or connections).
+//	caches which don't implement io.Closer have nothing to release
+func Close(c Interface) error {
+	if closer, ok := c.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+//	TileRange is the tiles of a map at zoom Z, from the column MinX to MaxX and the row MinY to MaxY (inclusive).
+//	the tiles of single layers are not part of the range. high-DPI tiles are part of the range of their Scale
+type TileRange struct {
+	MapName    string
+	Z          int
+	MinX, MinY int
+	MaxX, MaxY int
+	//	Scale is the high-DPI scale of the tiles (i.e. 2 for @2x tiles). 0 and 1 are the tiles of the default size
+	Scale int
+}
+
+//	Contains reports whether the tile of the key is in the range
+func (tr TileRange) Contains(key *Key) bool {
+	return key.MapName == tr.MapName && key.LayerName == "" && scale(key.Scale) == scale(tr.Scale) && key.Z == tr.Z &&
+		key.X >= tr.MinX && key.X <= tr.MaxX && key.Y >= tr.MinY && key.Y <= tr.MaxY
+}
+
+//	Namespace returns the name the tiles of the range are stored under (i.e. osm@2x)
+func (tr TileRange) Namespace() string {
+	return namespace(tr.MapName, tr.Scale)
+}
+
+//	AllRows reports whether the range spans every row of its zoom
+func (tr TileRange) AllRows() bool {
+	return tr.MinY <= 0 && tr.MaxY >= (1<<uint(tr.Z))-1
+}
+
+//	RangePurger is implemented by caches which can purge a range of tiles in bulk rather than one key at a time
+//	(i.e. by removing a directory or by listing the keys with a prefix)
+type RangePurger interface {
+	PurgeRange(tr TileRange) error
+}
+
+//	PurgeRange purges the tiles of the range from the cache. caches which don't implement RangePurger
+//	are purged one key at a time
+func PurgeRange(c Interface, tr TileRange) error {
+	if rp, ok := c.(RangePurger); ok {
+		return rp.PurgeRange(tr)
+	}
+
+	for x := tr.MinX; x <= tr.MaxX; x++ {
+		for y := tr.MinY; y <= tr.MaxY; y++ {
+			key := Key{
+				MapName: tr.MapName,
+				Z:       tr.Z,
+				X:       x,
+				Y:       y,
+				Scale:   tr.Scale,
+			}
+			if err := c.Purge(&key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//	MetadataWriter is implemented by caches which store the details of the maps they cache alongside the tiles
+//	(i.e. mbtiles)
+type MetadataWriter interface {
+	WriteMetadata(mapName string, tileJSON tilejson.TileJSON) error
+}
+
 //	ParseKey will parse a string in the format /:map/:layer/:z/:x/:y into a Key struct. The :layer value is optional
 //	ParseKey also supports other OS delimeters (i.e. Windows - "\")
 func ParseKey(str string) (*Key, error) {
@@ -80,7 +210,9 @@ func ParseKey(str string) (*Key, error) {
 
 	//	trim the extension if it exists
 	yParts := strings.Split(zxy[2], ".")
-	key.Y, err = strconv.Atoi(yParts[0])
+	//	split off the high-DPI scale (i.e. @2x) if it exists
+	yScaleParts := strings.Split(yParts[0], "@")
+	key.Y, err = strconv.Atoi(yScaleParts[0])
 	if err != nil {
 		err = ErrInvalidFileKey{
 			path: str,
@@ -92,6 +224,20 @@ func ParseKey(str string) (*Key, error) {
 		return nil, err
 	}
 
+	if len(yScaleParts) == 2 {
+		key.Scale, err = strconv.Atoi(strings.TrimSuffix(yScaleParts[1], "x"))
+		if err != nil || key.Scale < 1 {
+			err = ErrInvalidFileKey{
+				path: str,
+				key:  "Scale",
+				val:  yScaleParts[1],
+			}
+
+			log.Println(err.Error())
+			return nil, err
+		}
+	}
+
 	return &key, nil
 }
 
@@ -101,10 +247,32 @@ type Key struct {
 	Z         int
 	X         int
 	Y         int
+	//	Scale is the high-DPI scale of the tile (i.e. 2 for @2x tiles).
+	//	tiles with a Scale greater than 1 are stored under their own namespace
+	Scale int
 }
 
 func (k Key) String() string {
-	return filepath.Join(k.MapName, k.LayerName, strconv.Itoa(k.Z), strconv.Itoa(k.X), strconv.Itoa(k.Y))
+	return filepath.Join(namespace(k.MapName, k.Scale), k.LayerName, strconv.Itoa(k.Z), strconv.Itoa(k.X), strconv.Itoa(k.Y))
+}
+
+//	namespace returns the name the map's tiles of the scale are stored under. tiles with a scale
+//	greater than 1 are stored under their own namespace (i.e. osm@2x)
+func namespace(mapName string, s int) string {
+	if s > 1 {
+		return mapName + "@" + strconv.Itoa(s) + "x"
+	}
+
+	return mapName
+}
+
+//	scale returns the scale of the tiles, 0 is the default size
+func scale(s int) int {
+	if s < 1 {
+		return 1
+	}
+
+	return s
 }
 
 // InitFunc initilize a cache given a config map.
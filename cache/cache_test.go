@@ -39,6 +39,16 @@ func TestParseKey(t *testing.T) {
 				LayerName: "buildings",
 			},
 		},
+		{
+			input: "/osm/12/11/123@2x.pbf",
+			expected: &cache.Key{
+				Z:       12,
+				X:       11,
+				Y:       123,
+				MapName: "osm",
+				Scale:   2,
+			},
+		},
 	}
 
 	for i, tc := range testcases {
@@ -54,3 +64,70 @@ func TestParseKey(t *testing.T) {
 		}
 	}
 }
+
+func TestTileRangeContains(t *testing.T) {
+	tr := cache.TileRange{MapName: "osm", Z: 3, MinX: 1, MinY: 2, MaxX: 4, MaxY: 5}
+
+	testcases := []struct {
+		key      cache.Key
+		expected bool
+	}{
+		{key: cache.Key{MapName: "osm", Z: 3, X: 1, Y: 2}, expected: true},
+		{key: cache.Key{MapName: "osm", Z: 3, X: 4, Y: 5}, expected: true},
+		{key: cache.Key{MapName: "osm", Z: 3, X: 5, Y: 5}, expected: false},
+		{key: cache.Key{MapName: "osm", Z: 3, X: 1, Y: 1}, expected: false},
+		{key: cache.Key{MapName: "osm", Z: 4, X: 1, Y: 2}, expected: false},
+		{key: cache.Key{MapName: "other", Z: 3, X: 1, Y: 2}, expected: false},
+		{key: cache.Key{MapName: "osm", LayerName: "water", Z: 3, X: 1, Y: 2}, expected: false},
+		{key: cache.Key{MapName: "osm", Z: 3, X: 1, Y: 2, Scale: 1}, expected: true},
+		{key: cache.Key{MapName: "osm", Z: 3, X: 1, Y: 2, Scale: 2}, expected: false},
+	}
+
+	for i, tc := range testcases {
+		if output := tr.Contains(&tc.key); output != tc.expected {
+			t.Errorf("[%v] key (%v), expected %v got %v", i, tc.key, tc.expected, output)
+		}
+	}
+
+	//	high-DPI tiles are in the range of their scale
+	tr.Scale = 2
+	if !tr.Contains(&cache.Key{MapName: "osm", Z: 3, X: 1, Y: 2, Scale: 2}) {
+		t.Errorf("expected the @2x range to contain the @2x tile")
+	}
+	if tr.Contains(&cache.Key{MapName: "osm", Z: 3, X: 1, Y: 2}) {
+		t.Errorf("expected the @2x range not to contain the tile of the default size")
+	}
+	if ns := tr.Namespace(); ns != "osm@2x" {
+		t.Errorf("expected the namespace osm@2x got %v", ns)
+	}
+}
+
+//	purgeCache records the purged keys
+type purgeCache struct {
+	purged []cache.Key
+}
+
+func (pc *purgeCache) Get(key *cache.Key) ([]byte, bool, error) { return nil, false, nil }
+func (pc *purgeCache) Set(key *cache.Key, val []byte) error     { return nil }
+func (pc *purgeCache) Purge(key *cache.Key) error {
+	pc.purged = append(pc.purged, *key)
+	return nil
+}
+
+func TestPurgeRange(t *testing.T) {
+	// caches which don't implement RangePurger are purged a key at a time
+	var pc purgeCache
+	tr := cache.TileRange{MapName: "osm", Z: 1, MinX: 0, MinY: 1, MaxX: 1, MaxY: 1}
+
+	if err := cache.PurgeRange(&pc, tr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []cache.Key{
+		{MapName: "osm", Z: 1, X: 0, Y: 1},
+		{MapName: "osm", Z: 1, X: 1, Y: 1},
+	}
+	if !reflect.DeepEqual(pc.purged, expected) {
+		t.Errorf("expected %v got %v", expected, pc.purged)
+	}
+}
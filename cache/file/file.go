@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/go-spatial/tegola/cache"
 	"github.com/go-spatial/tegola/util/dict"
@@ -98,6 +100,54 @@ func (fc *Cache) Get(key *cache.Key) ([]byte, bool, error) {
 	return val, true, nil
 }
 
+//	GetTTL reads the tile like Get. tiles modified longer than the ttl ago are stale
+func (fc *Cache) GetTTL(key *cache.Key, ttl time.Duration) ([]byte, bool, bool, error) {
+	path := filepath.Join(fc.Basepath, key.String())
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, false, nil
+		}
+
+		return nil, false, false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	val, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	stale := ttl > 0 && time.Since(info.ModTime()) > ttl
+
+	return val, true, stale, nil
+}
+
+//	Written returns the modification time of the tile's file, implements cache.Ager
+func (fc *Cache) Written(key *cache.Key) (time.Time, bool, error) {
+	info, err := os.Stat(filepath.Join(fc.Basepath, key.String()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, false, nil
+		}
+
+		return time.Time{}, false, err
+	}
+
+	return info.ModTime(), true, nil
+}
+
+//	SetTTL writes the tile like Set. the file's modification time is checked against the ttl when it's read
+func (fc *Cache) SetTTL(key *cache.Key, val []byte, ttl time.Duration) error {
+	return fc.Set(key, val)
+}
+
 func (fc *Cache) Set(key *cache.Key, val []byte) error {
 	var err error
 
@@ -146,3 +196,49 @@ func (fc *Cache) Purge(key *cache.Key) error {
 	//	remove the locker key on purge
 	return os.Remove(path)
 }
+
+//	PurgeRange removes the tiles of the range. the directory of a column is removed
+//	when the range spans every row of the zoom
+func (fc *Cache) PurgeRange(tr cache.TileRange) error {
+	zoomPath := filepath.Join(fc.Basepath, tr.Namespace(), strconv.Itoa(tr.Z))
+
+	columns, err := ioutil.ReadDir(zoomPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, column := range columns {
+		x, err := strconv.Atoi(column.Name())
+		if err != nil || !column.IsDir() || x < tr.MinX || x > tr.MaxX {
+			continue
+		}
+
+		columnPath := filepath.Join(zoomPath, column.Name())
+		if tr.AllRows() {
+			if err := os.RemoveAll(columnPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rows, err := ioutil.ReadDir(columnPath)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			//	temp files of in progress writes are skipped
+			y, err := strconv.Atoi(row.Name())
+			if err != nil || y < tr.MinY || y > tr.MaxY {
+				continue
+			}
+			if err := os.Remove(filepath.Join(columnPath, row.Name())); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
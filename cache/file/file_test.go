@@ -2,8 +2,11 @@ package file_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-spatial/tegola/cache"
 	"github.com/go-spatial/tegola/cache/file"
@@ -316,3 +319,142 @@ func TestMaxZoom(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTTL(t *testing.T) {
+	fc, err := file.New(map[string]interface{}{
+		"basepath": "testfiles/tegola-cache",
+	})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	key := cache.Key{MapName: "ttl", Z: 3, X: 1, Y: 2}
+	if err = fc.(cache.Expirer).SetTTL(&key, []byte("\x53\x69\x6c\x61\x73"), time.Hour); err != nil {
+		t.Fatalf("write failed. err: %v", err)
+	}
+	defer fc.Purge(&key)
+
+	//	backdate the tile
+	modTime := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(filepath.Join("testfiles/tegola-cache", key.String()), modTime, modTime); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	testcases := []struct {
+		ttl   time.Duration
+		stale bool
+	}{
+		{ttl: 0, stale: false},
+		{ttl: 3 * time.Hour, stale: false},
+		{ttl: time.Hour, stale: true},
+	}
+
+	for i, tc := range testcases {
+		_, hit, stale, err := fc.(cache.Expirer).GetTTL(&key, tc.ttl)
+		if err != nil {
+			t.Errorf("[%v] read failed. err: %v", i, err)
+			continue
+		}
+		if !hit {
+			t.Errorf("[%v] read failed. should have been a hit but cache reported a miss", i)
+			continue
+		}
+		if stale != tc.stale {
+			t.Errorf("[%v] stale, expected %v got %v", i, tc.stale, stale)
+		}
+	}
+}
+
+func TestPurgeRange(t *testing.T) {
+	type tcase struct {
+		tr cache.TileRange
+		// the columns and rows of zoom 2 expected to be purged
+		purged func(x, y int) bool
+		// the @2x tile 2/1/1 is expected to be purged
+		scaledPurged bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		basepath := filepath.Join(os.TempDir(), "tegola-purge-range")
+		c, err := file.New(map[string]interface{}{"basepath": basepath})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				if err := c.Set(&key, []byte{1}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		}
+		//	a layer tile within the range is not purged
+		layerKey := cache.Key{MapName: "test-map", LayerName: "water", Z: 2, X: 1, Y: 1}
+		if err := c.Set(&layerKey, []byte{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		scaledKey := cache.Key{MapName: "test-map", Z: 2, X: 1, Y: 1, Scale: 2}
+		if err := c.Set(&scaledKey, []byte{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := c.(cache.RangePurger).PurgeRange(tc.tr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				_, hit, err := c.Get(&key)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if hit == tc.purged(x, y) {
+					t.Errorf("tile (%v/%v), expected purged %v got hit %v", x, y, tc.purged(x, y), hit)
+				}
+			}
+		}
+		if _, hit, _ := c.Get(&layerKey); !hit {
+			t.Errorf("expected the layer tile to not have been purged")
+		}
+		if _, hit, _ := c.Get(&scaledKey); hit == tc.scaledPurged {
+			t.Errorf("@2x tile, expected purged %v got hit %v", tc.scaledPurged, hit)
+		}
+
+		if err := os.RemoveAll(basepath); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	tests := map[string]tcase{
+		"columns": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 1, MaxX: 2, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return x >= 1 && x <= 2 },
+		},
+		"rows": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 0, MaxX: 3, MinY: 1, MaxY: 1},
+			purged: func(x, y int) bool { return y == 1 },
+		},
+		"other map": {
+			tr:     cache.TileRange{MapName: "other-map", Z: 2, MinX: 0, MaxX: 3, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return false },
+		},
+		"scale": {
+			tr:           cache.TileRange{MapName: "test-map", Z: 2, MinX: 0, MaxX: 3, MinY: 0, MaxY: 3, Scale: 2},
+			purged:       func(x, y int) bool { return false },
+			scaledPurged: true,
+		},
+		"other zoom": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 3, MinX: 0, MaxX: 7, MinY: 0, MaxY: 7},
+			purged: func(x, y int) bool { return false },
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
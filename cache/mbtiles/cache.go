@@ -0,0 +1,363 @@
+// +build cgo
+
+package mbtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/mapbox/tilejson"
+	"github.com/go-spatial/tegola/util/dict"
+)
+
+//	the MBTiles 1.3 schema (https://github.com/mapbox/mbtiles-spec/blob/master/1.3/spec.md)
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS metadata (name text, value text)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS metadata_name ON metadata (name)`,
+	`CREATE TABLE IF NOT EXISTS tiles (zoom_level integer, tile_column integer, tile_row integer, tile_data blob)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS tile_index ON tiles (zoom_level, tile_column, tile_row)`,
+}
+
+//	New instantiates a Cache. The config expects the following params:
+//
+//		basepath (string): a path to where the mbtiles files will be written. each map is written to
+//			its own file (i.e. basepath/osm.mbtiles)
+//		gzip (bool): [Optional] gzip the tiles before they are written. defaults to false
+//		max_zoom (int): [Optional] max zoom to use the cache. beyond this zoom cache Set() calls will be ignored
+//
+func New(config map[string]interface{}) (cache.Interface, error) {
+	var err error
+
+	mc := Cache{
+		tilesets: map[string]*tileset{},
+	}
+
+	//	parse the config
+	c := dict.M(config)
+
+	defaultMaxZoom := 0
+	maxZoom, err := c.Int(ConfigKeyMaxZoom, &defaultMaxZoom)
+	if err != nil {
+		return nil, err
+	}
+	if maxZoom != 0 {
+		mz := uint(maxZoom)
+		mc.MaxZoom = &mz
+	}
+
+	if v, ok := c[ConfigKeyGzip]; ok {
+		if mc.Gzip, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("mbtilescache: %v value needs to be of type bool. Value is of type %T", ConfigKeyGzip, v)
+		}
+	}
+
+	mc.Basepath, err = c.String(ConfigKeyBasepath, nil)
+	if err != nil {
+		return nil, ErrMissingBasepath
+	}
+
+	if mc.Basepath == "" {
+		return nil, ErrMissingBasepath
+	}
+
+	//	make our basepath if it does not exist
+	if err = os.MkdirAll(mc.Basepath, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &mc, nil
+}
+
+//	Cache writes the tiles of each map to an MBTiles file, implements the cache.Interface.
+//	the files use the default rollback journal so they can be copied without the journal
+//	once the writes have completed.
+type Cache struct {
+	Basepath string
+	//	Gzip compresses the tiles before they are written. gzipped tiles are decompressed on read
+	Gzip bool
+	//	MaxZoom determins the max zoom the cache to persist. Beyond this
+	//	zoom, cache Set() calls will be ignored.
+	MaxZoom *uint
+
+	//	guards tilesets
+	sync.Mutex
+	//	the open files by path
+	tilesets map[string]*tileset
+}
+
+//	tileset is an open MBTiles file. reads are concurrent, writes are serialized
+//	as sqlite allows a single writer at a time
+type tileset struct {
+	*sql.DB
+	write sync.Mutex
+}
+
+//	filename returns the path of the MBTiles file for the key. tiles of a single layer
+//	and high-DPI tiles are written to their own file (i.e. osm/water.mbtiles, osm@2x.mbtiles)
+func (mc *Cache) filename(key *cache.Key) string {
+	mapName := key.MapName
+	if key.Scale > 1 {
+		mapName += "@" + strconv.Itoa(key.Scale) + "x"
+	}
+
+	return filepath.Join(mc.Basepath, filepath.Join(mapName, key.LayerName)+".mbtiles")
+}
+
+//	tileset opens the MBTiles file for the key. the file is only created when create is true (i.e. writes),
+//	otherwise a missing file returns a nil tileset so reads and purges of unknown maps or layers don't
+//	create files
+func (mc *Cache) tileset(key *cache.Key, create bool) (*tileset, error) {
+	path := mc.filename(key)
+
+	mc.Lock()
+	defer mc.Unlock()
+
+	if ts, ok := mc.tilesets[path]; ok {
+		return ts, nil
+	}
+
+	if !create {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("mbtilescache: error creating the schema of (%v): %v", path, err)
+		}
+	}
+
+	ts := tileset{DB: db}
+	mc.tilesets[path] = &ts
+
+	return &ts, nil
+}
+
+//	tmsRow flips the y of the tile. MBTiles use the TMS tiling scheme with the origin at the bottom left
+func tmsRow(z, y int) int {
+	return (1 << uint(z)) - 1 - y
+}
+
+func (mc *Cache) Get(key *cache.Key) ([]byte, bool, error) {
+	ts, err := mc.tileset(key, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if ts == nil {
+		return nil, false, nil
+	}
+
+	var val []byte
+	err = ts.QueryRow(
+		`SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?`,
+		key.Z, key.X, tmsRow(key.Z, key.Y),
+	).Scan(&val)
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, false, nil
+	default:
+		return nil, false, err
+	}
+
+	//	tiles can be gzipped regardless of the config, i.e. when the file was written by another tool
+	if len(val) > 1 && val[0] == 0x1f && val[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(val))
+		if err != nil {
+			return nil, false, err
+		}
+		defer r.Close()
+
+		if val, err = ioutil.ReadAll(r); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return val, true, nil
+}
+
+func (mc *Cache) Set(key *cache.Key, val []byte) error {
+	//	check for maxzoom
+	if mc.MaxZoom != nil && key.Z > int(*mc.MaxZoom) {
+		return nil
+	}
+
+	if mc.Gzip {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(val); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		val = buf.Bytes()
+	}
+
+	ts, err := mc.tileset(key, true)
+	if err != nil {
+		return err
+	}
+
+	ts.write.Lock()
+	defer ts.write.Unlock()
+
+	_, err = ts.Exec(
+		`INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)`,
+		key.Z, key.X, tmsRow(key.Z, key.Y), val,
+	)
+
+	return err
+}
+
+func (mc *Cache) Purge(key *cache.Key) error {
+	ts, err := mc.tileset(key, false)
+	if err != nil || ts == nil {
+		return err
+	}
+
+	ts.write.Lock()
+	defer ts.write.Unlock()
+
+	_, err = ts.Exec(
+		`DELETE FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?`,
+		key.Z, key.X, tmsRow(key.Z, key.Y),
+	)
+
+	return err
+}
+
+//	PurgeRange deletes the tiles of the range with a single statement
+func (mc *Cache) PurgeRange(tr cache.TileRange) error {
+	ts, err := mc.tileset(&cache.Key{MapName: tr.MapName, Scale: tr.Scale}, false)
+	if err != nil || ts == nil {
+		return err
+	}
+
+	ts.write.Lock()
+	defer ts.write.Unlock()
+
+	//	the rows are flipped so the max y is the min row
+	_, err = ts.Exec(
+		`DELETE FROM tiles WHERE zoom_level = ? AND tile_column BETWEEN ? AND ? AND tile_row BETWEEN ? AND ?`,
+		tr.Z, tr.MinX, tr.MaxX, tmsRow(tr.Z, tr.MaxY), tmsRow(tr.Z, tr.MinY),
+	)
+
+	return err
+}
+
+//	metadataLayer is an entry of the vector_layers of the metadata json
+type metadataLayer struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"`
+	MinZoom     int               `json:"minzoom"`
+	MaxZoom     int               `json:"maxzoom"`
+	Fields      map[string]string `json:"fields"`
+}
+
+//	WriteMetadata writes the map's TileJSON to the metadata table of the map's file
+func (mc *Cache) WriteMetadata(mapName string, tileJSON tilejson.TileJSON) error {
+	ts, err := mc.tileset(&cache.Key{MapName: mapName}, true)
+	if err != nil {
+		return err
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	metadata := map[string]string{
+		"name":    mapName,
+		"format":  tileJSON.Format,
+		"type":    "overlay",
+		"version": tileJSON.Version,
+		"minzoom": strconv.Itoa(tileJSON.MinZoom),
+		"maxzoom": strconv.Itoa(tileJSON.MaxZoom),
+		"bounds": formatFloat(tileJSON.Bounds[0]) + "," + formatFloat(tileJSON.Bounds[1]) + "," +
+			formatFloat(tileJSON.Bounds[2]) + "," + formatFloat(tileJSON.Bounds[3]),
+		"center": formatFloat(tileJSON.Center[0]) + "," + formatFloat(tileJSON.Center[1]) + "," +
+			formatFloat(tileJSON.Center[2]),
+	}
+	if tileJSON.Name != nil && *tileJSON.Name != "" {
+		metadata["name"] = *tileJSON.Name
+	}
+	if tileJSON.Attribution != nil && *tileJSON.Attribution != "" {
+		metadata["attribution"] = *tileJSON.Attribution
+	}
+	if tileJSON.Description != nil && *tileJSON.Description != "" {
+		metadata["description"] = *tileJSON.Description
+	}
+
+	//	vector tilesets describe their layers in the json row
+	layers := make([]metadataLayer, 0, len(tileJSON.VectorLayers))
+	for _, l := range tileJSON.VectorLayers {
+		layers = append(layers, metadataLayer{
+			ID:      l.ID,
+			MinZoom: l.MinZoom,
+			MaxZoom: l.MaxZoom,
+			Fields:  map[string]string{},
+		})
+	}
+	layersJSON, err := json.Marshal(map[string]interface{}{"vector_layers": layers})
+	if err != nil {
+		return err
+	}
+	metadata["json"] = string(layersJSON)
+
+	ts.write.Lock()
+	defer ts.write.Unlock()
+
+	tx, err := ts.Begin()
+	if err != nil {
+		return err
+	}
+
+	for name, value := range metadata {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)`, name, value); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+//	Close closes the open files
+func (mc *Cache) Close() error {
+	mc.Lock()
+	defer mc.Unlock()
+
+	var firstErr error
+	for path, ts := range mc.tilesets {
+		if err := ts.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(mc.tilesets, path)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,9 @@
+// +build !cgo
+
+package mbtiles
+
+import "github.com/go-spatial/tegola/cache"
+
+func New(config map[string]interface{}) (cache.Interface, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,380 @@
+// +build cgo
+
+package mbtiles_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/mbtiles"
+	"github.com/go-spatial/tegola/mapbox/tilejson"
+)
+
+func TestNew(t *testing.T) {
+	basepath, err := ioutil.TempDir("", "mbtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(basepath)
+
+	type tcase struct {
+		config map[string]interface{}
+		gzip   bool
+		err    bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		output, err := mbtiles.New(tc.config)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected an error got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		mc := output.(*mbtiles.Cache)
+		if mc.Basepath != basepath || mc.Gzip != tc.gzip {
+			t.Errorf("expected basepath %v gzip %v got %v %v", basepath, tc.gzip, mc.Basepath, mc.Gzip)
+		}
+	}
+
+	tests := map[string]tcase{
+		"basepath": {
+			config: map[string]interface{}{"basepath": basepath},
+		},
+		"gzip": {
+			config: map[string]interface{}{"basepath": basepath, "gzip": true},
+			gzip:   true,
+		},
+		"missing basepath": {
+			config: map[string]interface{}{},
+			err:    true,
+		},
+		"invalid gzip": {
+			config: map[string]interface{}{"basepath": basepath, "gzip": "yes"},
+			err:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestSetGetPurge(t *testing.T) {
+	for _, gzip := range []bool{false, true} {
+		basepath, err := ioutil.TempDir("", "mbtilescache")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.RemoveAll(basepath)
+
+		mc, err := mbtiles.New(map[string]interface{}{"basepath": basepath, "gzip": gzip})
+		if err != nil {
+			t.Fatalf("[gzip %v] unexpected error: %v", gzip, err)
+		}
+
+		key := cache.Key{MapName: "osm", Z: 2, X: 1, Y: 0}
+		tile := []byte("tile")
+
+		if err := mc.Set(&key, tile); err != nil {
+			t.Fatalf("[gzip %v] unexpected error: %v", gzip, err)
+		}
+
+		val, hit, err := mc.Get(&key)
+		if err != nil || !hit || string(val) != string(tile) {
+			t.Errorf("[gzip %v] expected a hit with (%s) got (%s) hit %v err %v", gzip, tile, val, hit, err)
+		}
+
+		//	the row is TMS flipped
+		db, err := sql.Open("sqlite3", filepath.Join(basepath, "osm.mbtiles"))
+		if err != nil {
+			t.Fatalf("[gzip %v] unexpected error: %v", gzip, err)
+		}
+		var row int
+		var data []byte
+		if err := db.QueryRow(`SELECT tile_row, tile_data FROM tiles WHERE zoom_level = 2 AND tile_column = 1`).Scan(&row, &data); err != nil {
+			t.Fatalf("[gzip %v] unexpected error: %v", gzip, err)
+		}
+		db.Close()
+		if row != 3 {
+			t.Errorf("[gzip %v] tile_row, expected 3 got %v", gzip, row)
+		}
+		if isGzip := data[0] == 0x1f && data[1] == 0x8b; isGzip != gzip {
+			t.Errorf("[gzip %v] expected the tile data to be gzipped %v", gzip, gzip)
+		}
+
+		if err := mc.Purge(&key); err != nil {
+			t.Fatalf("[gzip %v] unexpected error: %v", gzip, err)
+		}
+		if _, hit, _ := mc.Get(&key); hit {
+			t.Errorf("[gzip %v] expected a miss after the purge", gzip)
+		}
+	}
+}
+
+func TestUnknownMapNoFiles(t *testing.T) {
+	basepath, err := ioutil.TempDir("", "mbtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(basepath)
+
+	mc, err := mbtiles.New(map[string]interface{}{"basepath": basepath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type tcase struct {
+		key cache.Key
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		val, hit, err := mc.Get(&tc.key)
+		if err != nil || hit || val != nil {
+			t.Errorf("expected a miss got (%s) hit %v err %v", val, hit, err)
+		}
+		if err := mc.Purge(&tc.key); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mc.(cache.RangePurger).PurgeRange(cache.TileRange{MapName: tc.key.MapName, Z: 1, MaxX: 1, MaxY: 1}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		//	reads and purges of unknown maps or layers don't create files
+		files, err := ioutil.ReadDir(basepath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("expected no files in the basepath got %v", len(files))
+		}
+	}
+
+	tests := map[string]tcase{
+		"map": {
+			key: cache.Key{MapName: "unknown", Z: 1, X: 1, Y: 1},
+		},
+		"layer": {
+			key: cache.Key{MapName: "unknown", LayerName: "water", Z: 1, X: 1, Y: 1},
+		},
+		"high-DPI": {
+			key: cache.Key{MapName: "unknown", Scale: 2, Z: 1, X: 1, Y: 1},
+		},
+		"path": {
+			key: cache.Key{MapName: "a/b/c", Z: 1, X: 1, Y: 1},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestConcurrentSetGet(t *testing.T) {
+	basepath, err := ioutil.TempDir("", "mbtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(basepath)
+
+	mc, err := mbtiles.New(map[string]interface{}{"basepath": basepath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for x := 0; x < 16; x++ {
+		wg.Add(1)
+		go func(x int) {
+			defer wg.Done()
+
+			key := cache.Key{MapName: "osm", Z: 4, X: x, Y: x}
+			if err := mc.Set(&key, []byte{byte(x)}); err != nil {
+				t.Errorf("tile (%v), unexpected error: %v", x, err)
+				return
+			}
+			val, hit, err := mc.Get(&key)
+			if err != nil || !hit || len(val) != 1 || val[0] != byte(x) {
+				t.Errorf("tile (%v), expected a hit with %v got %v hit %v err %v", x, []byte{byte(x)}, val, hit, err)
+			}
+		}(x)
+	}
+	wg.Wait()
+}
+
+func TestWriteMetadata(t *testing.T) {
+	basepath, err := ioutil.TempDir("", "mbtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(basepath)
+
+	c, err := mbtiles.New(map[string]interface{}{"basepath": basepath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mc := c.(*mbtiles.Cache)
+
+	attribution := "© OpenStreetMap"
+	err = mc.WriteMetadata("osm", tilejson.TileJSON{
+		Attribution: &attribution,
+		Bounds:      [4]float64{-180, -85.0511, 180, 85.0511},
+		Center:      [3]float64{-76.275329586789, 39.153492567373, 8},
+		Format:      "pbf",
+		MinZoom:     0,
+		MaxZoom:     14,
+		Version:     "1.0.0",
+		VectorLayers: []tilejson.VectorLayer{
+			{ID: "water", MinZoom: 0, MaxZoom: 14},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(basepath, "osm.mbtiles"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT name, value FROM metadata`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rows.Close()
+
+	metadata := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		metadata[name] = value
+	}
+
+	expected := map[string]string{
+		"name":        "osm",
+		"format":      "pbf",
+		"attribution": attribution,
+		"bounds":      "-180,-85.0511,180,85.0511",
+		"center":      "-76.275329586789,39.153492567373,8",
+		"minzoom":     "0",
+		"maxzoom":     "14",
+	}
+	for name, value := range expected {
+		if metadata[name] != value {
+			t.Errorf("metadata (%v), expected %v got %v", name, value, metadata[name])
+		}
+	}
+
+	var layers struct {
+		VectorLayers []struct {
+			ID string `json:"id"`
+		} `json:"vector_layers"`
+	}
+	if err := json.Unmarshal([]byte(metadata["json"]), &layers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(layers.VectorLayers) != 1 || layers.VectorLayers[0].ID != "water" {
+		t.Errorf("metadata (json), expected the water layer got %v", metadata["json"])
+	}
+}
+
+func TestPurgeRange(t *testing.T) {
+	type tcase struct {
+		tr cache.TileRange
+		// the columns and rows of zoom 2 expected to be purged
+		purged func(x, y int) bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		basepath, err := ioutil.TempDir("", "mbtilescache")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.RemoveAll(basepath)
+
+		c, err := mbtiles.New(map[string]interface{}{"basepath": basepath})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				if err := c.Set(&key, []byte{1}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		}
+		//	a layer tile within the range is not purged
+		layerKey := cache.Key{MapName: "test-map", LayerName: "water", Z: 2, X: 1, Y: 1}
+		if err := c.Set(&layerKey, []byte{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := c.(cache.RangePurger).PurgeRange(tc.tr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				_, hit, err := c.Get(&key)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if hit == tc.purged(x, y) {
+					t.Errorf("tile (%v/%v), expected purged %v got hit %v", x, y, tc.purged(x, y), hit)
+				}
+			}
+		}
+		if _, hit, _ := c.Get(&layerKey); !hit {
+			t.Errorf("expected the layer tile to not have been purged")
+		}
+	}
+
+	tests := map[string]tcase{
+		"columns": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 1, MaxX: 2, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return x >= 1 && x <= 2 },
+		},
+		"rows": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 0, MaxX: 3, MinY: 1, MaxY: 1},
+			purged: func(x, y int) bool { return y == 1 },
+		},
+		"other map": {
+			tr:     cache.TileRange{MapName: "other-map", Z: 2, MinX: 0, MaxX: 3, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return false },
+		},
+		"other zoom": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 3, MinX: 0, MaxX: 7, MinY: 0, MaxY: 7},
+			purged: func(x, y int) bool { return false },
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package mbtiles
+
+import (
+	"errors"
+
+	"github.com/go-spatial/tegola/cache"
+)
+
+var (
+	ErrMissingBasepath = errors.New("mbtilescache: missing required param 'basepath'")
+	ErrUnsupported     = errors.New("mbtilescache: unsupported, tegola was built without cgo")
+)
+
+const CacheType = "mbtiles"
+
+const (
+	ConfigKeyBasepath = "basepath"
+	ConfigKeyGzip     = "gzip"
+	ConfigKeyMaxZoom  = "max_zoom"
+)
+
+func init() {
+	cache.Register(CacheType, New)
+}
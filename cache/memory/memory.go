@@ -1,49 +1,430 @@
 package memory
 
 import (
+	"container/heap"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/internal/log"
+	"github.com/go-spatial/tegola/util/dict"
 )
 
-func New() *MemoryCache {
-	return &MemoryCache{
-		keyVals: map[string][]byte{},
+var (
+	ErrInvalidMaxBytes = errors.New("memorycache: 'max_bytes' must be greater than 0")
+	ErrInvalidEviction = errors.New("memorycache: 'eviction' must be one of: lru, lfu")
+)
+
+const CacheType = "memory"
+
+const (
+	ConfigKeyMaxBytes = "max_bytes"
+	ConfigKeyEviction = "eviction"
+	ConfigKeyTTL      = "ttl"
+	ConfigKeyMaxZoom  = "max_zoom"
+	//	ConfigKeyStatsInterval is how often the cache's counters are logged
+	ConfigKeyStatsInterval = "stats_interval"
+)
+
+//	supported eviction policies
+const (
+	//	EvictionLRU evicts the least recently used tiles first
+	EvictionLRU = "lru"
+	//	EvictionLFU evicts the least frequently used tiles first. ties are broken by recency
+	EvictionLFU = "lfu"
+)
+
+//	DefaultMaxBytes is the size of the cache when max_bytes is not set: 256 MiB
+const DefaultMaxBytes = 256 << 20
+
+func init() {
+	cache.Register(CacheType, NewFromConfig)
+}
+
+//	New returns an empty cache of DefaultMaxBytes with LRU eviction and no ttl.
+//	use NewFromConfig or NewCache to size the cache
+func New() *Cache {
+	return NewCache(DefaultMaxBytes, EvictionLRU, 0)
+}
+
+//	NewFromConfig instantiates a Cache. The config expects the following params:
+//
+//		max_bytes (int): [Optional] the max size of the cached tiles, in bytes. Defaults to 256 MiB
+//		eviction (string): [Optional] the eviction policy when the cache is full. One of: lru (default), lfu
+//		ttl (string): [Optional] how long a tile is cached for (i.e. "1h30m"). Defaults to no expiry
+//		max_zoom (int): [Optional] max zoom to use the cache. beyond this zoom cache Set() calls will be ignored
+//		stats_interval (string): [Optional] how often the hits, misses and evictions are logged (i.e. "5m"). Defaults to never
+//
+func NewFromConfig(config map[string]interface{}) (cache.Interface, error) {
+	c := dict.M(config)
+
+	maxBytes, err := intValue(c, ConfigKeyMaxBytes, DefaultMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		return nil, ErrInvalidMaxBytes
+	}
+
+	defaultEviction := EvictionLRU
+	eviction, err := c.String(ConfigKeyEviction, &defaultEviction)
+	if err != nil {
+		return nil, err
 	}
+	if eviction != EvictionLRU && eviction != EvictionLFU {
+		return nil, ErrInvalidEviction
+	}
+
+	defaultTTL := ""
+	ttlStr, err := c.String(ConfigKeyTTL, &defaultTTL)
+	if err != nil {
+		return nil, err
+	}
+	var ttl time.Duration
+	if ttlStr != "" {
+		if ttl, err = time.ParseDuration(ttlStr); err != nil || ttl < 0 {
+			return nil, fmt.Errorf("memorycache: invalid 'ttl' (%v). expected a duration (i.e. 1h30m)", ttlStr)
+		}
+	}
+
+	mc := NewCache(maxBytes, eviction, ttl)
+
+	maxZoom, err := intValue(c, ConfigKeyMaxZoom, -1)
+	if err != nil {
+		return nil, err
+	}
+	if maxZoom >= 0 {
+		mz := uint(maxZoom)
+		mc.MaxZoom = &mz
+	}
+
+	defaultInterval := ""
+	intervalStr, err := c.String(ConfigKeyStatsInterval, &defaultInterval)
+	if err != nil {
+		return nil, err
+	}
+	if intervalStr != "" {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("memorycache: invalid 'stats_interval' (%v). expected a duration (i.e. 5m)", intervalStr)
+		}
+		mc.done = make(chan struct{})
+		go mc.logStats(interval)
+	}
+
+	return mc, nil
 }
 
-//	test cacher, implements the cache.Interface
-type MemoryCache struct {
-	keyVals map[string][]byte
-	sync.RWMutex
+//	intValue reads an integer config value. values decoded from toml are int64s
+func intValue(c dict.M, key string, def int64) (int64, error) {
+	switch v := c[key].(type) {
+	case nil:
+		return def, nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("memorycache: '%v' value needs to be of type int. Value is of type %T", key, v)
+	}
 }
 
-func (mc *MemoryCache) Get(key *cache.Key) ([]byte, bool, error) {
-	mc.RLock()
-	defer mc.RUnlock()
+//	NewCache returns an empty cache holding up to maxBytes of tiles. a ttl of 0 means the tiles don't expire
+func NewCache(maxBytes int64, eviction string, ttl time.Duration) *Cache {
+	return &Cache{
+		MaxBytes: maxBytes,
+		Eviction: eviction,
+		TTL:      ttl,
+		entries:  map[string]*entry{},
+		queue:    evictionQueue{lfu: eviction == EvictionLFU},
+	}
+}
 
-	val, ok := mc.keyVals[key.String()]
+//	Cache is an in process tile cache bounded by size, implements the cache.Interface
+type Cache struct {
+	//	MaxBytes is the max size of the cached tiles and their keys
+	MaxBytes int64
+	//	Eviction is the eviction policy (i.e. EvictionLFU). it's fixed when the cache is created
+	Eviction string
+	//	TTL is how long a tile is cached for. 0 means the tiles don't expire
+	TTL time.Duration
+	//	MaxZoom determins the max zoom the cache to persist. Beyond this
+	//	zoom, cache Set() calls will be ignored
+	MaxZoom *uint
+
+	sync.Mutex
+	entries map[string]*entry
+	queue   evictionQueue
+	bytes   int64
+	//	tick orders the entries by recency
+	tick  uint64
+	stats Stats
+	//	done stops the stats logging, nil when the stats are not logged
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+//	MemoryCache is the name of Cache before the cache was bounded by size.
+//
+//	Deprecated: use Cache
+type MemoryCache = Cache
+
+//	Stats are the counters of the cache
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	//	Expirations are the tiles removed because they outlived the TTL
+	Expirations uint64
+	Entries     int
+	Bytes       int64
+}
+
+//	Stats returns a snapshot of the cache's counters
+func (mc *Cache) Stats() Stats {
+	mc.Lock()
+	defer mc.Unlock()
+
+	s := mc.stats
+	s.Entries = len(mc.entries)
+	s.Bytes = mc.bytes
+
+	return s
+}
+
+func (s Stats) String() string {
+	var hitRate float64
+	if s.Hits+s.Misses > 0 {
+		hitRate = float64(s.Hits) / float64(s.Hits+s.Misses) * 100
+	}
+
+	return fmt.Sprintf("%v hits, %v misses (%.1f%% hit rate), %v evictions, %v expirations, %v tiles, %v bytes",
+		s.Hits, s.Misses, hitRate, s.Evictions, s.Expirations, s.Entries, s.Bytes)
+}
+
+//	logStats logs the cache's counters at every interval until the cache is closed
+func (mc *Cache) logStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			log.Infof("memorycache: %v", mc.Stats())
+		case <-mc.done:
+			return
+		}
+	}
+}
+
+//	Close stops logging the cache's counters. the cached tiles are left to the garbage collector
+func (mc *Cache) Close() error {
+	mc.closeOnce.Do(func() {
+		if mc.done != nil {
+			close(mc.done)
+		}
+	})
+
+	return nil
+}
+
+func (mc *Cache) Get(key *cache.Key) ([]byte, bool, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	e, ok := mc.entries[key.String()]
 	if !ok {
+		mc.stats.Misses++
+		return nil, false, nil
+	}
+
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		mc.remove(e)
+		mc.stats.Expirations++
+		mc.stats.Misses++
 		return nil, false, nil
 	}
 
-	return val, true, nil
+	mc.stats.Hits++
+	mc.touch(e)
+
+	return e.val, true, nil
+}
+
+//	GetTTL reads the tile like Get. tiles older than the ttl, or past the ttl they were written with,
+//	are returned as stale. stale tiles stay in the cache until they are evicted or overwritten
+func (mc *Cache) GetTTL(key *cache.Key, ttl time.Duration) ([]byte, bool, bool, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	e, ok := mc.entries[key.String()]
+	if !ok {
+		mc.stats.Misses++
+		return nil, false, false, nil
+	}
+
+	now := time.Now()
+	stale := (ttl > 0 && now.After(e.written.Add(ttl))) || (!e.expires.IsZero() && now.After(e.expires))
+
+	mc.stats.Hits++
+	mc.touch(e)
+
+	return e.val, true, stale, nil
+}
+
+//	Written returns when the tile was written to the cache, implements cache.Ager
+func (mc *Cache) Written(key *cache.Key) (time.Time, bool, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	e, ok := mc.entries[key.String()]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	return e.written, true, nil
+}
+
+func (mc *Cache) Set(key *cache.Key, val []byte) error {
+	return mc.SetTTL(key, val, mc.TTL)
+}
+
+//	SetTTL writes the tile like Set. the tile expires after the ttl instead of the cache's TTL
+func (mc *Cache) SetTTL(key *cache.Key, val []byte, ttl time.Duration) error {
+	//	check for maxzoom
+	if mc.MaxZoom != nil && key.Z > int(*mc.MaxZoom) {
+		return nil
+	}
+
+	k := key.String()
+	size := int64(len(k) + len(val))
+
+	mc.Lock()
+	defer mc.Unlock()
+
+	if e, ok := mc.entries[k]; ok {
+		mc.remove(e)
+	}
+
+	//	the tile would evict everything else and still not fit
+	if size > mc.MaxBytes {
+		return nil
+	}
+
+	for mc.bytes+size > mc.MaxBytes && mc.queue.Len() > 0 {
+		mc.remove(mc.queue.entries[0])
+		mc.stats.Evictions++
+	}
+
+	e := &entry{
+		key:     k,
+		tile:    *key,
+		val:     val,
+		size:    size,
+		written: time.Now(),
+	}
+	if ttl > 0 {
+		e.expires = e.written.Add(ttl)
+	}
+
+	mc.entries[k] = e
+	mc.bytes += size
+	mc.tick++
+	e.tick = mc.tick
+	e.freq = 1
+	heap.Push(&mc.queue, e)
+
+	return nil
 }
 
-func (mc *MemoryCache) Set(key *cache.Key, val []byte) error {
+func (mc *Cache) Purge(key *cache.Key) error {
 	mc.Lock()
 	defer mc.Unlock()
 
-	mc.keyVals[key.String()] = val
+	if e, ok := mc.entries[key.String()]; ok {
+		mc.remove(e)
+	}
 
 	return nil
 }
 
-func (mc *MemoryCache) Purge(key *cache.Key) error {
+//	PurgeRange removes the tiles of the range
+func (mc *Cache) PurgeRange(tr cache.TileRange) error {
 	mc.Lock()
 	defer mc.Unlock()
 
-	delete(mc.keyVals, key.String())
+	for _, e := range mc.entries {
+		if tr.Contains(&e.tile) {
+			mc.remove(e)
+		}
+	}
 
 	return nil
 }
+
+//	touch records a hit on the entry
+func (mc *Cache) touch(e *entry) {
+	mc.tick++
+	e.tick = mc.tick
+	e.freq++
+	heap.Fix(&mc.queue, e.index)
+}
+
+func (mc *Cache) remove(e *entry) {
+	heap.Remove(&mc.queue, e.index)
+	delete(mc.entries, e.key)
+	mc.bytes -= e.size
+}
+
+type entry struct {
+	key string
+	//	the key of the tile, to match the tile to purged ranges
+	tile    cache.Key
+	val     []byte
+	size    int64
+	written time.Time
+	expires time.Time
+	//	the last access and the number of accesses, the entry with the lowest is evicted first
+	tick uint64
+	freq uint64
+	//	position in the eviction queue
+	index int
+}
+
+//	evictionQueue is a min heap of the entries ordered by the eviction policy
+type evictionQueue struct {
+	entries []*entry
+	lfu     bool
+}
+
+func (q evictionQueue) Len() int { return len(q.entries) }
+
+func (q evictionQueue) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if q.lfu && a.freq != b.freq {
+		return a.freq < b.freq
+	}
+	return a.tick < b.tick
+}
+
+func (q evictionQueue) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
+}
+
+func (q *evictionQueue) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(q.entries)
+	q.entries = append(q.entries, e)
+}
+
+func (q *evictionQueue) Pop() interface{} {
+	n := len(q.entries)
+	e := q.entries[n-1]
+	q.entries[n-1] = nil
+	q.entries = q.entries[:n-1]
+	return e
+}
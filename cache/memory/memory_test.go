@@ -0,0 +1,333 @@
+package memory_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/memory"
+)
+
+func TestNew(t *testing.T) {
+	expected, err := memory.NewFromConfig(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output := memory.New(); !reflect.DeepEqual(expected, output) {
+		t.Errorf("expected %+v got %+v", expected, output)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	maxZoom := uint(9)
+
+	type tcase struct {
+		config   map[string]interface{}
+		expected *memory.Cache
+		err      bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		output, err := memory.NewFromConfig(tc.config)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected an error got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if !reflect.DeepEqual(tc.expected, output) {
+			t.Errorf("expected %+v got %+v", tc.expected, output)
+		}
+	}
+
+	withMaxZoom := memory.NewCache(1024, memory.EvictionLFU, time.Hour)
+	withMaxZoom.MaxZoom = &maxZoom
+
+	tests := map[string]tcase{
+		"defaults": {
+			config:   map[string]interface{}{},
+			expected: memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0),
+		},
+		"all options": {
+			config: map[string]interface{}{
+				"max_bytes": int64(1024),
+				"eviction":  "lfu",
+				"ttl":       "1h",
+				"max_zoom":  9,
+			},
+			expected: withMaxZoom,
+		},
+		"invalid max_bytes": {
+			config: map[string]interface{}{"max_bytes": 0},
+			err:    true,
+		},
+		"invalid eviction": {
+			config: map[string]interface{}{"eviction": "fifo"},
+			err:    true,
+		},
+		"invalid ttl": {
+			config: map[string]interface{}{"ttl": "1 hour"},
+			err:    true,
+		},
+		"invalid stats_interval": {
+			config: map[string]interface{}{"stats_interval": "0s"},
+			err:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestStatsInterval(t *testing.T) {
+	c, err := memory.NewFromConfig(map[string]interface{}{"stats_interval": "1ms"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, _, err := c.Get(&cache.Key{Z: 1, X: 1, Y: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	//	closing stops the stats logging, more than once is fine
+	for i := 0; i < 2; i++ {
+		if err := cache.Close(c); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestStatsString(t *testing.T) {
+	type tcase struct {
+		stats    memory.Stats
+		expected string
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		if output := tc.stats.String(); output != tc.expected {
+			t.Errorf("expected %q got %q", tc.expected, output)
+		}
+	}
+
+	tests := map[string]tcase{
+		"empty": {
+			expected: "0 hits, 0 misses (0.0% hit rate), 0 evictions, 0 expirations, 0 tiles, 0 bytes",
+		},
+		"counters": {
+			stats:    memory.Stats{Hits: 3, Misses: 1, Evictions: 2, Expirations: 1, Entries: 5, Bytes: 1024},
+			expected: "3 hits, 1 misses (75.0% hit rate), 2 evictions, 1 expirations, 5 tiles, 1024 bytes",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	key := func(x int) *cache.Key {
+		return &cache.Key{Z: 1, X: x, Y: 0}
+	}
+	// the keys are 5 bytes (i.e. 1/0/0), so each tile takes up 10 bytes
+	tile := make([]byte, 5)
+
+	type tcase struct {
+		eviction string
+		// the tiles read after the cache is filled, before a new tile is added
+		reads []int
+		// the tile expected to be evicted
+		evicted int
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		mc := memory.NewCache(30, tc.eviction, 0)
+		for x := 0; x < 3; x++ {
+			if err := mc.Set(key(x), tile); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		for _, x := range tc.reads {
+			if _, hit, _ := mc.Get(key(x)); !hit {
+				t.Fatalf("expected a hit for tile %v", x)
+			}
+		}
+
+		if err := mc.Set(key(3), tile); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			_, hit, _ := mc.Get(key(x))
+			if hit == (x == tc.evicted) {
+				t.Errorf("tile %v, expected hit %v got %v", x, x != tc.evicted, hit)
+			}
+		}
+
+		stats := mc.Stats()
+		if stats.Evictions != 1 || stats.Entries != 3 || stats.Bytes != 30 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	}
+
+	tests := map[string]tcase{
+		"lru": {
+			eviction: memory.EvictionLRU,
+			reads:    []int{0, 2},
+			evicted:  1,
+		},
+		"lfu": {
+			eviction: memory.EvictionLFU,
+			// tile 2 is the least recently used but was read twice
+			reads:   []int{2, 2, 1, 0},
+			evicted: 1,
+		},
+		"lfu ties": {
+			eviction: memory.EvictionLFU,
+			evicted:  0,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	mc := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, time.Millisecond)
+	key := &cache.Key{Z: 1, X: 1, Y: 1}
+
+	if err := mc.Set(key, []byte{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, hit, _ := mc.Get(key); !hit {
+		t.Fatalf("expected a hit")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, hit, _ := mc.Get(key); hit {
+		t.Errorf("expected the tile to have expired")
+	}
+
+	stats := mc.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Expirations != 1 || stats.Entries != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCacheGetTTL(t *testing.T) {
+	mc := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+	key := &cache.Key{Z: 1, X: 1, Y: 1}
+
+	if err := mc.SetTTL(key, []byte{1}, 50*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, hit, stale, _ := mc.GetTTL(key, time.Hour); !hit || stale {
+		t.Fatalf("expected a fresh hit got hit %v stale %v", hit, stale)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// the tile is past the ttl it was written with
+	if _, hit, stale, _ := mc.GetTTL(key, 0); !hit || !stale {
+		t.Errorf("expected a stale hit got hit %v stale %v", hit, stale)
+	}
+	// a shorter ttl on read
+	if err := mc.Set(key, []byte{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, hit, stale, _ := mc.GetTTL(key, time.Millisecond); !hit || !stale {
+		t.Errorf("expected a stale hit got hit %v stale %v", hit, stale)
+	}
+}
+
+func TestPurgeRange(t *testing.T) {
+	type tcase struct {
+		tr cache.TileRange
+		// the columns and rows of zoom 2 expected to be purged
+		purged func(x, y int) bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		c := cache.Interface(memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0))
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				if err := c.Set(&key, []byte{1}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		}
+		//	a layer tile within the range is not purged
+		layerKey := cache.Key{MapName: "test-map", LayerName: "water", Z: 2, X: 1, Y: 1}
+		if err := c.Set(&layerKey, []byte{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := c.(cache.RangePurger).PurgeRange(tc.tr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				_, hit, err := c.Get(&key)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if hit == tc.purged(x, y) {
+					t.Errorf("tile (%v/%v), expected purged %v got hit %v", x, y, tc.purged(x, y), hit)
+				}
+			}
+		}
+		if _, hit, _ := c.Get(&layerKey); !hit {
+			t.Errorf("expected the layer tile to not have been purged")
+		}
+	}
+
+	tests := map[string]tcase{
+		"columns": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 1, MaxX: 2, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return x >= 1 && x <= 2 },
+		},
+		"rows": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 0, MaxX: 3, MinY: 1, MaxY: 1},
+			purged: func(x, y int) bool { return y == 1 },
+		},
+		"other map": {
+			tr:     cache.TileRange{MapName: "other-map", Z: 2, MinX: 0, MaxX: 3, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return false },
+		},
+		"other zoom": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 3, MinX: 0, MaxX: 7, MinY: 0, MaxY: 7},
+			purged: func(x, y int) bool { return false },
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package pmtiles
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+//	the PMTiles v3 archive format (https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md)
+
+const (
+	//	HeaderLength is the length of the archive header in bytes
+	HeaderLength = 127
+	//	rootMaxLength is the max length of the header and the root directory. clients read
+	//	the first 16 KiB of an archive to find any tile with at most one more request
+	rootMaxLength = 16384
+
+	magic   = "PMTiles"
+	version = 3
+)
+
+//	compression types
+const (
+	CompressionUnknown = 0
+	CompressionNone    = 1
+	CompressionGzip    = 2
+	CompressionBrotli  = 3
+	CompressionZstd    = 4
+)
+
+//	tile types
+const (
+	TileTypeUnknown = 0
+	TileTypeMVT     = 1
+)
+
+var ErrInvalidHeader = errors.New("pmtiles: invalid header. expected a PMTiles v3 archive")
+
+//	Header is the fixed length header at the start of an archive.
+//	the offsets of the sections are relative to the start of the archive
+type Header struct {
+	RootOffset          uint64
+	RootLength          uint64
+	MetadataOffset      uint64
+	MetadataLength      uint64
+	LeafDirectoryOffset uint64
+	LeafDirectoryLength uint64
+	TileDataOffset      uint64
+	TileDataLength      uint64
+	AddressedTiles      uint64
+	TileEntries         uint64
+	TileContents        uint64
+	//	Clustered is set when the tile data is ordered by tile ID
+	Clustered           bool
+	InternalCompression uint8
+	TileCompression     uint8
+	TileType            uint8
+	MinZoom             uint8
+	MaxZoom             uint8
+	//	the bounds and center in degrees
+	MinLon, MinLat float64
+	MaxLon, MaxLat float64
+	CenterZoom     uint8
+	CenterLon      float64
+	CenterLat      float64
+}
+
+//	e7 converts degrees to the fixed precision of the header
+func e7(deg float64) uint32 {
+	return uint32(int32(math.Floor(deg*10000000 + 0.5)))
+}
+
+func fromE7(v uint32) float64 {
+	return float64(int32(v)) / 10000000
+}
+
+//	MarshalBinary encodes the header
+func (h Header) MarshalBinary() ([]byte, error) {
+	b := make([]byte, HeaderLength)
+	copy(b[0:7], magic)
+	b[7] = version
+
+	le := binary.LittleEndian
+	le.PutUint64(b[8:], h.RootOffset)
+	le.PutUint64(b[16:], h.RootLength)
+	le.PutUint64(b[24:], h.MetadataOffset)
+	le.PutUint64(b[32:], h.MetadataLength)
+	le.PutUint64(b[40:], h.LeafDirectoryOffset)
+	le.PutUint64(b[48:], h.LeafDirectoryLength)
+	le.PutUint64(b[56:], h.TileDataOffset)
+	le.PutUint64(b[64:], h.TileDataLength)
+	le.PutUint64(b[72:], h.AddressedTiles)
+	le.PutUint64(b[80:], h.TileEntries)
+	le.PutUint64(b[88:], h.TileContents)
+	if h.Clustered {
+		b[96] = 1
+	}
+	b[97] = h.InternalCompression
+	b[98] = h.TileCompression
+	b[99] = h.TileType
+	b[100] = h.MinZoom
+	b[101] = h.MaxZoom
+	le.PutUint32(b[102:], e7(h.MinLon))
+	le.PutUint32(b[106:], e7(h.MinLat))
+	le.PutUint32(b[110:], e7(h.MaxLon))
+	le.PutUint32(b[114:], e7(h.MaxLat))
+	b[118] = h.CenterZoom
+	le.PutUint32(b[119:], e7(h.CenterLon))
+	le.PutUint32(b[123:], e7(h.CenterLat))
+
+	return b, nil
+}
+
+//	UnmarshalBinary decodes the header
+func (h *Header) UnmarshalBinary(b []byte) error {
+	if len(b) < HeaderLength || string(b[0:7]) != magic || b[7] != version {
+		return ErrInvalidHeader
+	}
+
+	le := binary.LittleEndian
+	*h = Header{
+		RootOffset:          le.Uint64(b[8:]),
+		RootLength:          le.Uint64(b[16:]),
+		MetadataOffset:      le.Uint64(b[24:]),
+		MetadataLength:      le.Uint64(b[32:]),
+		LeafDirectoryOffset: le.Uint64(b[40:]),
+		LeafDirectoryLength: le.Uint64(b[48:]),
+		TileDataOffset:      le.Uint64(b[56:]),
+		TileDataLength:      le.Uint64(b[64:]),
+		AddressedTiles:      le.Uint64(b[72:]),
+		TileEntries:         le.Uint64(b[80:]),
+		TileContents:        le.Uint64(b[88:]),
+		Clustered:           b[96] == 1,
+		InternalCompression: b[97],
+		TileCompression:     b[98],
+		TileType:            b[99],
+		MinZoom:             b[100],
+		MaxZoom:             b[101],
+		MinLon:              fromE7(le.Uint32(b[102:])),
+		MinLat:              fromE7(le.Uint32(b[106:])),
+		MaxLon:              fromE7(le.Uint32(b[110:])),
+		MaxLat:              fromE7(le.Uint32(b[114:])),
+		CenterZoom:          b[118],
+		CenterLon:           fromE7(le.Uint32(b[119:])),
+		CenterLat:           fromE7(le.Uint32(b[123:])),
+	}
+
+	return nil
+}
+
+//	TileID returns the ID of the tile: the number of tiles of the lower zooms plus
+//	the position of the tile on the zoom's Hilbert curve
+func TileID(z uint8, x, y uint64) uint64 {
+	//	the tiles of the lower zooms: (4^z - 1) / 3
+	id := ((uint64(1) << (2 * uint64(z))) - 1) / 3
+
+	n := uint64(1) << z
+	for s := n / 2; s > 0; s /= 2 {
+		var rx, ry uint64
+		if x&s > 0 {
+			rx = 1
+		}
+		if y&s > 0 {
+			ry = 1
+		}
+		id += s * s * ((3 * rx) ^ ry)
+
+		//	rotate the quadrant
+		if ry == 0 {
+			if rx == 1 {
+				x = n - 1 - x
+				y = n - 1 - y
+			}
+			x, y = y, x
+		}
+	}
+
+	return id
+}
+
+//	Entry is an entry of a directory. entries with a RunLength of 0 point to a leaf directory,
+//	otherwise they point to the tile data of RunLength tiles with consecutive IDs
+type Entry struct {
+	TileID uint64
+	//	the offset relative to the tile data section, or to the leaf directories section for leaf entries
+	Offset    uint64
+	Length    uint32
+	RunLength uint32
+}
+
+//	encodeDirectory encodes and gzips the entries. the entries must be ordered by tile ID
+func encodeDirectory(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := bufio.NewWriter(gz)
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	put := func(v uint64) {
+		n := binary.PutUvarint(varint, v)
+		w.Write(varint[:n])
+	}
+
+	put(uint64(len(entries)))
+
+	//	the columns of the entries. the tile IDs are delta encoded
+	var lastID uint64
+	for _, e := range entries {
+		put(e.TileID - lastID)
+		lastID = e.TileID
+	}
+	for _, e := range entries {
+		put(uint64(e.RunLength))
+	}
+	for _, e := range entries {
+		put(uint64(e.Length))
+	}
+	//	an offset which follows the previous entry's data is encoded as 0, otherwise as offset + 1
+	for i, e := range entries {
+		if i > 0 && e.Offset == entries[i-1].Offset+uint64(entries[i-1].Length) {
+			put(0)
+			continue
+		}
+		put(e.Offset + 1)
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+//	decodeDirectory decodes a directory compressed with the compression
+func decodeDirectory(b []byte, compression uint8) ([]Entry, error) {
+	var r io.ByteReader
+	switch compression {
+	case CompressionNone:
+		r = bytes.NewReader(b)
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = bufio.NewReader(gz)
+	default:
+		return nil, fmt.Errorf("pmtiles: unsupported internal compression (%v)", compression)
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, n)
+
+	var lastID uint64
+	for i := range entries {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		lastID += v
+		entries[i].TileID = lastID
+	}
+	for i := range entries {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].RunLength = uint32(v)
+	}
+	for i := range entries {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Length = uint32(v)
+	}
+	for i := range entries {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if v == 0 && i > 0 {
+			entries[i].Offset = entries[i-1].Offset + uint64(entries[i-1].Length)
+			continue
+		}
+		entries[i].Offset = v - 1
+	}
+
+	return entries, nil
+}
+
+//	findEntry returns the entry holding the tile ID: the entry with the greatest tile ID
+//	not greater than the ID. leaf directory entries are returned as is
+func findEntry(entries []Entry, id uint64) (Entry, bool) {
+	lo, hi := 0, len(entries)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case entries[mid].TileID < id:
+			lo = mid + 1
+		case entries[mid].TileID > id:
+			hi = mid - 1
+		default:
+			return entries[mid], true
+		}
+	}
+
+	//	hi is the last entry with a lower tile ID
+	if hi < 0 {
+		return Entry{}, false
+	}
+	e := entries[hi]
+	if e.RunLength == 0 || id-e.TileID < uint64(e.RunLength) {
+		return e, true
+	}
+
+	return Entry{}, false
+}
+
+//	decompress decompresses tile data
+func decompress(b []byte, compression uint8) ([]byte, error) {
+	switch compression {
+	case CompressionNone, CompressionUnknown:
+		return b, nil
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+
+		return ioutil.ReadAll(gz)
+	default:
+		return nil, fmt.Errorf("pmtiles: unsupported tile compression (%v)", compression)
+	}
+}
@@ -0,0 +1,155 @@
+package pmtiles
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTileID(t *testing.T) {
+	testcases := []struct {
+		z        uint8
+		x, y     uint64
+		expected uint64
+	}{
+		{z: 0, x: 0, y: 0, expected: 0},
+		{z: 1, x: 0, y: 0, expected: 1},
+		{z: 1, x: 0, y: 1, expected: 2},
+		{z: 1, x: 1, y: 1, expected: 3},
+		{z: 1, x: 1, y: 0, expected: 4},
+		{z: 2, x: 0, y: 0, expected: 5},
+		{z: 12, x: 3423, y: 1763, expected: 19078479},
+	}
+
+	for i, tc := range testcases {
+		if id := TileID(tc.z, tc.x, tc.y); id != tc.expected {
+			t.Errorf("[%v] tile (%v/%v/%v), expected %v got %v", i, tc.z, tc.x, tc.y, tc.expected, id)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := Header{
+		RootOffset:          127,
+		RootLength:          25,
+		TileDataOffset:      1024,
+		TileDataLength:      4096,
+		AddressedTiles:      10,
+		Clustered:           true,
+		InternalCompression: CompressionGzip,
+		TileCompression:     CompressionGzip,
+		TileType:            TileTypeMVT,
+		MaxZoom:             14,
+		MinLon:              -180,
+		MinLat:              -85.0511,
+		MaxLon:              180,
+		MaxLat:              85.0511,
+		CenterZoom:          8,
+		CenterLon:           -76.2753296,
+		CenterLat:           39.1534926,
+	}
+
+	b, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b) != HeaderLength {
+		t.Fatalf("header length, expected %v got %v", HeaderLength, len(b))
+	}
+
+	var output Header
+	if err := output.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(h, output) {
+		t.Errorf("expected %+v got %+v", h, output)
+	}
+
+	b[7] = 2
+	if err := output.UnmarshalBinary(b); err != ErrInvalidHeader {
+		t.Errorf("expected %v got %v", ErrInvalidHeader, err)
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	entries := []Entry{
+		{TileID: 0, Offset: 0, Length: 10, RunLength: 1},
+		{TileID: 1, Offset: 10, Length: 20, RunLength: 2},
+		// points back to the data of the first tile
+		{TileID: 5, Offset: 0, Length: 10, RunLength: 1},
+		{TileID: 9, Offset: 30, Length: 5, RunLength: 1},
+	}
+
+	b, err := encodeDirectory(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output, err := decodeDirectory(b, CompressionGzip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(entries, output) {
+		t.Errorf("expected %+v got %+v", entries, output)
+	}
+
+	findcases := []struct {
+		id    uint64
+		found bool
+		entry int
+	}{
+		{id: 0, found: true, entry: 0},
+		// within the run of the second entry
+		{id: 2, found: true, entry: 1},
+		{id: 3, found: false},
+		{id: 5, found: true, entry: 2},
+		{id: 10, found: false},
+	}
+	for i, tc := range findcases {
+		e, ok := findEntry(entries, tc.id)
+		if ok != tc.found {
+			t.Errorf("[%v] id (%v), expected found %v got %v", i, tc.id, tc.found, ok)
+			continue
+		}
+		if ok && e != entries[tc.entry] {
+			t.Errorf("[%v] id (%v), expected %+v got %+v", i, tc.id, entries[tc.entry], e)
+		}
+	}
+}
+
+func TestBuildDirectoriesLeaves(t *testing.T) {
+	//	tiles which don't follow each other, so the root directory doesn't fit
+	var entries []Entry
+	for i := uint64(0); i < 20000; i++ {
+		entries = append(entries, Entry{TileID: i * 7, Offset: i * 1000, Length: uint32(100 + i%900), RunLength: 1})
+	}
+
+	root, leaves, err := buildDirectories(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(root) > rootMaxLength-HeaderLength {
+		t.Errorf("expected the root directory to fit in %v bytes got %v", rootMaxLength-HeaderLength, len(root))
+	}
+	if len(leaves) == 0 {
+		t.Fatalf("expected leaf directories")
+	}
+
+	rootEntries, err := decodeDirectory(root, CompressionGzip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var output []Entry
+	for _, re := range rootEntries {
+		if re.RunLength != 0 {
+			t.Fatalf("expected a leaf entry got %+v", re)
+		}
+		leaf, err := decodeDirectory(leaves[re.Offset:re.Offset+uint64(re.Length)], CompressionGzip)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		output = append(output, leaf...)
+	}
+	if !reflect.DeepEqual(entries, output) {
+		t.Errorf("expected the leaves to hold the %v entries got %v", len(entries), len(output))
+	}
+}
@@ -0,0 +1,194 @@
+package pmtiles
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/util/dict"
+)
+
+var (
+	ErrMissingFilepath = errors.New("pmtilescache: missing required param 'filepath'")
+	ErrReadOnly        = errors.New("pmtilescache: the cache is read only")
+)
+
+const CacheType = "pmtiles"
+
+const (
+	ConfigKeyFilepath = "filepath"
+	ConfigKeyMap      = "map"
+)
+
+func init() {
+	cache.Register(CacheType, New)
+}
+
+//	New instantiates a read only Cache serving the tiles of a PMTiles archive. The config expects the following params:
+//
+//		filepath (string): the path of the archive (i.e. /data/osm.pmtiles)
+//		map (string): [Optional] the name of the map the archive holds the tiles of. when set,
+//			the tiles of other maps are misses. defaults to serving the archive for every map
+//
+func New(config map[string]interface{}) (cache.Interface, error) {
+	var err error
+
+	c := dict.M(config)
+
+	path, err := c.String(ConfigKeyFilepath, nil)
+	if err != nil || path == "" {
+		return nil, ErrMissingFilepath
+	}
+
+	defaultMap := ""
+	mapName, err := c.String(ConfigKeyMap, &defaultMap)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	pc.MapName = mapName
+
+	return pc, nil
+}
+
+//	Open opens the archive at path
+func Open(path string) (*Cache, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, HeaderLength)
+	if _, err := f.ReadAt(b, 0); err != nil {
+		f.Close()
+		return nil, ErrInvalidHeader
+	}
+
+	pc := Cache{
+		file:   f,
+		leaves: map[uint64][]Entry{},
+	}
+	if err = pc.Header.UnmarshalBinary(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if pc.root, err = pc.readDirectory(pc.Header.RootOffset, pc.Header.RootLength); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &pc, nil
+}
+
+//	Cache serves the tiles of a PMTiles archive, implements the cache.Interface.
+//	the archive is read only: Set calls are ignored and Purge calls return ErrReadOnly
+type Cache struct {
+	Header Header
+	//	MapName is the map the archive holds the tiles of. an empty MapName serves the archive for every map
+	MapName string
+
+	//	the archive. reads use ReadAt so they are safe for concurrent use
+	file *os.File
+	root []Entry
+
+	//	the leaf directories read so far, by offset
+	sync.RWMutex
+	leaves map[uint64][]Entry
+}
+
+func (pc *Cache) readDirectory(offset, length uint64) ([]Entry, error) {
+	b := make([]byte, length)
+	if _, err := pc.file.ReadAt(b, int64(offset)); err != nil {
+		return nil, err
+	}
+
+	return decodeDirectory(b, pc.Header.InternalCompression)
+}
+
+//	leaf returns the leaf directory at the offset into the leaf directories section
+func (pc *Cache) leaf(e Entry) ([]Entry, error) {
+	pc.RLock()
+	entries, ok := pc.leaves[e.Offset]
+	pc.RUnlock()
+	if ok {
+		return entries, nil
+	}
+
+	entries, err := pc.readDirectory(pc.Header.LeafDirectoryOffset+e.Offset, uint64(e.Length))
+	if err != nil {
+		return nil, err
+	}
+
+	pc.Lock()
+	pc.leaves[e.Offset] = entries
+	pc.Unlock()
+
+	return entries, nil
+}
+
+func (pc *Cache) Get(key *cache.Key) ([]byte, bool, error) {
+	//	archives hold the tiles of a whole map at a scale of 1
+	if (pc.MapName != "" && key.MapName != pc.MapName) || key.LayerName != "" || key.Scale > 1 {
+		return nil, false, nil
+	}
+
+	if key.Z < int(pc.Header.MinZoom) || key.Z > int(pc.Header.MaxZoom) || key.X < 0 || key.Y < 0 {
+		return nil, false, nil
+	}
+
+	id := TileID(uint8(key.Z), uint64(key.X), uint64(key.Y))
+
+	entries := pc.root
+	//	the spec allows for at most 3 levels of leaf directories
+	for depth := 0; depth <= 3; depth++ {
+		e, ok := findEntry(entries, id)
+		if !ok {
+			return nil, false, nil
+		}
+
+		if e.RunLength > 0 {
+			b := make([]byte, e.Length)
+			if _, err := pc.file.ReadAt(b, int64(pc.Header.TileDataOffset+e.Offset)); err != nil {
+				return nil, false, err
+			}
+
+			val, err := decompress(b, pc.Header.TileCompression)
+			if err != nil {
+				return nil, false, err
+			}
+
+			return val, true, nil
+		}
+
+		var err error
+		if entries, err = pc.leaf(e); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return nil, false, nil
+}
+
+//	Set is a no-op. the archive is read only
+func (pc *Cache) Set(key *cache.Key, val []byte) error {
+	return nil
+}
+
+func (pc *Cache) Purge(key *cache.Key) error {
+	return ErrReadOnly
+}
+
+func (pc *Cache) PurgeRange(tr cache.TileRange) error {
+	return ErrReadOnly
+}
+
+//	Close closes the archive
+func (pc *Cache) Close() error {
+	return pc.file.Close()
+}
@@ -0,0 +1,178 @@
+package pmtiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/pmtiles"
+	"github.com/go-spatial/tegola/mapbox/tilejson"
+)
+
+func TestWriterCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pmtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "osm.pmtiles")
+
+	w, err := pmtiles.NewWriter(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tiles := map[cache.Key][]byte{
+		{Z: 0, X: 0, Y: 0}: []byte("world"),
+		// the ocean tiles are stored once
+		{Z: 1, X: 0, Y: 0}: []byte("ocean"),
+		{Z: 1, X: 0, Y: 1}: []byte("ocean"),
+		{Z: 1, X: 1, Y: 1}: []byte("land"),
+		{Z: 1, X: 1, Y: 0}: []byte("ocean"),
+	}
+	for key, val := range tiles {
+		key := key
+		if err := w.Set(&key, val); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	name := "osm"
+	//	the header has the bounds and zooms of the seed, not the map's
+	tileJSON := tilejson.TileJSON{
+		Name:    &name,
+		Bounds:  [4]float64{-180, -85.0511, 180, 85.0511},
+		Center:  [3]float64{0, 0, 6},
+		MinZoom: 0,
+		MaxZoom: 20,
+	}
+	if err := w.Close(tileJSON, [4]float64{-10, 35, 30, 60}, 0, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to have been removed")
+	}
+
+	c, err := pmtiles.New(map[string]interface{}{"filepath": path, "map": "osm"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pc := c.(*pmtiles.Cache)
+
+	h := pc.Header
+	if h.AddressedTiles != 5 || h.TileContents != 3 || h.MinZoom != 0 || h.MaxZoom != 1 {
+		t.Errorf("unexpected header %+v", h)
+	}
+	if h.MinLon != -10 || h.MinLat != 35 || h.MaxLon != 30 || h.MaxLat != 60 {
+		t.Errorf("header bounds, expected the seed bounds got %v, %v, %v, %v", h.MinLon, h.MinLat, h.MaxLon, h.MaxLat)
+	}
+	if h.CenterLon != 10 || h.CenterLat != 47.5 || h.CenterZoom != 1 {
+		t.Errorf("header center, expected 10, 47.5 at zoom 1 got %v, %v at zoom %v", h.CenterLon, h.CenterLat, h.CenterZoom)
+	}
+
+	for key, val := range tiles {
+		key := key
+		key.MapName = "osm"
+
+		output, hit, err := pc.Get(&key)
+		if err != nil || !hit || string(output) != string(val) {
+			t.Errorf("tile (%v), expected a hit with (%s) got (%s) hit %v err %v", key, val, output, hit, err)
+		}
+	}
+
+	misses := []cache.Key{
+		{MapName: "osm", Z: 2, X: 1, Y: 1},
+		{MapName: "osm", LayerName: "water", Z: 0, X: 0, Y: 0},
+		{MapName: "osm", Z: 0, X: 0, Y: 0, Scale: 2},
+		{MapName: "other", Z: 0, X: 0, Y: 0},
+	}
+	for _, key := range misses {
+		key := key
+		if _, hit, err := pc.Get(&key); hit || err != nil {
+			t.Errorf("tile (%v), expected a miss got hit %v err %v", key, hit, err)
+		}
+	}
+
+	key := cache.Key{MapName: "osm", Z: 0, X: 0, Y: 0}
+	if err := pc.Purge(&key); err != pmtiles.ErrReadOnly {
+		t.Errorf("purge, expected %v got %v", pmtiles.ErrReadOnly, err)
+	}
+}
+
+func TestWriterCloseZoomRange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pmtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := pmtiles.NewWriter(filepath.Join(dir, "osm.pmtiles"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bounds := [4]float64{-180, -85.0511, 180, 85.0511}
+	if err := w.Close(tilejson.TileJSON{}, bounds, 2, 1); err == nil {
+		t.Errorf("expected an error for a min zoom greater than the max zoom")
+	}
+
+	//	the writer can still be closed with a valid zoom range
+	if err := w.Close(tilejson.TileJSON{}, bounds, 0, 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := pmtiles.New(map[string]interface{}{}); err != pmtiles.ErrMissingFilepath {
+		t.Errorf("expected %v got %v", pmtiles.ErrMissingFilepath, err)
+	}
+
+	f, err := ioutil.TempFile("", "pmtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.Write([]byte("not an archive"))
+	f.Close()
+
+	if _, err := pmtiles.New(map[string]interface{}{"filepath": f.Name()}); err != pmtiles.ErrInvalidHeader {
+		t.Errorf("expected %v got %v", pmtiles.ErrInvalidHeader, err)
+	}
+}
+
+func TestWriterAbort(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pmtilescache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "osm.pmtiles")
+	w, err := pmtiles.NewWriter(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Set(&cache.Key{Z: 1, X: 1, Y: 1}, []byte("tile")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := w.Abort(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	//	neither the archive nor the temporary file are left
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files got %v", len(files))
+	}
+
+	if err := w.Close(tilejson.TileJSON{}, [4]float64{-180, -85.0511, 180, 85.0511}, 0, 1); err != pmtiles.ErrWriterClosed {
+		t.Errorf("close, expected %v got %v", pmtiles.ErrWriterClosed, err)
+	}
+}
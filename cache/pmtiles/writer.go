@@ -0,0 +1,343 @@
+package pmtiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/mapbox/tilejson"
+)
+
+var ErrWriterClosed = errors.New("pmtiles: the archive has been closed")
+
+//	Writer builds a PMTiles archive for a single map. tiles can be written concurrently and in any order.
+//	tiles with identical data are stored once. the tile data is buffered in a temporary file next to the
+//	archive until Close writes the archive.
+//
+//	Writer implements cache.Interface so an archive can be seeded like a cache. the map name of the keys
+//	is ignored and the archive is write only: Get always misses.
+type Writer struct {
+	path string
+
+	sync.Mutex
+	//	the buffered tile data, gzipped and deduplicated
+	tmp     *os.File
+	tmpSize uint64
+	//	the offset and length in the temporary file of each tile's data, by the hash of the data
+	contents map[[sha256.Size]byte]Entry
+	//	the tile entries with offsets into the temporary file
+	entries map[uint64]Entry
+	closed  bool
+}
+
+//	NewWriter creates a writer for an archive at path. the archive is written when Close is called
+func NewWriter(path string) (*Writer, error) {
+	tmp, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		path:     path,
+		tmp:      tmp,
+		contents: map[[sha256.Size]byte]Entry{},
+		entries:  map[uint64]Entry{},
+	}, nil
+}
+
+//	WriteTile adds the tile to the archive. empty tiles and tiles outside of the zoom's grid are omitted.
+//	writing a tile twice replaces the tile
+func (w *Writer) WriteTile(z uint8, x, y uint64, val []byte) error {
+	if len(val) == 0 {
+		return nil
+	}
+	//	i.e. the x of 2^z when seeding up to a longitude of 180
+	if size := uint64(1) << z; x >= size || y >= size {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(val); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(buf.Bytes())
+	id := TileID(z, x, y)
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	content, ok := w.contents[hash]
+	if !ok {
+		if _, err := w.tmp.Write(buf.Bytes()); err != nil {
+			return err
+		}
+
+		content = Entry{Offset: w.tmpSize, Length: uint32(buf.Len())}
+		w.contents[hash] = content
+		w.tmpSize += uint64(buf.Len())
+	}
+
+	w.entries[id] = Entry{TileID: id, Offset: content.Offset, Length: content.Length, RunLength: 1}
+
+	return nil
+}
+
+func (w *Writer) Get(key *cache.Key) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (w *Writer) Set(key *cache.Key, val []byte) error {
+	return w.WriteTile(uint8(key.Z), uint64(key.X), uint64(key.Y), val)
+}
+
+//	Purge is a no-op. tiles can't be removed from an archive being built
+func (w *Writer) Purge(key *cache.Key) error {
+	return nil
+}
+
+//	Abort discards the buffered tiles and removes the temporary file without writing the archive
+//	(i.e. when the seed was cancelled and the archive would be missing tiles)
+func (w *Writer) Abort() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+
+	w.tmp.Close()
+	return os.Remove(w.tmp.Name())
+}
+
+//	Close writes the archive and removes the temporary file. the header's bounds (minlon, minlat, maxlon, maxlat)
+//	and zoom range are those of the seed rather than the map's, so clients don't request tiles the archive
+//	doesn't have. the center is the middle of the bounds at the map's center zoom, within the zoom range.
+//	the metadata (name, attribution, layers...) is from the map's TileJSON
+func (w *Writer) Close(tileJSON tilejson.TileJSON, bounds [4]float64, minZoom, maxZoom uint8) error {
+	if minZoom > maxZoom {
+		return fmt.Errorf("pmtiles: invalid zoom range. min (%v) is greater than max (%v)", minZoom, maxZoom)
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+
+	defer os.Remove(w.tmp.Name())
+	defer w.tmp.Close()
+
+	entries := make([]Entry, 0, len(w.entries))
+	for _, e := range w.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].TileID < entries[j].TileID })
+
+	//	cluster the tile data: the data is written in tile ID order, each content once
+	offsets := map[uint64]uint64{}
+	order := []Entry{}
+	var dataLength uint64
+	for i := range entries {
+		tmpOffset := entries[i].Offset
+		offset, ok := offsets[tmpOffset]
+		if !ok {
+			offset = dataLength
+			offsets[tmpOffset] = offset
+			order = append(order, entries[i])
+			dataLength += uint64(entries[i].Length)
+		}
+		entries[i].Offset = offset
+	}
+
+	//	merge consecutive tiles with the same data into runs
+	var runs []Entry
+	for _, e := range entries {
+		if n := len(runs); n > 0 {
+			last := &runs[n-1]
+			if last.Offset == e.Offset && last.TileID+uint64(last.RunLength) == e.TileID {
+				last.RunLength++
+				continue
+			}
+		}
+		runs = append(runs, e)
+	}
+
+	root, leaves, err := buildDirectories(runs)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(newMetadata(tileJSON))
+	if err != nil {
+		return err
+	}
+
+	header := Header{
+		RootOffset:          HeaderLength,
+		RootLength:          uint64(len(root)),
+		AddressedTiles:      uint64(len(entries)),
+		TileEntries:         uint64(len(runs)),
+		TileContents:        uint64(len(order)),
+		Clustered:           true,
+		InternalCompression: CompressionGzip,
+		TileCompression:     CompressionGzip,
+		TileType:            TileTypeMVT,
+		MinZoom:             minZoom,
+		MaxZoom:             maxZoom,
+		MinLon:              bounds[0],
+		MinLat:              bounds[1],
+		MaxLon:              bounds[2],
+		MaxLat:              bounds[3],
+		CenterLon:           (bounds[0] + bounds[2]) / 2,
+		CenterLat:           (bounds[1] + bounds[3]) / 2,
+		CenterZoom:          centerZoom(tileJSON.Center[2], minZoom, maxZoom),
+	}
+	header.MetadataOffset = header.RootOffset + header.RootLength
+	header.MetadataLength = uint64(len(metadata))
+	header.LeafDirectoryOffset = header.MetadataOffset + header.MetadataLength
+	header.LeafDirectoryLength = uint64(len(leaves))
+	header.TileDataOffset = header.LeafDirectoryOffset + header.LeafDirectoryLength
+	header.TileDataLength = dataLength
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(w.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, b := range [][]byte{headerBytes, root, metadata, leaves} {
+		if _, err := f.Write(b); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range order {
+		if _, err := io.Copy(f, io.NewSectionReader(w.tmp, int64(e.Offset), int64(e.Length))); err != nil {
+			return err
+		}
+	}
+
+	return f.Close()
+}
+
+//	leafSize is the initial number of entries of a leaf directory
+const leafSize = 4096
+
+//	buildDirectories encodes the entries as the root directory if it fits in the first 16 KiB
+//	of the archive. otherwise the entries are split into leaf directories, growing the leaves
+//	until the root directory of the leaves fits
+func buildDirectories(entries []Entry) (root []byte, leaves []byte, err error) {
+	root, err = encodeDirectory(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(root) <= rootMaxLength-HeaderLength {
+		return root, nil, nil
+	}
+
+	for size := leafSize; ; size *= 2 {
+		var rootEntries []Entry
+		var buf bytes.Buffer
+
+		for i := 0; i < len(entries); i += size {
+			end := i + size
+			if end > len(entries) {
+				end = len(entries)
+			}
+
+			leaf, err := encodeDirectory(entries[i:end])
+			if err != nil {
+				return nil, nil, err
+			}
+
+			rootEntries = append(rootEntries, Entry{
+				TileID: entries[i].TileID,
+				Offset: uint64(buf.Len()),
+				Length: uint32(len(leaf)),
+			})
+			buf.Write(leaf)
+		}
+
+		root, err = encodeDirectory(rootEntries)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(root) <= rootMaxLength-HeaderLength {
+			return root, buf.Bytes(), nil
+		}
+	}
+}
+
+//	centerZoom returns the zoom within the zoom range closest to z
+func centerZoom(z float64, minZoom, maxZoom uint8) uint8 {
+	switch {
+	case z <= float64(minZoom):
+		return minZoom
+	case z >= float64(maxZoom):
+		return maxZoom
+	default:
+		return uint8(z)
+	}
+}
+
+//	metadataLayer is an entry of the vector_layers of the metadata
+type metadataLayer struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"`
+	MinZoom     int               `json:"minzoom"`
+	MaxZoom     int               `json:"maxzoom"`
+	Fields      map[string]string `json:"fields"`
+}
+
+//	newMetadata returns the archive metadata for the TileJSON
+func newMetadata(tileJSON tilejson.TileJSON) map[string]interface{} {
+	layers := make([]metadataLayer, 0, len(tileJSON.VectorLayers))
+	for _, l := range tileJSON.VectorLayers {
+		layers = append(layers, metadataLayer{
+			ID:      l.ID,
+			MinZoom: l.MinZoom,
+			MaxZoom: l.MaxZoom,
+			Fields:  map[string]string{},
+		})
+	}
+
+	metadata := map[string]interface{}{
+		"vector_layers": layers,
+	}
+	if tileJSON.Name != nil {
+		metadata["name"] = *tileJSON.Name
+	}
+	if tileJSON.Attribution != nil && *tileJSON.Attribution != "" {
+		metadata["attribution"] = *tileJSON.Attribution
+	}
+	if tileJSON.Description != nil && *tileJSON.Description != "" {
+		metadata["description"] = *tileJSON.Description
+	}
+
+	return metadata
+}
@@ -1,6 +1,8 @@
 package redis
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -17,8 +19,13 @@ const (
 	ConfigKeyPassword = "password"
 	ConfigKeyDB       = "db"
 	ConfigKeyMaxZoom  = "max_zoom"
+	//	ConfigKeyStaleTTL is how long tiles are kept after their ttl so they can be served stale
+	ConfigKeyStaleTTL = "stale_ttl"
 )
 
+//	DefaultStaleTTL is how long tiles are kept after their ttl when stale_ttl is not set
+const DefaultStaleTTL = time.Hour
+
 func init() {
 	cache.Register(CacheType, New)
 }
@@ -76,9 +83,20 @@ func New(config map[string]interface{}) (rcache cache.Interface, err error) {
 		return nil, err
 	}
 
+	defaultStaleTTL := DefaultStaleTTL.String()
+	staleTTLStr, err := c.String(ConfigKeyStaleTTL, &defaultStaleTTL)
+	if err != nil {
+		return nil, err
+	}
+	staleTTL, err := time.ParseDuration(staleTTLStr)
+	if err != nil || staleTTL < 0 {
+		return nil, fmt.Errorf("rediscache: invalid 'stale_ttl' (%v). expected a duration (i.e. 1h)", staleTTLStr)
+	}
+
 	return &RedisCache{
-		Redis:   client,
-		MaxZoom: maxZoom,
+		Redis:    client,
+		MaxZoom:  maxZoom,
+		StaleTTL: staleTTL,
 	}, nil
 }
 
@@ -86,6 +104,9 @@ type RedisCache struct {
 	Redis      *redis.Client
 	Expiration time.Duration
 	MaxZoom    int
+	//	StaleTTL is how long tiles written with a ttl are kept once they are older than the ttl,
+	//	so they can be served stale while they are regenerated. 0 removes the tiles at their ttl
+	StaleTTL time.Duration
 }
 
 func (rdc *RedisCache) Set(key *cache.Key, val []byte) (error) {
@@ -98,19 +119,109 @@ func (rdc *RedisCache) Set(key *cache.Key, val []byte) (error) {
 		Err()
 }
 
-func (rdc *RedisCache) Get(key *cache.Key) (val []byte, hit bool, err error) {
-	val, err = rdc.Redis.Get(key.String()).Bytes()
+//	SetTTL writes the tile like Set. redis keeps the tile for the ttl and the StaleTTL, then removes it
+func (rdc *RedisCache) SetTTL(key *cache.Key, val []byte, ttl time.Duration) error {
+	if key.Z > rdc.MaxZoom {
+		return nil
+	}
+
+	return rdc.Redis.
+		Set(key.String(), val, ttl+rdc.StaleTTL).
+		Err()
+}
+
+//	GetTTL reads the tile like Get. tiles within the StaleTTL of their expiry are stale. the age of the
+//	tile is only known from its remaining expiry, so the ttl the tile was written with is always used
+func (rdc *RedisCache) GetTTL(key *cache.Key, ttl time.Duration) ([]byte, bool, bool, error) {
+	k := key.String()
 
+	pipe := rdc.Redis.Pipeline()
+	defer pipe.Close()
+
+	get := pipe.Get(k)
+	pttl := pipe.PTTL(k)
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, false, false, err
+	}
+
+	val, err := get.Bytes()
 	switch err {
 	case nil: // cache hit
-		return val, true, nil
 	case redis.Nil: // cache miss
-		return val, false, nil
+		return nil, false, false, nil
 	default: // error
-		return val, false, err
+		return nil, false, false, err
+	}
+
+	return val, true, stale(pttl.Val(), rdc.StaleTTL), nil
+}
+
+//	stale reports whether a tile with the remaining expiry is past its ttl. tiles without an expiry never go stale
+func stale(remaining, staleTTL time.Duration) bool {
+	return remaining > 0 && remaining <= staleTTL
+}
+
+//	Get reads the tile. stale tiles are a miss
+func (rdc *RedisCache) Get(key *cache.Key) (val []byte, hit bool, err error) {
+	val, hit, isStale, err := rdc.GetTTL(key, 0)
+	if isStale {
+		return nil, false, err
 	}
+
+	return val, hit, err
 }
 
 func (rdc *RedisCache) Purge(key *cache.Key) (err error) {
 	return rdc.Redis.Del(key.String()).Err()
 }
+
+//	purgeBatchSize is the number of DEL commands sent in a pipeline
+const purgeBatchSize = 1000
+
+//	PurgeRange scans the keys of the range's zoom and deletes the keys of the range in pipelined batches
+func (rdc *RedisCache) PurgeRange(tr cache.TileRange) error {
+	prefix := tr.Namespace() + "/" + strconv.Itoa(tr.Z) + "/"
+
+	pipe := rdc.Redis.Pipeline()
+	defer pipe.Close()
+
+	var queued int
+	iter := rdc.Redis.Scan(0, prefix+"*", purgeBatchSize).Iterator()
+	for iter.Next() {
+		k := iter.Val()
+
+		//	the rest of the key is x/y
+		xy := strings.Split(strings.TrimPrefix(k, prefix), "/")
+		if len(xy) != 2 {
+			continue
+		}
+		x, err := strconv.Atoi(xy[0])
+		if err != nil || x < tr.MinX || x > tr.MaxX {
+			continue
+		}
+		y, err := strconv.Atoi(xy[1])
+		if err != nil || y < tr.MinY || y > tr.MaxY {
+			continue
+		}
+
+		pipe.Del(k)
+		queued++
+		if queued == purgeBatchSize {
+			if _, err := pipe.Exec(); err != nil {
+				return err
+			}
+			queued = 0
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+//	Close closes the connections to the redis server
+func (rdc *RedisCache) Close() error {
+	return rdc.Redis.Close()
+}
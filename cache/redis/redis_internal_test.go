@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStale(t *testing.T) {
+	type tcase struct {
+		remaining time.Duration
+		staleTTL  time.Duration
+		expected  bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		if output := stale(tc.remaining, tc.staleTTL); output != tc.expected {
+			t.Errorf("expected %v got %v", tc.expected, output)
+		}
+	}
+
+	tests := map[string]tcase{
+		"fresh": {
+			remaining: 90 * time.Minute,
+			staleTTL:  time.Hour,
+		},
+		"stale": {
+			remaining: 30 * time.Minute,
+			staleTTL:  time.Hour,
+			expected:  true,
+		},
+		//	redis reports -1 for keys without an expiry
+		"no expiry": {
+			remaining: -time.Millisecond,
+			staleTTL:  time.Hour,
+		},
+		"no stale ttl": {
+			remaining: 30 * time.Minute,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
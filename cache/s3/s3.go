@@ -3,9 +3,14 @@ package s3
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -191,6 +196,11 @@ type Cache struct {
 }
 
 func (s3c *Cache) Set(key *cache.Key, val []byte) error {
+	return s3c.SetTTL(key, val, 0)
+}
+
+//	SetTTL writes the tile like Set. the tile's expiry is stored in the object's Expires metadata
+func (s3c *Cache) SetTTL(key *cache.Key, val []byte, ttl time.Duration) error {
 	var err error
 
 	//	check for maxzoom
@@ -206,6 +216,9 @@ func (s3c *Cache) Set(key *cache.Key, val []byte) error {
 		Bucket: aws.String(s3c.Bucket),
 		Key:    aws.String(k),
 	}
+	if ttl > 0 {
+		input.Expires = aws.Time(time.Now().Add(ttl))
+	}
 
 	_, err = s3c.Client.PutObject(&input)
 	if err != nil {
@@ -216,6 +229,13 @@ func (s3c *Cache) Set(key *cache.Key, val []byte) error {
 }
 
 func (s3c *Cache) Get(key *cache.Key) ([]byte, bool, error) {
+	val, hit, _, err := s3c.GetTTL(key, 0)
+	return val, hit, err
+}
+
+//	GetTTL reads the tile like Get. tiles last modified longer than the ttl ago, or past the expiry
+//	they were written with, are stale
+func (s3c *Cache) GetTTL(key *cache.Key, ttl time.Duration) ([]byte, bool, bool, error) {
 	var err error
 
 	//	add our basepath
@@ -231,21 +251,60 @@ func (s3c *Cache) Get(key *cache.Key) ([]byte, bool, error) {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
 			case s3.ErrCodeNoSuchKey:
-				return nil, false, nil
+				return nil, false, false, nil
 			default:
-				return nil, false, aerr
+				return nil, false, false, aerr
 			}
 		}
-		return nil, false, err
+		return nil, false, false, err
 	}
 
 	var buf bytes.Buffer
 	_, err = io.Copy(&buf, result.Body)
 	if err != nil {
-		return nil, false, err
+		return nil, false, false, err
+	}
+
+	var stale bool
+	now := time.Now()
+	if ttl > 0 && result.LastModified != nil {
+		stale = now.Sub(*result.LastModified) > ttl
+	}
+	//	the Expires header is returned as the raw string
+	if result.Expires != nil {
+		if expires, err := http.ParseTime(*result.Expires); err == nil && now.After(expires) {
+			stale = true
+		}
+	}
+
+	return buf.Bytes(), true, stale, nil
+}
+
+//	Written returns the last modified time of the tile's object, implements cache.Ager
+func (s3c *Cache) Written(key *cache.Key) (time.Time, bool, error) {
+	input := s3.HeadObjectInput{
+		Bucket: aws.String(s3c.Bucket),
+		Key:    aws.String(filepath.Join(s3c.Basepath, key.String())),
+	}
+
+	result, err := s3c.Client.HeadObject(&input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			//	HEAD responses have no body, so a missing object is reported as not found
+			case s3.ErrCodeNoSuchKey, "NotFound":
+				return time.Time{}, false, nil
+			default:
+				return time.Time{}, false, aerr
+			}
+		}
+		return time.Time{}, false, err
+	}
+	if result.LastModified == nil {
+		return time.Time{}, false, nil
 	}
 
-	return buf.Bytes(), true, nil
+	return *result.LastModified, true, nil
 }
 
 func (s3c *Cache) Purge(key *cache.Key) error {
@@ -266,3 +325,70 @@ func (s3c *Cache) Purge(key *cache.Key) error {
 
 	return nil
 }
+
+//	deleteBatchSize is the max number of keys of a DeleteObjects request
+const deleteBatchSize = 1000
+
+//	PurgeRange lists the tiles of each column of the range by prefix and removes the tiles
+//	of the range with batched DeleteObjects requests
+func (s3c *Cache) PurgeRange(tr cache.TileRange) error {
+	var batch []*s3.ObjectIdentifier
+
+	deleteBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		output, err := s3c.Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(s3c.Bucket),
+			Delete: &s3.Delete{
+				Objects: batch,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		//	quiet mode only reports the keys which failed to delete
+		if len(output.Errors) > 0 {
+			e := output.Errors[0]
+			return fmt.Errorf("s3cache: error deleting (%v): %v", aws.StringValue(e.Key), aws.StringValue(e.Message))
+		}
+
+		return nil
+	}
+
+	for x := tr.MinX; x <= tr.MaxX; x++ {
+		prefix := filepath.Join(s3c.Basepath, tr.Namespace(), strconv.Itoa(tr.Z), strconv.Itoa(x)) + "/"
+
+		var batchErr error
+		err := s3c.Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: aws.String(s3c.Bucket),
+			Prefix: aws.String(prefix),
+		}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, obj := range page.Contents {
+				y, err := strconv.Atoi(path.Base(aws.StringValue(obj.Key)))
+				if err != nil || y < tr.MinY || y > tr.MaxY {
+					continue
+				}
+
+				batch = append(batch, &s3.ObjectIdentifier{Key: obj.Key})
+				if len(batch) == deleteBatchSize {
+					if batchErr = deleteBatch(); batchErr != nil {
+						return false
+					}
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if batchErr != nil {
+			return batchErr
+		}
+	}
+
+	return deleteBatch()
+}
@@ -331,3 +331,86 @@ func TestMaxZoom(t *testing.T) {
 		})
 	}
 }
+
+func TestPurgeRange(t *testing.T) {
+	if os.Getenv("RUN_S3_TESTS") != "yes" {
+		return
+	}
+
+	type tcase struct {
+		tr cache.TileRange
+		// the columns and rows of zoom 2 expected to be purged
+		purged func(x, y int) bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		c, err := s3.New(map[string]interface{}{
+			"bucket":   os.Getenv("AWS_TEST_BUCKET"),
+			"basepath": "tegola-purge-range",
+			"region":   os.Getenv("AWS_REGION"),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				if err := c.Set(&key, []byte{1}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		}
+		//	a layer tile within the range is not purged
+		layerKey := cache.Key{MapName: "test-map", LayerName: "water", Z: 2, X: 1, Y: 1}
+		if err := c.Set(&layerKey, []byte{1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := c.(cache.RangePurger).PurgeRange(tc.tr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				key := cache.Key{MapName: "test-map", Z: 2, X: x, Y: y}
+				_, hit, err := c.Get(&key)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if hit == tc.purged(x, y) {
+					t.Errorf("tile (%v/%v), expected purged %v got hit %v", x, y, tc.purged(x, y), hit)
+				}
+			}
+		}
+		if _, hit, _ := c.Get(&layerKey); !hit {
+			t.Errorf("expected the layer tile to not have been purged")
+		}
+	}
+
+	tests := map[string]tcase{
+		"columns": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 1, MaxX: 2, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return x >= 1 && x <= 2 },
+		},
+		"rows": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 2, MinX: 0, MaxX: 3, MinY: 1, MaxY: 1},
+			purged: func(x, y int) bool { return y == 1 },
+		},
+		"other map": {
+			tr:     cache.TileRange{MapName: "other-map", Z: 2, MinX: 0, MaxX: 3, MinY: 0, MaxY: 3},
+			purged: func(x, y int) bool { return false },
+		},
+		"other zoom": {
+			tr:     cache.TileRange{MapName: "test-map", Z: 3, MinX: 0, MaxX: 7, MinY: 0, MaxY: 7},
+			purged: func(x, y int) bool { return false },
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
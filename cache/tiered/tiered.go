@@ -0,0 +1,290 @@
+package tiered
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/internal/log"
+	"github.com/go-spatial/tegola/util/dict"
+)
+
+var (
+	ErrMissingTiers = errors.New("tieredcache: missing required param 'tiers'")
+)
+
+const CacheType = "tiered"
+
+const (
+	ConfigKeyTiers = "tiers"
+	//	tier config keys
+	ConfigKeyType        = "type"
+	ConfigKeyWriteBehind = "write_behind"
+)
+
+//	writeBehindQueueSize is the number of writes a write behind tier can fall behind before Set blocks
+const writeBehindQueueSize = 256
+
+func init() {
+	cache.Register(CacheType, New)
+}
+
+//	New instantiates a tiered cache. The config expects the following params:
+//
+//		tiers ([]map): the config of each tier, fastest first. each tier supports the params
+//			of its cache type plus:
+//
+//			type (string): the type of the cache backend (i.e. memory)
+//			write_behind (bool): [Optional] write the tier in the background. Set returns
+//				before the tile is written to the tier. defaults to false
+//
+func New(config map[string]interface{}) (cache.Interface, error) {
+	var tierConfigs []map[string]interface{}
+	switch t := config[ConfigKeyTiers].(type) {
+	case []map[string]interface{}:
+		tierConfigs = t
+	case []interface{}:
+		for i := range t {
+			tc, ok := t[i].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("tieredcache: tier (%v) must be a table of params. got (%T)", i, t[i])
+			}
+			tierConfigs = append(tierConfigs, tc)
+		}
+	}
+	if len(tierConfigs) == 0 {
+		return nil, ErrMissingTiers
+	}
+
+	tc := Cache{}
+	for i := range tierConfigs {
+		c := dict.M(tierConfigs[i])
+
+		cType, err := c.String(ConfigKeyType, nil)
+		if err != nil {
+			return nil, fmt.Errorf("tieredcache: tier (%v): %v", i, err)
+		}
+
+		var writeBehind bool
+		if v, ok := c[ConfigKeyWriteBehind]; ok {
+			if writeBehind, ok = v.(bool); !ok {
+				return nil, fmt.Errorf("tieredcache: tier (%v): %v value needs to be of type bool. Value is of type %T", i, ConfigKeyWriteBehind, v)
+			}
+		}
+
+		backend, err := cache.For(cType, tierConfigs[i])
+		if err != nil {
+			return nil, fmt.Errorf("tieredcache: tier (%v): %v", i, err)
+		}
+
+		tc.Tiers = append(tc.Tiers, NewTier(backend, writeBehind))
+	}
+
+	return &tc, nil
+}
+
+//	Cache composes multiple caches into tiers, implements the cache.Interface.
+//	Get reads the tiers in order, filling the faster tiers on a hit. Set and Purge apply to every tier.
+type Cache struct {
+	//	Tiers are ordered fastest first
+	Tiers []*Tier
+}
+
+//	Tier is a cache of a tiered cache
+type Tier struct {
+	cache.Interface
+	//	WriteBehind tiers are written in the background
+	WriteBehind bool
+
+	//	the pending writes of write behind tiers. purges go through the queue so they are applied in order
+	queue   chan func() error
+	pending sync.WaitGroup
+	//	closeQueue closes the queue once so the cache can be closed more than once
+	closeQueue sync.Once
+}
+
+//	NewTier returns a tier for the cache. the writes of write behind tiers are applied in order by a background worker
+func NewTier(c cache.Interface, writeBehind bool) *Tier {
+	t := Tier{
+		Interface:   c,
+		WriteBehind: writeBehind,
+	}
+	if writeBehind {
+		t.queue = make(chan func() error, writeBehindQueueSize)
+		go t.work()
+	}
+
+	return &t
+}
+
+func (t *Tier) work() {
+	for fn := range t.queue {
+		if err := fn(); err != nil {
+			log.Errorf("tieredcache: write behind: %v", err)
+		}
+		t.pending.Done()
+	}
+}
+
+//	write applies the write to the tier, in the background for write behind tiers
+func (t *Tier) write(fn func() error) error {
+	if !t.WriteBehind {
+		return fn()
+	}
+
+	t.pending.Add(1)
+	t.queue <- fn
+	return nil
+}
+
+func (tc *Cache) Get(key *cache.Key) ([]byte, bool, error) {
+	val, hit, stale, err := tc.GetTTL(key, 0)
+	if stale {
+		return nil, false, err
+	}
+
+	return val, hit, err
+}
+
+//	GetTTL reads the tiers in order until a fresh tile is found, filling the faster tiers with it.
+//	when the tiers only have stale tiles, the stale tile of the fastest tier is returned.
+//	tiles read with a ttl fill the faster tiers with what is left of the ttl, so a filled tile doesn't
+//	stay fresh longer than the tile it was read from. tiers which implement cache.Ager report when the
+//	tile was written, the tiles of other tiers are filled with the whole ttl
+func (tc *Cache) GetTTL(key *cache.Key, ttl time.Duration) ([]byte, bool, bool, error) {
+	//	the key is copied as write behind tiers use it after GetTTL returns
+	k := *key
+
+	var staleVal []byte
+	var staleHit bool
+
+	for i, t := range tc.Tiers {
+		val, hit, stale, err := cache.GetTTL(t.Interface, key, ttl)
+		if err != nil {
+			//	a slower tier may still have the tile
+			log.Errorf("tieredcache: error reading tier (%v): %v", i, err)
+			continue
+		}
+		if !hit {
+			continue
+		}
+		if stale {
+			if !staleHit {
+				staleVal, staleHit = val, true
+			}
+			continue
+		}
+
+		if i > 0 {
+			tc.fill(i, &k, val, ttl)
+		}
+
+		return val, true, false, nil
+	}
+
+	if staleHit {
+		return staleVal, true, true, nil
+	}
+
+	return nil, false, false, nil
+}
+
+//	fill writes the tile read from tier i to the faster tiers with what is left of the ttl
+func (tc *Cache) fill(i int, key *cache.Key, val []byte, ttl time.Duration) {
+	if ttl > 0 {
+		written, ok, err := cache.Written(tc.Tiers[i].Interface, key)
+		if err != nil {
+			log.Errorf("tieredcache: error reading the write time of tier (%v): %v", i, err)
+		}
+		if ok {
+			ttl -= time.Since(written)
+			//	the tile is about to go stale, leave it to be regenerated
+			if ttl <= 0 {
+				return
+			}
+		}
+	}
+
+	for _, ft := range tc.Tiers[:i] {
+		ft := ft
+		if err := ft.write(func() error { return cache.SetTTL(ft.Interface, key, val, ttl) }); err != nil {
+			log.Errorf("tieredcache: error filling tier: %v", err)
+		}
+	}
+}
+
+//	Set writes the tile to every tier. the first error is returned after every tier has been written
+func (tc *Cache) Set(key *cache.Key, val []byte) error {
+	return tc.SetTTL(key, val, 0)
+}
+
+//	SetTTL writes the tile to every tier with the ttl
+func (tc *Cache) SetTTL(key *cache.Key, val []byte, ttl time.Duration) error {
+	k := *key
+
+	var firstErr error
+	for _, t := range tc.Tiers {
+		t := t
+		if err := t.write(func() error { return cache.SetTTL(t.Interface, &k, val, ttl) }); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+//	Purge removes the tile from every tier. the first error is returned after every tier has been purged
+func (tc *Cache) Purge(key *cache.Key) error {
+	k := *key
+
+	var firstErr error
+	for _, t := range tc.Tiers {
+		t := t
+		if err := t.write(func() error { return t.Purge(&k) }); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+//	PurgeRange removes the tiles of the range from every tier, in bulk for tiers which implement cache.RangePurger.
+//	the first error is returned after every tier has been purged
+func (tc *Cache) PurgeRange(tr cache.TileRange) error {
+	var firstErr error
+	for _, t := range tc.Tiers {
+		t := t
+		if err := t.write(func() error { return cache.PurgeRange(t.Interface, tr) }); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+//	Flush blocks until the pending writes of the write behind tiers have completed
+func (tc *Cache) Flush() {
+	for _, t := range tc.Tiers {
+		t.pending.Wait()
+	}
+}
+
+//	Close waits for the pending writes of the write behind tiers, then closes every tier.
+//	the first error is returned after every tier has been closed
+func (tc *Cache) Close() error {
+	tc.Flush()
+
+	var firstErr error
+	for _, t := range tc.Tiers {
+		if t.WriteBehind {
+			t.closeQueue.Do(func() { close(t.queue) })
+		}
+		if err := cache.Close(t.Interface); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,249 @@
+package tiered_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/memory"
+	"github.com/go-spatial/tegola/cache/tiered"
+)
+
+func TestNew(t *testing.T) {
+	type tcase struct {
+		config      map[string]interface{}
+		tiers       int
+		writeBehind []bool
+		err         bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		output, err := tiered.New(tc.config)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected an error got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		tiers := output.(*tiered.Cache).Tiers
+		if len(tiers) != len(tc.writeBehind) {
+			t.Fatalf("tiers, expected %v got %v", len(tc.writeBehind), len(tiers))
+		}
+		for i := range tiers {
+			if tiers[i].WriteBehind != tc.writeBehind[i] {
+				t.Errorf("tier (%v) write behind, expected %v got %v", i, tc.writeBehind[i], tiers[i].WriteBehind)
+			}
+			if _, ok := tiers[i].Interface.(*memory.Cache); !ok {
+				t.Errorf("tier (%v), expected a memory cache got %T", i, tiers[i].Interface)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"tiers": {
+			config: map[string]interface{}{
+				"tiers": []map[string]interface{}{
+					{"type": "memory"},
+					{"type": "memory", "write_behind": true},
+				},
+			},
+			writeBehind: []bool{false, true},
+		},
+		"missing tiers": {
+			config: map[string]interface{}{},
+			err:    true,
+		},
+		"unknown tier type": {
+			config: map[string]interface{}{
+				"tiers": []interface{}{
+					map[string]interface{}{"type": "tape"},
+				},
+			},
+			err: true,
+		},
+		"invalid write_behind": {
+			config: map[string]interface{}{
+				"tiers": []interface{}{
+					map[string]interface{}{"type": "memory", "write_behind": "yes"},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	for _, writeBehind := range []bool{false, true} {
+		fast := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+		slow := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+
+		tc := tiered.Cache{
+			Tiers: []*tiered.Tier{
+				tiered.NewTier(fast, false),
+				tiered.NewTier(slow, writeBehind),
+			},
+		}
+
+		key := cache.Key{MapName: "osm", Z: 1, X: 1, Y: 1}
+		tile := []byte("tile")
+
+		// set writes through every tier
+		if err := tc.Set(&key, tile); err != nil {
+			t.Fatalf("[write behind %v] unexpected error: %v", writeBehind, err)
+		}
+		tc.Flush()
+		for i, c := range []*memory.Cache{fast, slow} {
+			if _, hit, _ := c.Get(&key); !hit {
+				t.Errorf("[write behind %v] expected tier (%v) to have the tile", writeBehind, i)
+			}
+		}
+
+		// a hit on the slow tier fills the fast tier
+		if err := fast.Purge(&key); err != nil {
+			t.Fatalf("[write behind %v] unexpected error: %v", writeBehind, err)
+		}
+		val, hit, err := tc.Get(&key)
+		if err != nil || !hit || string(val) != string(tile) {
+			t.Errorf("[write behind %v] expected a hit with (%s) got (%s) hit %v err %v", writeBehind, tile, val, hit, err)
+		}
+		if _, hit, _ := fast.Get(&key); !hit {
+			t.Errorf("[write behind %v] expected the fast tier to have been filled", writeBehind)
+		}
+
+		// purge cascades
+		if err := tc.Purge(&key); err != nil {
+			t.Fatalf("[write behind %v] unexpected error: %v", writeBehind, err)
+		}
+		tc.Flush()
+		if _, hit, _ := tc.Get(&key); hit {
+			t.Errorf("[write behind %v] expected a miss after the purge", writeBehind)
+		}
+	}
+}
+
+//	getter hides the cache.Ager and cache.Expirer implementations of the cache
+type getter struct {
+	cache.Interface
+}
+
+func TestGetTTLFill(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+
+	type tcase struct {
+		//	wraps the slow tier
+		slow func(c *memory.Cache) cache.Interface
+		//	whether the filled tile is fresh once the slow tile is stale
+		fresh bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		fast := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+		slow := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+
+		c := tiered.Cache{
+			Tiers: []*tiered.Tier{
+				tiered.NewTier(fast, false),
+				tiered.NewTier(tc.slow(slow), false),
+			},
+		}
+
+		key := cache.Key{MapName: "osm", Z: 1, X: 1, Y: 1}
+		if err := slow.Set(&key, []byte("tile")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(ttl / 2)
+
+		val, hit, stale, err := c.GetTTL(&key, ttl)
+		if err != nil || !hit || stale || string(val) != "tile" {
+			t.Fatalf("expected a fresh hit with (tile) got (%s) hit %v stale %v err %v", val, hit, stale, err)
+		}
+		if _, hit, stale, _ := fast.GetTTL(&key, 0); !hit || stale {
+			t.Fatalf("expected the fast tier to have been filled got hit %v stale %v", hit, stale)
+		}
+
+		//	the slow tile is stale, the fast tile is stale too unless the age of the slow tile is unknown
+		time.Sleep(ttl * 3 / 4)
+		if _, hit, stale, _ := fast.GetTTL(&key, 0); !hit || stale == tc.fresh {
+			t.Errorf("expected a hit with fresh %v got hit %v stale %v", tc.fresh, hit, stale)
+		}
+	}
+
+	tests := map[string]tcase{
+		"ager": {
+			slow: func(c *memory.Cache) cache.Interface { return c },
+		},
+		"unknown age": {
+			slow:  func(c *memory.Cache) cache.Interface { return getter{c} },
+			fresh: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestPurgeRange(t *testing.T) {
+	fast := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+	slow := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+
+	tc := tiered.Cache{
+		Tiers: []*tiered.Tier{
+			tiered.NewTier(fast, false),
+			tiered.NewTier(slow, true),
+		},
+	}
+
+	purged := cache.Key{MapName: "osm", Z: 1, X: 1, Y: 1}
+	kept := cache.Key{MapName: "osm", Z: 1, X: 0, Y: 1}
+	for _, key := range []cache.Key{purged, kept} {
+		key := key
+		if err := tc.Set(&key, []byte("tile")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := tc.PurgeRange(cache.TileRange{MapName: "osm", Z: 1, MinX: 1, MinY: 0, MaxX: 1, MaxY: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tc.Flush()
+
+	for i, c := range []*memory.Cache{fast, slow} {
+		if _, hit, _ := c.Get(&purged); hit {
+			t.Errorf("tier (%v), expected the tile to have been purged", i)
+		}
+		if _, hit, _ := c.Get(&kept); !hit {
+			t.Errorf("tier (%v), expected the tile outside of the range to have been kept", i)
+		}
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	tc := tiered.Cache{
+		Tiers: []*tiered.Tier{
+			tiered.NewTier(memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0), false),
+			tiered.NewTier(memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0), true),
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := tc.Close(); err != nil {
+			t.Errorf("close (%v), unexpected error: %v", i, err)
+		}
+	}
+}
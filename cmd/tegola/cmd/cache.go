@@ -16,6 +16,7 @@ import (
 	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/atlas"
 	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/pmtiles"
 	"github.com/go-spatial/tegola/internal/log"
 	"github.com/go-spatial/tegola/maths/webmercator"
 	"github.com/go-spatial/tegola/provider"
@@ -36,6 +37,18 @@ var (
 	cacheConcurrency int
 	//	cache overwrite
 	cacheOverwrite bool
+	//	seed into a PMTiles archive at this path instead of the configured cache
+	cachePMTiles string
+	//	a file of tiles in the z/x/y format to seed or purge instead of the bounds. "-" reads from stdin
+	cacheTileList string
+	//	a GeoJSON or WKT file of polygons. only tiles intersecting the polygons are cached
+	cacheAOI string
+	//	a file recording the completed columns of a seed so an interrupted seed can be resumed
+	cacheCheckpoint string
+	//	how often to report the progress of the job
+	cacheProgressInterval time.Duration
+	//	the partition of the tiles to cache in the i/n format, so n processes can split a job
+	cachePartition string
 )
 
 var cacheCmd = &cobra.Command{
@@ -79,15 +92,56 @@ var cacheCmd = &cobra.Command{
 			maps = atlas.AllMaps()
 		}
 
+		//	seed into a PMTiles archive rather than the configured cache backend
+		var archive *pmtiles.Writer
+		if cachePMTiles != "" {
+			if args[0] != "seed" {
+				log.Fatal("--pmtiles is only supported by cache seed")
+			}
+			if len(maps) != 1 {
+				log.Fatal("--pmtiles archives hold a single map. select one with --map")
+			}
+
+			archive, err = pmtiles.NewWriter(cachePMTiles)
+			if err != nil {
+				log.Fatal(err)
+			}
+			atlas.SetCache(archive)
+		}
+
 		//	check for a cache backend
 		if atlas.GetCache() == nil {
 			log.Fatalf("mising cache backend. check your config (%v)", configFile)
 		}
 
 		var zooms []int
-		var minx, miny, maxx, maxy int
 		var bounds [4]float64
 
+		//	tile list caching. the listed tiles are expanded to their ancestors and descendants within the zoom range
+		var tileList *tileList
+		if cacheTileList != "" {
+			if cacheZXY != "" {
+				log.Fatal("--tile-list and --zxy can't be used together")
+			}
+			if cacheMaxZoom != 0 && cacheMinZoom > cacheMaxZoom {
+				log.Fatalf("invalid zoom range. min (%v) is greater than max (%v)", cacheMinZoom, cacheMaxZoom)
+			}
+
+			tiles, err := readTileList(cacheTileList)
+			if err != nil {
+				log.Fatalf("error reading tile list (%v): %v", cacheTileList, err)
+			}
+
+			//	without a max zoom the listed tiles are not expanded to their descendants
+			maxZoom := -1
+			if cacheMaxZoom != 0 {
+				maxZoom = int(cacheMaxZoom)
+			}
+
+			tileList = newTileList(tiles, int(cacheMinZoom), maxZoom)
+			log.Infof("tile list (%v) has %v tiles, expanded to %v tiles", cacheTileList, len(tiles), tileList.Len())
+		}
+
 		//	single tile caching
 		if cacheZXY != "" {
 			//	convert the input into a tile
@@ -131,7 +185,23 @@ var cacheCmd = &cobra.Command{
 			}
 		}
 
-		if len(zooms) == 0 {
+		//	area of interest caching. the bounds of the area replace the bounds and tiles outside of the area are skipped
+		var area *aoi
+		if cacheAOI != "" {
+			if cacheZXY != "" || cacheTileList != "" {
+				log.Fatal("--aoi can't be used with --zxy or --tile-list")
+			}
+
+			area, err = readAOI(cacheAOI)
+			if err != nil {
+				log.Fatalf("error reading area of interest (%v): %v", cacheAOI, err)
+			}
+
+			bounds = area.bounds
+		}
+
+		//	the tile list replaces the zoom range
+		if len(zooms) == 0 && cacheTileList == "" {
 			//	check user input for zoom range
 			if cacheMaxZoom != 0 {
 				if cacheMaxZoom >= cacheMinZoom {
@@ -150,6 +220,105 @@ var cacheCmd = &cobra.Command{
 
 		}
 
+		//	partitioned caching. each process caches the tiles of its partition of the job
+		var part *partition
+		if cachePartition != "" {
+			part, err = parsePartition(cachePartition)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		//	include reports if a tile of the zoom range is to be cached
+		include := func(z, x, y int) bool {
+			if area != nil && !area.Intersects(z, x, y) {
+				return false
+			}
+			return part == nil || part.Contains(z, x, y)
+		}
+
+		//	caches which can purge a range of tiles in bulk are purged a zoom at a time rather than by the workers.
+		//	the tiles of a tile list are purged by the workers, so their progress is reported
+		_, rangePurger := atlas.GetCache().(cache.RangePurger)
+		rangePurge := args[0] == "purge" && rangePurger && area == nil && part == nil && tileList == nil
+
+		//	the number of tiles of a column, for every map
+		columnTiles := func(z, x, miny, maxy int) (n int) {
+			for y := miny; y <= maxy; y++ {
+				if include(z, x, y) {
+					n += len(maps)
+				}
+			}
+			return n
+		}
+
+		//	count the tiles of the job for the progress reports. the tiles of an area of interest or a partition
+		//	are only known once they are tested, so the total is left unknown (0) rather than walking every tile
+		total := 0
+		if area == nil && part == nil {
+			if tileList != nil {
+				total += tileList.Len() * len(maps)
+			}
+			if !rangePurge {
+				for _, z := range zooms {
+					if minx, miny, maxx, maxy := boundsTileRange(z, bounds); maxx >= minx && maxy >= miny {
+						total += (maxx - minx + 1) * (maxy - miny + 1) * len(maps)
+					}
+				}
+			}
+		}
+
+		action := "seeded"
+		if args[0] == "purge" {
+			action = "purged"
+		}
+		progress := newSeedProgress(action, total)
+
+		//	the checkpoint of a resumable seed
+		var checkpoint *seedCheckpoint
+		if cacheCheckpoint != "" {
+			if args[0] != "seed" {
+				log.Fatal("--checkpoint is only supported by seed")
+			}
+			//	archives are written from scratch by each run
+			if cachePMTiles != "" {
+				log.Fatal("--checkpoint can't be used with --pmtiles")
+			}
+
+			mapNames := make([]string, 0, len(maps))
+			for m := range maps {
+				mapNames = append(mapNames, maps[m].Name)
+			}
+			//	the columns of the checkpoint are only valid for the same tiles
+			signature := fmt.Sprintf("maps=%v zoom=%v-%v bounds=%v aoi=%v tile-list=%v partition=%v", strings.Join(mapNames, ","), cacheMinZoom, cacheMaxZoom, bounds, cacheAOI, cacheTileList, cachePartition)
+
+			checkpoint, err = openCheckpoint(cacheCheckpoint, signature)
+			if err != nil {
+				log.Fatalf("error opening checkpoint (%v): %v", cacheCheckpoint, err)
+			}
+			defer checkpoint.Close()
+		}
+
+		//	record a processed tile in the checkpoint
+		checkpointDone := func(mt MapTile, failed bool) {
+			if checkpoint == nil {
+				return
+			}
+			if err := checkpoint.Done(mt.Tile.Z, mt.Tile.X, failed); err != nil {
+				log.Errorf("error writing checkpoint (%v): %v", cacheCheckpoint, err)
+			}
+		}
+
+		//	record a column which has been sent to the workers in the checkpoint
+		checkpointDispatched := func(z, x int) {
+			if checkpoint == nil {
+				return
+			}
+			if err := checkpoint.Dispatched(z, x); err != nil {
+				log.Errorf("error writing checkpoint (%v): %v", cacheCheckpoint, err)
+			}
+		}
+
 		//	setup a waitgroup
 		var wg sync.WaitGroup
 
@@ -207,23 +376,25 @@ var cacheCmd = &cobra.Command{
 							//	read the tile from the cache
 							_, hit, err := c.Get(&key)
 							if err != nil {
-								log.Fatal("error reading from cache: %v", err)
+								log.Fatalf("error reading from cache: %v", err)
 							}
 							//	if we have a cache hit, then skip processing this tile
 							if hit {
 								log.Infof("cache seed set to not overwrite existing tiles. skipping map (%v) tile (%v/%v/%v)", mt.MapName, mt.Tile.Z, mt.Tile.X, mt.Tile.Y)
+								progress.Skipped()
+								checkpointDone(mt, false)
 								continue
 							}
 						}
 
-						//	set tile buffer if it was configured by the user
-						if conf.TileBuffer > 0 {
-							mt.Tile.Buffer = float64(conf.TileBuffer)
-						}
-
 						//	seed the tile
 						if err = atlas.SeedMapTile(ctx, m, uint64(mt.Tile.Z), uint64(mt.Tile.X), uint64(mt.Tile.Y)); err != nil {
 							log.Errorf("error seeding tile (%+v): %v", mt.Tile, err)
+							//	tiles interrupted by a cancel are not failures. their columns are left to be resumed
+							if !gdcmd.IsCancelled() {
+								progress.Failed(mt, err)
+								checkpointDone(mt, true)
+							}
 							break
 						}
 
@@ -233,6 +404,9 @@ var cacheCmd = &cobra.Command{
 
 						log.Infof("seeding map (%v) tile (%v/%v/%v) took: %v", mt.MapName, mt.Tile.Z, mt.Tile.X, mt.Tile.Y, time.Now().Sub(t))
 
+						progress.Done()
+						checkpointDone(mt, false)
+
 					case "purge":
 						log.Infof("purging map (%v) tile (%v/%v/%v)", mt.MapName, mt.Tile.Z, mt.Tile.X, mt.Tile.Y)
 
@@ -245,8 +419,11 @@ var cacheCmd = &cobra.Command{
 						//	purge the tile
 						if err = atlas.PurgeMapTile(m, mt.Tile); err != nil {
 							log.Errorf("error purging tile (%+v): %v", mt.Tile, err)
+							progress.Failed(mt, err)
 							break
 						}
+
+						progress.Done()
 					}
 				}
 
@@ -254,26 +431,102 @@ var cacheCmd = &cobra.Command{
 				wg.Done()
 			}(tiler)
 		}
+		if !rangePurge {
+			stopProgress := progress.Start(cacheProgressInterval)
+			defer stopProgress()
+		}
+
+		//	iterate the tile list
+		if tileList != nil {
+			//	the list is ordered by zoom and column so a column is dispatched once a tile of another column is reached
+			column, started, cancelled := [2]int{}, false, false
+
+			tileList.ForEach(func(z, x, y int) bool {
+				if started && column != [2]int{z, x} {
+					checkpointDispatched(column[0], column[1])
+				}
+				column, started = [2]int{z, x}, true
+
+				if !include(z, x, y) {
+					return true
+				}
+
+				//	skip the columns completed by a previous run
+				if checkpoint != nil && checkpoint.Completed(z, x) {
+					progress.Resumed(len(maps))
+					return true
+				}
+
+				//	range maps
+				for m := range maps {
+					mapTile := MapTile{
+						MapName: maps[m].Name,
+						Tile:    tegola.NewTile(z, x, y),
+					}
+					if checkpoint != nil {
+						checkpoint.Add(z, x)
+					}
+					select {
+					case tiler <- mapTile:
+					case <-gdcmd.Cancelled():
+						log.Info("cancel recieved; cleaning up…")
+						cancelled = true
+						return false
+					}
+				}
+				return true
+			})
+
+			if started && !cancelled {
+				checkpointDispatched(column[0], column[1])
+			}
+		}
+
 		//	iterate our zoom range
 	ZoomLoop:
 		for i := range zooms {
 
-			topLeft := *tegola.NewTileLatLong(zooms[i], bounds[1], bounds[0])
-			minx, maxy = topLeft.Deg2Num()
+			minx, miny, maxx, maxy := boundsTileRange(zooms[i], bounds)
+
+			if rangePurge {
+				for m := range maps {
+					if gdcmd.IsCancelled() {
+						log.Info("cancel recieved; cleaning up…")
+						break ZoomLoop
+					}
+
+					log.Infof("purging map (%v) zoom (%v) tiles (%v/%v) to (%v/%v)", maps[m].Name, zooms[i], minx, miny, maxx, maxy)
 
-			bottomRight := *tegola.NewTileLatLong(zooms[i], bounds[3], bounds[2])
-			maxx, miny = bottomRight.Deg2Num()
+					if err := atlas.PurgeMapTileRange(maps[m], uint64(zooms[i]), uint64(minx), uint64(miny), uint64(maxx), uint64(maxy)); err != nil {
+						log.Errorf("error purging map (%v) zoom (%v): %v", maps[m].Name, zooms[i], err)
+					}
+				}
+				continue
+			}
 
 			//	range rows
 			for x := minx; x <= maxx; x++ {
+				//	skip the columns completed by a previous run
+				if checkpoint != nil && checkpoint.Completed(zooms[i], x) {
+					progress.Resumed(columnTiles(zooms[i], x, miny, maxy))
+					continue
+				}
+
 				//	range columns
 				for y := miny; y <= maxy; y++ {
+					if !include(zooms[i], x, y) {
+						continue
+					}
+
 					//	range maps
 					for m := range maps {
 						mapTile := MapTile{
 							MapName: maps[m].Name,
 							Tile:    tegola.NewTile(zooms[i], x, y),
 						}
+						if checkpoint != nil {
+							checkpoint.Add(zooms[i], x)
+						}
 						select {
 						case tiler <- mapTile:
 						case <-gdcmd.Cancelled():
@@ -283,6 +536,8 @@ var cacheCmd = &cobra.Command{
 
 					}
 				}
+
+				checkpointDispatched(zooms[i], x)
 			}
 		}
 
@@ -291,9 +546,57 @@ var cacheCmd = &cobra.Command{
 
 		//	wait for the workers to complete any remaining jobs
 		wg.Wait()
+
+		if !rangePurge {
+			progress.Summary()
+		}
+
+		//	wait for caches writing in the background to complete their writes
+		if f, ok := atlas.GetCache().(cache.Flusher); ok {
+			f.Flush()
+		}
+
+		//	a cancelled seed would leave an archive missing tiles which claims the whole area and zooms of the seed
+		if archive != nil && gdcmd.IsCancelled() {
+			if err := archive.Abort(); err != nil {
+				log.Errorf("error removing the temporary file of the PMTiles archive (%v): %v", cachePMTiles, err)
+			}
+			log.Warnf("seed cancelled, PMTiles archive (%v) not written", cachePMTiles)
+			return
+		}
+
+		//	write the archive's directories and metadata. the header holds the area and zooms of the seed
+		if archive != nil {
+			archiveBounds, minZoom, maxZoom := bounds, 0, 0
+			if tileList != nil {
+				archiveBounds, minZoom, maxZoom = tileList.Bounds(), tileList.minZoom, tileList.lastZoom
+			} else if len(zooms) > 0 {
+				minZoom, maxZoom = zooms[0], zooms[len(zooms)-1]
+			}
+			//	a tile list with no tiles within the zoom range
+			if maxZoom < minZoom {
+				maxZoom = minZoom
+			}
+
+			if err = archive.Close(maps[0].TileJSON(nil), archiveBounds, uint8(minZoom), uint8(maxZoom)); err != nil {
+				log.Fatalf("error writing PMTiles archive (%v): %v", cachePMTiles, err)
+			}
+			log.Infof("wrote PMTiles archive (%v)", cachePMTiles)
+		}
 	},
 }
 
+//	boundsTileRange returns the range of tiles of the lat / long bounds at zoom z
+func boundsTileRange(z int, bounds [4]float64) (minx, miny, maxx, maxy int) {
+	topLeft := *tegola.NewTileLatLong(z, bounds[1], bounds[0])
+	minx, maxy = topLeft.Deg2Num()
+
+	bottomRight := *tegola.NewTileLatLong(z, bounds[3], bounds[2])
+	maxx, miny = bottomRight.Deg2Num()
+
+	return minx, miny, maxx, maxy
+}
+
 type MapTile struct {
 	MapName string
 	Tile    *tegola.Tile
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+
+	"github.com/go-spatial/tegola/geom"
+	"github.com/go-spatial/tegola/geom/encoding/geojson"
+	"github.com/go-spatial/tegola/geom/encoding/wkt"
+)
+
+//	the latitude limit of the web mercator tile grid
+const maxLat = 85.0511
+
+type tileClass uint8
+
+const (
+	//	the tile is outside of the area of interest, as are its descendants
+	tileOutside tileClass = iota
+	//	the tile is within the area of interest, as are its descendants
+	tileInside
+	//	the boundary of the area of interest crosses the tile
+	tilePartial
+)
+
+type aoiTile struct {
+	z, x, y int
+}
+
+//	aoiClass is the classification of a tile. partial tiles keep the edges crossing them so their
+//	children only test those edges
+type aoiClass struct {
+	class tileClass
+	edges []int
+}
+
+//	aoi is an area of interest of polygons. the polygons are projected to the tile grid of zoom 0,
+//	where the world spans 0 to 1 with the origin at the top left
+type aoi struct {
+	//	the lon / lat bounds of the polygons: minx, miny, maxx, maxy
+	bounds [4]float64
+	//	the edges of every ring of the polygons, in tile grid coordinates
+	edges [][2][2]float64
+
+	//	the tiles classified so far. tiles are only kept when their parent is partial,
+	//	the classes of the other tiles are inherited from their ancestors
+	classes map[aoiTile]aoiClass
+}
+
+//	readAOI reads an area of interest from a GeoJSON (geometry, feature or feature collection) or WKT file
+//	of polygons and multipolygons. coordinates are expected to be longitude, latitude
+func readAOI(path string) (*aoi, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var geo geom.Geometry
+
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("{")) {
+		geo, err = decodeGeoJSON(b)
+	} else {
+		geo, err = wkt.Decode(string(b))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	polygons, err := aoiPolygons(geo)
+	if err != nil {
+		return nil, err
+	}
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("no polygons found")
+	}
+
+	return newAOI(polygons), nil
+}
+
+//	geoJSONFeature holds the geometry of a feature. the other members (i.e. the id, which may be a string
+//	or a negative number) are not decoded
+type geoJSONFeature struct {
+	Geometry geojson.Geometry `json:"geometry"`
+}
+
+//	decodeGeoJSON decodes a GeoJSON object. the geometries of features are decoded as a collection
+func decodeGeoJSON(b []byte) (geom.Geometry, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(b, &object); err != nil {
+		return nil, err
+	}
+
+	switch object.Type {
+	case "FeatureCollection":
+		var fc struct {
+			Features []geoJSONFeature `json:"features"`
+		}
+		if err := json.Unmarshal(b, &fc); err != nil {
+			return nil, err
+		}
+
+		col := make(geom.Collection, 0, len(fc.Features))
+		for i := range fc.Features {
+			col = append(col, fc.Features[i].Geometry.Geometry)
+		}
+		return col, nil
+
+	case "Feature":
+		var f geoJSONFeature
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, err
+		}
+		return f.Geometry.Geometry, nil
+
+	default:
+		var g geojson.Geometry
+		if err := json.Unmarshal(b, &g); err != nil {
+			return nil, err
+		}
+		return g.Geometry, nil
+	}
+}
+
+//	aoiPolygons returns the polygons of the geometry. null geometries are skipped
+func aoiPolygons(geo geom.Geometry) ([]geom.Polygon, error) {
+	switch g := geo.(type) {
+	case nil:
+		return nil, nil
+	case geom.Polygon:
+		return []geom.Polygon{g}, nil
+	case geom.MultiPolygon:
+		polygons := make([]geom.Polygon, 0, len(g))
+		for i := range g {
+			polygons = append(polygons, g[i])
+		}
+		return polygons, nil
+	case geom.Collection:
+		var polygons []geom.Polygon
+		for i := range g {
+			p, err := aoiPolygons(g[i])
+			if err != nil {
+				return nil, err
+			}
+			polygons = append(polygons, p...)
+		}
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry type (%T). expecting polygons or multipolygons", geo)
+	}
+}
+
+//	lonLatToGrid projects a lon / lat to the tile grid of zoom 0
+func lonLatToGrid(pt [2]float64) [2]float64 {
+	lat := math.Max(-maxLat, math.Min(maxLat, pt[1])) * math.Pi / 180.0
+
+	return [2]float64{
+		(pt[0] + 180.0) / 360.0,
+		(1.0 - math.Log(math.Tan(lat)+1.0/math.Cos(lat))/math.Pi) / 2.0,
+	}
+}
+
+func newAOI(polygons []geom.Polygon) *aoi {
+	a := aoi{
+		bounds:  [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)},
+		classes: map[aoiTile]aoiClass{},
+	}
+
+	for _, p := range polygons {
+		for _, ring := range p {
+			for i := range ring {
+				a.bounds[0] = math.Min(a.bounds[0], ring[i][0])
+				a.bounds[1] = math.Min(a.bounds[1], ring[i][1])
+				a.bounds[2] = math.Max(a.bounds[2], ring[i][0])
+				a.bounds[3] = math.Max(a.bounds[3], ring[i][1])
+
+				//	rings are closed whether or not the last point repeats the first
+				next := ring[(i+1)%len(ring)]
+				if next == ring[i] {
+					continue
+				}
+				a.edges = append(a.edges, [2][2]float64{lonLatToGrid(ring[i]), lonLatToGrid(next)})
+			}
+		}
+	}
+
+	//	keep the bounds within the tile grid
+	a.bounds[0] = math.Max(a.bounds[0], -180.0)
+	a.bounds[1] = math.Max(a.bounds[1], -maxLat)
+	a.bounds[2] = math.Min(a.bounds[2], 180.0)
+	a.bounds[3] = math.Min(a.bounds[3], maxLat)
+
+	return &a
+}
+
+//	Intersects reports if the tile intersects the area of interest
+func (a *aoi) Intersects(z, x, y int) bool {
+	return a.classify(z, x, y).class != tileOutside
+}
+
+//	classify classifies the tile using the classification of its parent: the descendants of tiles
+//	inside or outside of the area share the class, so only the children of partial tiles are tested,
+//	and only against the edges crossing their parent
+func (a *aoi) classify(z, x, y int) aoiClass {
+	key := aoiTile{z, x, y}
+	if c, ok := a.classes[key]; ok {
+		return c
+	}
+
+	var edges []int
+	if z > 0 {
+		parent := a.classify(z-1, x>>1, y>>1)
+		if parent.class != tilePartial {
+			return aoiClass{class: parent.class}
+		}
+		edges = parent.edges
+	} else {
+		edges = make([]int, len(a.edges))
+		for i := range edges {
+			edges[i] = i
+		}
+	}
+
+	c := a.test(z, x, y, edges)
+	a.classes[key] = c
+
+	return c
+}
+
+//	test classifies the tile by the edges which can cross it
+func (a *aoi) test(z, x, y int, edges []int) aoiClass {
+	tiles := 1 << uint(z)
+	if x < 0 || y < 0 || x >= tiles || y >= tiles {
+		return aoiClass{class: tileOutside}
+	}
+
+	size := 1.0 / float64(tiles)
+	topLeft := [2]float64{float64(x) * size, float64(y) * size}
+	bottomRight := [2]float64{topLeft[0] + size, topLeft[1] + size}
+
+	var crossing []int
+	for _, i := range edges {
+		if segmentIntersectsRect(a.edges[i][0], a.edges[i][1], topLeft, bottomRight) {
+			crossing = append(crossing, i)
+		}
+	}
+	if len(crossing) > 0 {
+		return aoiClass{class: tilePartial, edges: crossing}
+	}
+
+	//	the boundary does not cross the tile, so the tile is inside if any of its points are
+	if a.contains([2]float64{topLeft[0] + size/2, topLeft[1] + size/2}) {
+		return aoiClass{class: tileInside}
+	}
+
+	return aoiClass{class: tileOutside}
+}
+
+//	contains reports if the point is within the polygons using the even-odd rule, which accounts for holes
+func (a *aoi) contains(pt [2]float64) bool {
+	var in bool
+	for _, e := range a.edges {
+		p1, p2 := e[0], e[1]
+		if (p1[1] > pt[1]) == (p2[1] > pt[1]) {
+			continue
+		}
+		if pt[0] < (p2[0]-p1[0])*(pt[1]-p1[1])/(p2[1]-p1[1])+p1[0] {
+			in = !in
+		}
+	}
+
+	return in
+}
+
+//	segmentIntersectsRect reports if the segment from p1 to p2 crosses or is within the rectangle
+//	using Liang–Barsky clipping
+func segmentIntersectsRect(p1, p2, rectMin, rectMax [2]float64) bool {
+	t0, t1 := 0.0, 1.0
+
+	for i := 0; i < 2; i++ {
+		d := p2[i] - p1[i]
+		for _, pq := range [2][2]float64{{-d, p1[i] - rectMin[i]}, {d, rectMax[i] - p1[i]}} {
+			p, q := pq[0], pq[1]
+			if p == 0 {
+				//	parallel to the side and outside of it
+				if q < 0 {
+					return false
+				}
+				continue
+			}
+
+			r := q / p
+			if p < 0 {
+				if r > t1 {
+					return false
+				}
+				if r > t0 {
+					t0 = r
+				}
+			} else {
+				if r < t0 {
+					return false
+				}
+				if r < t1 {
+					t1 = r
+				}
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spatial/tegola/geom"
+)
+
+// square returns the closed ring of the lon / lat rectangle
+func square(minx, miny, maxx, maxy float64) [][2]float64 {
+	return [][2]float64{{minx, miny}, {maxx, miny}, {maxx, maxy}, {minx, maxy}, {minx, miny}}
+}
+
+func TestAOIClassify(t *testing.T) {
+	//	tiles at zoom 3 are 45° wide. rows 2 and 3 span the latitudes 66.51 to 40.98 and 40.98 to 0
+	polygon := geom.Polygon{
+		square(-100, -70, 100, 70),
+		//	hole
+		square(-40, -35, 40, 35),
+	}
+	multiPolygon := []geom.Polygon{
+		{square(-170, 10, -100, 60)},
+		{square(100, -60, 170, -10)},
+	}
+
+	type tcase struct {
+		polygons []geom.Polygon
+		z, x, y  int
+		expected tileClass
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		a := newAOI(tc.polygons)
+
+		if c := a.classify(tc.z, tc.x, tc.y); c.class != tc.expected {
+			t.Errorf("expected class %v got %v", tc.expected, c.class)
+		}
+		if a.Intersects(tc.z, tc.x, tc.y) != (tc.expected != tileOutside) {
+			t.Errorf("expected intersects %v", tc.expected != tileOutside)
+		}
+	}
+
+	tests := map[string]tcase{
+		"world": {
+			polygons: []geom.Polygon{polygon},
+			z:        0, x: 0, y: 0,
+			expected: tilePartial,
+		},
+		"inside": {
+			//	lon -90 to -45, lat 66.51 to 40.98
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 2, y: 2,
+			expected: tileInside,
+		},
+		"descendant of an inside tile": {
+			polygons: []geom.Polygon{polygon},
+			z:        6, x: 17, y: 18,
+			expected: tileInside,
+		},
+		"outside": {
+			//	lon -180 to -135, lat 85.05 to 79.17
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 0, y: 0,
+			expected: tileOutside,
+		},
+		"descendant of an outside tile": {
+			polygons: []geom.Polygon{polygon},
+			z:        7, x: 3, y: 5,
+			expected: tileOutside,
+		},
+		"straddling an edge": {
+			//	lon -135 to -90, crossed by the edge at lon -100
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 1, y: 2,
+			expected: tilePartial,
+		},
+		"straddling a corner": {
+			//	lon 90 to 135, lat -66.51 to -79.17, holds the corner at lon 100 lat -70
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 6, y: 6,
+			expected: tilePartial,
+		},
+		"straddling the hole": {
+			//	lon -45 to 0, lat 40.98 to 0, crossed by the hole's edges at lon -40 and lat 35
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 3, y: 3,
+			expected: tilePartial,
+		},
+		"inside the hole": {
+			//	lon -22.5 to 0, lat 21.94 to 0
+			polygons: []geom.Polygon{polygon},
+			z:        4, x: 7, y: 7,
+			expected: tileOutside,
+		},
+		"beyond the grid": {
+			polygons: []geom.Polygon{polygon},
+			z:        3, x: 8, y: 0,
+			expected: tileOutside,
+		},
+		"multipolygon world": {
+			polygons: multiPolygon,
+			z:        0, x: 0, y: 0,
+			expected: tilePartial,
+		},
+		"inside the first polygon": {
+			//	lon -135 to -112.5, lat 55.78 to 40.98
+			polygons: multiPolygon,
+			z:        4, x: 2, y: 5,
+			expected: tileInside,
+		},
+		"inside the second polygon": {
+			//	lon 112.5 to 135, lat -40.98 to -55.78
+			polygons: multiPolygon,
+			z:        4, x: 13, y: 10,
+			expected: tileInside,
+		},
+		"between the polygons": {
+			//	lon -22.5 to 0, lat 21.94 to 0
+			polygons: multiPolygon,
+			z:        4, x: 7, y: 7,
+			expected: tileOutside,
+		},
+		"straddling the second polygon": {
+			//	lon 90 to 135, lat -40.98 to -66.51, crossed by the edges at lon 100 and lat -60
+			polygons: multiPolygon,
+			z:        3, x: 6, y: 5,
+			expected: tilePartial,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestReadAOI(t *testing.T) {
+	type tcase struct {
+		file   string
+		bounds [4]float64
+		edges  int
+		hasErr bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		dir, err := ioutil.TempDir("", "aoi")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "aoi")
+		if err := ioutil.WriteFile(path, []byte(tc.file), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		a, err := readAOI(path)
+		if tc.hasErr {
+			if err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if a.bounds != tc.bounds {
+			t.Errorf("bounds, expected %v got %v", tc.bounds, a.bounds)
+		}
+		if len(a.edges) != tc.edges {
+			t.Errorf("edges, expected %v got %v", tc.edges, len(a.edges))
+		}
+	}
+
+	tests := map[string]tcase{
+		"geojson polygon": {
+			file:   `{"type": "Polygon", "coordinates": [[[-10, -20], [10, -20], [10, 20], [-10, 20], [-10, -20]]]}`,
+			bounds: [4]float64{-10, -20, 10, 20},
+			edges:  4,
+		},
+		"geojson polygon with a hole": {
+			file:   `{"type": "Polygon", "coordinates": [[[-10, -20], [10, -20], [10, 20], [-10, 20], [-10, -20]], [[-1, -1], [1, -1], [1, 1], [-1, 1], [-1, -1]]]}`,
+			bounds: [4]float64{-10, -20, 10, 20},
+			edges:  8,
+		},
+		"geojson feature": {
+			file:   `{"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [5, 0], [5, 5], [0, 0]]]}}`,
+			bounds: [4]float64{0, 0, 5, 5},
+			edges:  3,
+		},
+		"geojson feature collection": {
+			file: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [5, 0], [5, 5], [0, 0]]]}},
+				{"type": "Feature", "properties": {}, "geometry": null},
+				{"type": "Feature", "properties": {}, "geometry": {"type": "MultiPolygon", "coordinates": [[[[-20, -10], [-15, -10], [-15, -5], [-20, -10]]], [[[30, 30], [35, 30], [35, 35], [30, 30]]]]}}
+			]}`,
+			bounds: [4]float64{-20, -10, 35, 35},
+			edges:  9,
+		},
+		"geojson features with string and negative ids": {
+			file: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "id": "CHL", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [5, 0], [5, 5], [0, 0]]]}},
+				{"type": "Feature", "id": -1, "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[-20, -10], [-15, -10], [-15, -5], [-20, -10]]]}}
+			]}`,
+			bounds: [4]float64{-20, -10, 5, 5},
+			edges:  6,
+		},
+		"geojson feature with a string id": {
+			file:   `{"type": "Feature", "id": "CHL", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [5, 0], [5, 5], [0, 0]]]}}`,
+			bounds: [4]float64{0, 0, 5, 5},
+			edges:  3,
+		},
+		"latitudes beyond the grid": {
+			file:   `{"type": "Polygon", "coordinates": [[[-180, -90], [180, -90], [180, 90], [-180, 90], [-180, -90]]]}`,
+			bounds: [4]float64{-180, -maxLat, 180, maxLat},
+			edges:  4,
+		},
+		"wkt polygon": {
+			file:   "POLYGON ((-10 -20, 10 -20, 10 20, -10 20, -10 -20))\n",
+			bounds: [4]float64{-10, -20, 10, 20},
+			edges:  4,
+		},
+		"wkt polygon without a closing point": {
+			file:   "POLYGON ((-10 -20, 10 -20, 10 20, -10 20))",
+			bounds: [4]float64{-10, -20, 10, 20},
+			edges:  4,
+		},
+		"wkt multipolygon": {
+			file:   "MULTIPOLYGON (((30 20, 45 40, 10 40, 30 20)), ((15 5, 40 10, 10 20, 5 10, 15 5)))",
+			bounds: [4]float64{5, 5, 45, 40},
+			edges:  7,
+		},
+		"geojson point": {
+			file:   `{"type": "Point", "coordinates": [0, 0]}`,
+			hasErr: true,
+		},
+		"wkt linestring": {
+			file:   "LINESTRING (30 10, 10 30, 40 40)",
+			hasErr: true,
+		},
+		"no polygons": {
+			file:   `{"type": "FeatureCollection", "features": []}`,
+			hasErr: true,
+		},
+		"invalid geojson": {
+			file:   `{"type": "Polygon", "coordinates": [[[0, 0]`,
+			hasErr: true,
+		},
+		"invalid wkt": {
+			file:   "POLYGON ((0 0, 1",
+			hasErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//	the first line of a checkpoint file, followed by the signature of the seed
+const checkpointHeader = "# tegola cache seed checkpoint: "
+
+type column struct {
+	z, x int
+}
+
+//	columnState tracks the tiles of a column being seeded
+type columnState struct {
+	//	tiles sent to the workers which have not completed
+	pending int
+	//	all of the column's tiles have been sent to the workers
+	dispatched bool
+	//	a tile of the column failed, so the column is not recorded as completed
+	failed bool
+}
+
+//	seedCheckpoint records the columns (z/x) of a seed which have completed, so an interrupted seed
+//	can skip them when it is resumed. a column is completed once every tile of the column, for every
+//	map of the seed, has been seeded.
+//
+//	completed columns are appended to the file as "z/x" lines as they complete. when a checkpoint
+//	is opened, the file is rewritten with the columns merged into "z/minx-maxx" ranges
+type seedCheckpoint struct {
+	path string
+
+	sync.Mutex
+	file *os.File
+	//	the completed columns
+	completed map[column]bool
+	//	the columns being seeded
+	columns map[column]*columnState
+}
+
+//	openCheckpoint opens the checkpoint file at path, creating it if it does not exist. the signature
+//	identifies the seed (maps, zooms, bounds...): a checkpoint written by a different seed is an error
+//	as its columns would not cover the tiles of this seed
+func openCheckpoint(path, signature string) (*seedCheckpoint, error) {
+	cp := seedCheckpoint{
+		path:      path,
+		completed: map[column]bool{},
+		columns:   map[column]*columnState{},
+	}
+
+	if err := cp.read(signature); err != nil {
+		return nil, err
+	}
+
+	//	rewrite the file with the columns merged into ranges
+	tmp := path + ".tmp"
+	if err := cp.write(tmp, signature); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	cp.file = f
+
+	return &cp, nil
+}
+
+func (cp *seedCheckpoint) read(signature string) error {
+	f, err := os.Open(cp.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		str := strings.TrimSpace(scanner.Text())
+
+		if line == 1 {
+			if str != checkpointHeader+signature {
+				return fmt.Errorf("the checkpoint was written by a different seed (%v). remove it to start over", strings.TrimPrefix(str, checkpointHeader))
+			}
+			continue
+		}
+		if str == "" {
+			continue
+		}
+
+		//	z/x or z/minx-maxx
+		parts := strings.Split(str, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("checkpoint line %v: invalid value (%v). expecting the format z/x or z/minx-maxx", line, str)
+		}
+		xs := strings.Split(parts[1], "-")
+
+		z, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return fmt.Errorf("checkpoint line %v: invalid Z value (%v)", line, parts[0])
+		}
+		minx, err := strconv.Atoi(xs[0])
+		if err != nil {
+			return fmt.Errorf("checkpoint line %v: invalid X value (%v)", line, xs[0])
+		}
+		maxx := minx
+		if len(xs) > 1 {
+			if maxx, err = strconv.Atoi(xs[1]); err != nil {
+				return fmt.Errorf("checkpoint line %v: invalid X value (%v)", line, xs[1])
+			}
+		}
+
+		for x := minx; x <= maxx; x++ {
+			cp.completed[column{z, x}] = true
+		}
+	}
+
+	return scanner.Err()
+}
+
+//	write writes the header and the completed columns, merged into ranges, to path
+func (cp *seedCheckpoint) write(path, signature string) error {
+	columns := make([]column, 0, len(cp.completed))
+	for c := range cp.completed {
+		columns = append(columns, c)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i].z != columns[j].z {
+			return columns[i].z < columns[j].z
+		}
+		return columns[i].x < columns[j].x
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, checkpointHeader+signature)
+
+	for i := 0; i < len(columns); {
+		//	extend the range while the columns are consecutive
+		j := i
+		for j+1 < len(columns) && columns[j+1].z == columns[i].z && columns[j+1].x == columns[j].x+1 {
+			j++
+		}
+		fmt.Fprintf(w, "%v/%v-%v\n", columns[i].z, columns[i].x, columns[j].x)
+		i = j + 1
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+//	Completed reports if the column was completed by a previous run
+func (cp *seedCheckpoint) Completed(z, x int) bool {
+	cp.Lock()
+	defer cp.Unlock()
+
+	return cp.completed[column{z, x}]
+}
+
+//	Add records a tile of the column has been sent to the workers
+func (cp *seedCheckpoint) Add(z, x int) {
+	cp.Lock()
+	defer cp.Unlock()
+
+	c := column{z, x}
+	if cp.columns[c] == nil {
+		cp.columns[c] = &columnState{}
+	}
+	cp.columns[c].pending++
+}
+
+//	Dispatched records every tile of the column has been sent to the workers
+func (cp *seedCheckpoint) Dispatched(z, x int) error {
+	cp.Lock()
+	defer cp.Unlock()
+
+	c := column{z, x}
+	state, ok := cp.columns[c]
+	if !ok {
+		return nil
+	}
+	state.dispatched = true
+
+	return cp.complete(c, state)
+}
+
+//	Done records a tile of the column has been processed. failed tiles keep the column from being completed
+func (cp *seedCheckpoint) Done(z, x int, failed bool) error {
+	cp.Lock()
+	defer cp.Unlock()
+
+	c := column{z, x}
+	state, ok := cp.columns[c]
+	if !ok {
+		return nil
+	}
+	state.pending--
+	state.failed = state.failed || failed
+
+	return cp.complete(c, state)
+}
+
+//	complete appends the column to the file once all of its tiles have been processed
+func (cp *seedCheckpoint) complete(c column, state *columnState) error {
+	if !state.dispatched || state.pending > 0 {
+		return nil
+	}
+	delete(cp.columns, c)
+
+	if state.failed {
+		return nil
+	}
+	cp.completed[c] = true
+
+	_, err := fmt.Fprintf(cp.file, "%v/%v\n", c.z, c.x)
+	return err
+}
+
+func (cp *seedCheckpoint) Close() error {
+	cp.Lock()
+	defer cp.Unlock()
+
+	return cp.file.Close()
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenCheckpoint(t *testing.T) {
+	const signature = "maps=osm zoom=0-2"
+
+	type tcase struct {
+		//	the contents of the checkpoint file. no file is written when empty
+		file      string
+		signature string
+		completed map[column]bool
+		//	the contents of the file once opened
+		expected string
+		hasErr   bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		dir, err := ioutil.TempDir("", "checkpoint")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "seed.checkpoint")
+		if tc.file != "" {
+			if err := ioutil.WriteFile(path, []byte(tc.file), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		cp, err := openCheckpoint(path, tc.signature)
+		if tc.hasErr {
+			if err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			//	the file is left as is
+			if b, _ := ioutil.ReadFile(path); string(b) != tc.file {
+				t.Errorf("expected the file to be unchanged, got %q", b)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer cp.Close()
+
+		if !reflect.DeepEqual(tc.completed, cp.completed) {
+			t.Errorf("completed, expected %v got %v", tc.completed, cp.completed)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(b) != tc.expected {
+			t.Errorf("file, expected %q got %q", tc.expected, b)
+		}
+	}
+
+	tests := map[string]tcase{
+		"new": {
+			signature: signature,
+			completed: map[column]bool{},
+			expected:  checkpointHeader + signature + "\n",
+		},
+		"columns merged into ranges": {
+			file:      checkpointHeader + signature + "\n1/0\n2/3\n\n1/1\n2/0-1\n2/2\n",
+			signature: signature,
+			completed: map[column]bool{{1, 0}: true, {1, 1}: true, {2, 0}: true, {2, 1}: true, {2, 2}: true, {2, 3}: true},
+			expected:  checkpointHeader + signature + "\n1/0-1\n2/0-3\n",
+		},
+		"gaps": {
+			file:      checkpointHeader + signature + "\n2/3\n2/0\n2/1\n",
+			signature: signature,
+			completed: map[column]bool{{2, 0}: true, {2, 1}: true, {2, 3}: true},
+			expected:  checkpointHeader + signature + "\n2/0-1\n2/3-3\n",
+		},
+		"different signature": {
+			file:      checkpointHeader + signature + "\n1/0\n",
+			signature: "maps=osm zoom=0-3",
+			hasErr:    true,
+		},
+		"missing header": {
+			file:      "1/0\n",
+			signature: signature,
+			hasErr:    true,
+		},
+		"invalid line": {
+			file:      checkpointHeader + signature + "\n1/0/0\n",
+			signature: signature,
+			hasErr:    true,
+		},
+		"invalid z": {
+			file:      checkpointHeader + signature + "\na/0\n",
+			signature: signature,
+			hasErr:    true,
+		},
+		"invalid range": {
+			file:      checkpointHeader + signature + "\n1/0-b\n",
+			signature: signature,
+			hasErr:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestCheckpointColumns(t *testing.T) {
+	const signature = "maps=osm zoom=1-1"
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "seed.checkpoint")
+
+	cp, err := openCheckpoint(path, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	//	column 1/0 completes once its tiles are dispatched and done, whichever comes last
+	cp.Add(1, 0)
+	cp.Add(1, 0)
+	cp.Done(1, 0, false)
+	cp.Dispatched(1, 0)
+	if cp.Completed(1, 0) {
+		t.Errorf("expected column 1/0 not to be completed with a pending tile")
+	}
+	cp.Done(1, 0, false)
+	if !cp.Completed(1, 0) {
+		t.Errorf("expected column 1/0 to be completed")
+	}
+
+	//	column 1/1 has a failed tile
+	cp.Add(1, 1)
+	cp.Add(1, 1)
+	cp.Done(1, 1, true)
+	cp.Done(1, 1, false)
+	cp.Dispatched(1, 1)
+	if cp.Completed(1, 1) {
+		t.Errorf("expected column 1/1 not to be completed after a failed tile")
+	}
+
+	if err := cp.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	//	a resumed seed only skips the completed column
+	cp, err = openCheckpoint(path, signature)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cp.Close()
+
+	expected := map[column]bool{{1, 0}: true}
+	if !reflect.DeepEqual(expected, cp.completed) {
+		t.Errorf("completed, expected %v got %v", expected, cp.completed)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-spatial/tegola/cache/pmtiles"
+)
+
+//	partitionBlock is the number of consecutive tiles on the Hilbert curve dealt to a partition at a time.
+//	64 tiles on the curve are a square of 8x8 tiles
+const partitionBlock = 64
+
+//	partition is a deterministic share of the tiles of a job, so independent processes, each seeding a
+//	partition of the same job, seed every tile exactly once
+type partition struct {
+	//	the partition, from 1 to n
+	i, n int
+}
+
+//	parsePartition parses a partition in the i/n format, i.e. 2/4 for the second of four partitions
+func parsePartition(str string) (*partition, error) {
+	parts := strings.Split(str, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid partition (%v). expecting the format i/n", str)
+	}
+
+	i, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid partition index (%v)", parts[0])
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid partition count (%v)", parts[1])
+	}
+	if n < 1 || i < 1 || i > n {
+		return nil, fmt.Errorf("invalid partition (%v). the index must be from 1 to the partition count", str)
+	}
+
+	return &partition{i: i, n: n}, nil
+}
+
+//	Contains reports if the tile belongs to the partition. the tiles of a zoom are ordered along a Hilbert
+//	curve (the PMTiles tile ID) and dealt to the partitions in blocks of 8x8 tiles. neighbouring tiles are
+//	seeded by the same process while any area of interest is spread over every partition.
+//	zooms with fewer tiles than a block belong to the first partition
+func (p partition) Contains(z, x, y int) bool {
+	//	tiles outside of the grid (i.e. the x of 2^z when seeding up to a longitude of 180) belong to the first partition
+	if size := 1 << uint(z); x < 0 || y < 0 || x >= size || y >= size {
+		return p.i == 1
+	}
+
+	d := pmtiles.TileID(uint8(z), uint64(x), uint64(y)) - pmtiles.TileID(uint8(z), 0, 0)
+
+	return int(d/partitionBlock%uint64(p.n)) == p.i-1
+}
+
+func (p partition) String() string {
+	return fmt.Sprintf("%v/%v", p.i, p.n)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePartition(t *testing.T) {
+	type tcase struct {
+		str      string
+		expected partition
+		hasErr   bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		p, err := parsePartition(tc.str)
+		if tc.hasErr {
+			if err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected err: %v", err)
+			return
+		}
+
+		if *p != tc.expected {
+			t.Errorf("expected %v got %v", tc.expected, *p)
+		}
+	}
+
+	tests := map[string]tcase{
+		"first":          {str: "1/4", expected: partition{i: 1, n: 4}},
+		"last":           {str: "4/4", expected: partition{i: 4, n: 4}},
+		"single":         {str: "1/1", expected: partition{i: 1, n: 1}},
+		"zero index":     {str: "0/4", hasErr: true},
+		"index beyond n": {str: "5/4", hasErr: true},
+		"zero count":     {str: "1/0", hasErr: true},
+		"missing count":  {str: "1", hasErr: true},
+		"extra part":     {str: "1/2/3", hasErr: true},
+		"invalid index":  {str: "a/2", hasErr: true},
+		"invalid count":  {str: "1/b", hasErr: true},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestPartitionContains(t *testing.T) {
+	type tcase struct {
+		z, n int
+	}
+
+	//	every tile of the zoom, including the column beyond the grid seeded up to a longitude of 180,
+	//	belongs to exactly one of the n partitions
+	fn := func(t *testing.T, tc tcase) {
+		size := 1 << uint(tc.z)
+		counts := make([]int, tc.n)
+
+		for x := 0; x <= size; x++ {
+			for y := 0; y < size; y++ {
+				var owners []int
+				for i := 1; i <= tc.n; i++ {
+					if (partition{i: i, n: tc.n}).Contains(tc.z, x, y) {
+						owners = append(owners, i)
+					}
+				}
+				if len(owners) != 1 {
+					t.Fatalf("tile %v/%v/%v, expected a single partition got %v", tc.z, x, y, owners)
+				}
+				counts[owners[0]-1]++
+			}
+		}
+
+		//	partitions are dealt whole blocks, so they differ by at most a block (and the column beyond the grid)
+		for i := range counts {
+			if counts[i] < counts[0]-partitionBlock-size || counts[i] > counts[0]+partitionBlock+size {
+				t.Errorf("partition %v/%v, unbalanced tile counts %v", i+1, tc.n, counts)
+				break
+			}
+		}
+	}
+
+	tests := []tcase{
+		{z: 0, n: 1},
+		{z: 0, n: 3},
+		{z: 3, n: 2},
+		{z: 5, n: 4},
+		{z: 6, n: 3},
+		{z: 8, n: 7},
+		{z: 9, n: 16},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("z%v n%v", tc.z, tc.n), func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
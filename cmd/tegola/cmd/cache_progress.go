@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-spatial/tegola/internal/log"
+)
+
+//	failedTile is a tile which errored while being seeded or purged
+type failedTile struct {
+	MapTile
+	err error
+}
+
+//	seedProgress tracks the tiles processed by the cache workers and reports the progress of the job
+type seedProgress struct {
+	//	the action being reported, seeded or purged
+	action string
+	//	the number of tiles of the job. 0 when unknown
+	total int
+	start time.Time
+
+	sync.Mutex
+	//	tiles processed by this run
+	done int
+	//	tiles already in the cache which were not overwritten
+	skipped int
+	//	tiles completed by a previous run, read from the checkpoint
+	resumed  int
+	failures []failedTile
+}
+
+func newSeedProgress(action string, total int) *seedProgress {
+	return &seedProgress{
+		action: action,
+		total:  total,
+		start:  time.Now(),
+	}
+}
+
+func (p *seedProgress) Done() {
+	p.Lock()
+	p.done++
+	p.Unlock()
+}
+
+func (p *seedProgress) Skipped() {
+	p.Lock()
+	p.skipped++
+	p.Unlock()
+}
+
+func (p *seedProgress) Resumed(n int) {
+	p.Lock()
+	p.resumed += n
+	p.Unlock()
+}
+
+func (p *seedProgress) Failed(mt MapTile, err error) {
+	p.Lock()
+	p.failures = append(p.failures, failedTile{MapTile: mt, err: err})
+	p.Unlock()
+}
+
+//	Report logs the tiles processed, the rate of this run and the estimated time remaining
+func (p *seedProgress) Report() {
+	p.Lock()
+	processed := p.done + p.skipped + len(p.failures)
+	completed := processed + p.resumed
+	failed := len(p.failures)
+	p.Unlock()
+
+	elapsed := time.Since(p.start)
+	rate := float64(processed) / elapsed.Seconds()
+
+	if p.total == 0 {
+		log.Infof("progress: %v %v tiles, %v failed, %.1f tiles/s", completed, p.action, failed, rate)
+		return
+	}
+
+	eta := "unknown"
+	if rate > 0 {
+		remaining := float64(p.total-completed) / rate
+		eta = (time.Duration(remaining) * time.Second).String()
+	}
+
+	log.Infof("progress: %v/%v tiles %v (%.1f%%), %v failed, %.1f tiles/s, eta %v",
+		completed, p.total, p.action, float64(completed)/float64(p.total)*100, failed, rate, eta)
+}
+
+//	Start reports the progress at every interval until the returned func is called
+func (p *seedProgress) Start(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.Report()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+//	Summary logs the totals of the job and the failed tiles, in the z/x/y format used by --tile-list
+func (p *seedProgress) Summary() {
+	p.Lock()
+	defer p.Unlock()
+
+	log.Infof("%v %v tiles in %v. %v existing tiles skipped, %v tiles resumed from the checkpoint, %v tiles failed",
+		p.action, p.done, time.Since(p.start), p.skipped, p.resumed, len(p.failures))
+
+	for _, f := range p.failures {
+		log.Errorf("failed map (%v) tile %v/%v/%v: %v", f.MapName, f.Tile.Z, f.Tile.X, f.Tile.Y, f.err)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/internal/log"
+)
+
+func TestSeedProgress(t *testing.T) {
+	type tcase struct {
+		total   int
+		done    int
+		skipped int
+		resumed int
+		failed  int
+		//	a part of the progress report
+		expected string
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		p := newSeedProgress("seeded", tc.total)
+
+		//	the workers report concurrently
+		var wg sync.WaitGroup
+		report := func(n int, fn func()) {
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					fn()
+				}()
+			}
+		}
+		report(tc.done, p.Done)
+		report(tc.skipped, p.Skipped)
+		report(tc.failed, func() {
+			p.Failed(MapTile{MapName: "osm", Tile: tegola.NewTile(1, 0, 0)}, errors.New("failed"))
+		})
+		p.Resumed(tc.resumed)
+		wg.Wait()
+
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		p.Report()
+		log.SetOutput(os.Stderr)
+
+		if !strings.Contains(buf.String(), tc.expected) {
+			t.Errorf("expected the report to contain %q got %q", tc.expected, buf.String())
+		}
+
+		p.Lock()
+		defer p.Unlock()
+
+		if p.done != tc.done || p.skipped != tc.skipped || p.resumed != tc.resumed || len(p.failures) != tc.failed {
+			t.Errorf("expected done %v skipped %v resumed %v failed %v got %v %v %v %v",
+				tc.done, tc.skipped, tc.resumed, tc.failed, p.done, p.skipped, p.resumed, len(p.failures))
+		}
+	}
+
+	tests := map[string]tcase{
+		"nothing processed": {
+			total:    10,
+			expected: "progress: 0/10 tiles seeded (0.0%), 0 failed, 0.0 tiles/s, eta unknown",
+		},
+		"processed": {
+			total:    10,
+			done:     4,
+			skipped:  2,
+			failed:   1,
+			expected: "progress: 7/10 tiles seeded (70.0%), 1 failed,",
+		},
+		"resumed": {
+			total:    10,
+			done:     3,
+			resumed:  5,
+			expected: "progress: 8/10 tiles seeded (80.0%), 0 failed,",
+		},
+		"unknown total": {
+			done:     3,
+			skipped:  1,
+			expected: "progress: 4 seeded tiles, 0 failed,",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestSeedProgressStart(t *testing.T) {
+	p := newSeedProgress("seeded", 10)
+
+	//	no interval, no reports
+	p.Start(0)()
+
+	stop := p.Start(time.Millisecond)
+	p.Done()
+	time.Sleep(5 * time.Millisecond)
+	stop()
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/atlas"
+)
+
+//	readTileList reads the tiles of a tile list file. a path of "-" reads the list from stdin
+func readTileList(path string) ([]*tegola.Tile, error) {
+	if path == "-" {
+		return parseTileList(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseTileList(f)
+}
+
+//	parseTileList parses a tile per line in the z/x/y format, such as the expire lists of osm2pgsql and imposm.
+//	empty lines and lines starting with # are skipped
+func parseTileList(r io.Reader) ([]*tegola.Tile, error) {
+	var tiles []*tegola.Tile
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		str := strings.TrimSpace(scanner.Text())
+		if str == "" || strings.HasPrefix(str, "#") {
+			continue
+		}
+
+		t, err := parseTileString(str)
+		if err != nil {
+			return nil, fmt.Errorf("tile list line %v: %v", line, err)
+		}
+
+		max := 1 << uint(t.Z)
+		if t.Z > atlas.MaxZoom || t.X < 0 || t.Y < 0 || t.X >= max || t.Y >= max {
+			return nil, fmt.Errorf("tile list line %v: tile (%v) is outside of the tile grid", line, str)
+		}
+
+		tiles = append(tiles, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return tiles, nil
+}
+
+//	tileList is the set of tiles of a tile list expanded to a zoom range: each listed tile, its ancestors from
+//	the min zoom and its descendants to the max zoom. the expanded tiles are not held in memory, the tiles of a
+//	zoom are iterated from the rectangles of the listed tiles' descendants at the zoom.
+type tileList struct {
+	minZoom int
+	//	less than 0 expands each tile to its own zoom, with no descendants
+	maxZoom int
+	//	the last zoom with tiles
+	lastZoom int
+
+	//	the listed tiles, deduplicated
+	listed map[tileKey]bool
+	tiles  []tileKey
+}
+
+type tileKey struct {
+	z, x, y int
+}
+
+//	tileSpan is a rectangle of tiles of a zoom
+type tileSpan struct {
+	minx, miny, maxx, maxy int
+}
+
+//	newTileList expands the tiles to the zoom range from minZoom to maxZoom. listed tiles beyond
+//	the max zoom only add their ancestors within the zoom range
+func newTileList(tiles []*tegola.Tile, minZoom, maxZoom int) *tileList {
+	tl := tileList{
+		minZoom:  minZoom,
+		maxZoom:  maxZoom,
+		lastZoom: maxZoom,
+		listed:   map[tileKey]bool{},
+	}
+
+	for _, t := range tiles {
+		k := tileKey{t.Z, t.X, t.Y}
+		if tl.listed[k] {
+			continue
+		}
+		tl.listed[k] = true
+		tl.tiles = append(tl.tiles, k)
+
+		if maxZoom < 0 && t.Z > tl.lastZoom {
+			tl.lastZoom = t.Z
+		}
+	}
+
+	return &tl
+}
+
+//	covers reports if a listed tile at zoom z or lower expands to the tile at zoom z. before zoom z
+//	only listed tiles expanded to their descendants are considered
+func (tl *tileList) covers(z, x, y int) bool {
+	start := z
+	if tl.maxZoom >= 0 {
+		start = 0
+	}
+	for zz := start; zz <= z; zz++ {
+		shift := uint(z - zz)
+		if tl.listed[tileKey{zz, x >> shift, y >> shift}] {
+			return true
+		}
+	}
+	return false
+}
+
+//	spans returns the disjoint rectangles of the expanded tiles at zoom z, ordered by column
+func (tl *tileList) spans(z int) []tileSpan {
+	var spans []tileSpan
+	ancestors := map[tileKey]bool{}
+
+	for _, t := range tl.tiles {
+		switch {
+		//	an ancestor of the listed tile
+		case t.z > z:
+			shift := uint(t.z - z)
+			k := tileKey{z, t.x >> shift, t.y >> shift}
+			if ancestors[k] || tl.covers(z, k.x, k.y) {
+				continue
+			}
+			ancestors[k] = true
+			spans = append(spans, tileSpan{k.x, k.y, k.x, k.y})
+
+		//	the listed tile or its descendants
+		case t.z == z || tl.maxZoom >= 0:
+			//	the descendants of a listed ancestor cover the tile's descendants
+			if t.z > 0 && tl.maxZoom >= 0 && tl.covers(t.z-1, t.x>>1, t.y>>1) {
+				continue
+			}
+			shift := uint(z - t.z)
+			spans = append(spans, tileSpan{
+				minx: t.x << shift,
+				miny: t.y << shift,
+				maxx: (t.x+1)<<shift - 1,
+				maxy: (t.y+1)<<shift - 1,
+			})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].minx != spans[j].minx {
+			return spans[i].minx < spans[j].minx
+		}
+		return spans[i].miny < spans[j].miny
+	})
+
+	return spans
+}
+
+//	Bounds returns the lon / lat bounds of the listed tiles: minx, miny, maxx, maxy. the expanded
+//	tiles are within the listed tiles, but for their ancestors
+func (tl *tileList) Bounds() [4]float64 {
+	if len(tl.tiles) == 0 {
+		return [4]float64{-180, -maxLat, 180, maxLat}
+	}
+
+	bounds := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, t := range tl.tiles {
+		//	the top left corners of the tile and of the tile to its bottom right
+		maxy, minx := tegola.NewTile(t.z, t.x, t.y).Num2Deg()
+		miny, maxx := tegola.NewTile(t.z, t.x+1, t.y+1).Num2Deg()
+
+		bounds[0] = math.Min(bounds[0], minx)
+		bounds[1] = math.Min(bounds[1], miny)
+		bounds[2] = math.Max(bounds[2], maxx)
+		bounds[3] = math.Max(bounds[3], maxy)
+	}
+
+	return bounds
+}
+
+//	Len returns the number of tiles of the expanded list
+func (tl *tileList) Len() (n int) {
+	for z := tl.minZoom; z <= tl.lastZoom; z++ {
+		for _, s := range tl.spans(z) {
+			n += (s.maxx - s.minx + 1) * (s.maxy - s.miny + 1)
+		}
+	}
+	return n
+}
+
+//	ForEach calls fn for each tile of the expanded list, ordered by zoom, then column and row,
+//	until fn returns false
+func (tl *tileList) ForEach(fn func(z, x, y int) bool) {
+	for z := tl.minZoom; z <= tl.lastZoom; z++ {
+		spans := tl.spans(z)
+
+		//	sweep the columns of the spans. active are the spans of the column ordered by row
+		var active []tileSpan
+		next := 0
+		for x := 0; next < len(spans) || len(active) > 0; x++ {
+			if len(active) == 0 {
+				x = spans[next].minx
+			}
+			if next < len(spans) && spans[next].minx == x {
+				for next < len(spans) && spans[next].minx == x {
+					active = append(active, spans[next])
+					next++
+				}
+				sort.Slice(active, func(i, j int) bool { return active[i].miny < active[j].miny })
+			}
+
+			for _, s := range active {
+				for y := s.miny; y <= s.maxy; y++ {
+					if !fn(z, x, y) {
+						return
+					}
+				}
+			}
+
+			//	drop the spans ending at the column
+			remaining := active[:0]
+			for _, s := range active {
+				if s.maxx > x {
+					remaining = append(remaining, s)
+				}
+			}
+			active = remaining
+		}
+	}
+}
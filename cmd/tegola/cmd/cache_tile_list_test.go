@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+)
+
+func TestParseTileList(t *testing.T) {
+	type tcase struct {
+		list     string
+		expected [][3]int
+		hasErr   bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		tiles, err := parseTileList(strings.NewReader(tc.list))
+		if tc.hasErr {
+			if err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected err: %v", err)
+			return
+		}
+
+		var output [][3]int
+		for _, tile := range tiles {
+			output = append(output, [3]int{tile.Z, tile.X, tile.Y})
+		}
+
+		if !reflect.DeepEqual(tc.expected, output) {
+			t.Errorf("expected %v got %v", tc.expected, output)
+		}
+	}
+
+	tests := map[string]tcase{
+		"tiles": {
+			list:     "1/0/1\n2/3/3\n",
+			expected: [][3]int{{1, 0, 1}, {2, 3, 3}},
+		},
+		"comments and blank lines": {
+			list:     "# expired tiles\n\n  1/0/1  \n\n# end\n",
+			expected: [][3]int{{1, 0, 1}},
+		},
+		"no trailing newline": {
+			list:     "0/0/0",
+			expected: [][3]int{{0, 0, 0}},
+		},
+		"empty": {
+			list: "",
+		},
+		"missing y": {
+			list:   "1/0/1\n1/0\n",
+			hasErr: true,
+		},
+		"not a number": {
+			list:   "1/a/1\n",
+			hasErr: true,
+		},
+		"negative zoom": {
+			list:   "-1/0/0\n",
+			hasErr: true,
+		},
+		"outside of the grid": {
+			list:   "1/2/0\n",
+			hasErr: true,
+		},
+		"negative column": {
+			list:   "1/-1/0\n",
+			hasErr: true,
+		},
+		"beyond the max zoom": {
+			list:   "30/0/0\n",
+			hasErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) { fn(t, tc) })
+	}
+}
+
+func TestTileList(t *testing.T) {
+	type tcase struct {
+		tiles    [][3]int
+		minZoom  int
+		maxZoom  int
+		expected [][3]int
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		var tiles []*tegola.Tile
+		for _, tile := range tc.tiles {
+			tiles = append(tiles, tegola.NewTile(tile[0], tile[1], tile[2]))
+		}
+
+		tl := newTileList(tiles, tc.minZoom, tc.maxZoom)
+
+		var output [][3]int
+		tl.ForEach(func(z, x, y int) bool {
+			output = append(output, [3]int{z, x, y})
+			return true
+		})
+
+		if !reflect.DeepEqual(tc.expected, output) {
+			t.Errorf("expected %v got %v", tc.expected, output)
+		}
+		if tl.Len() != len(tc.expected) {
+			t.Errorf("expected len %v got %v", len(tc.expected), tl.Len())
+		}
+	}
+
+	tests := map[string]tcase{
+		"own zoom": {
+			tiles:    [][3]int{{2, 1, 1}},
+			minZoom:  2,
+			maxZoom:  -1,
+			expected: [][3]int{{2, 1, 1}},
+		},
+		"ancestors": {
+			tiles:    [][3]int{{3, 5, 2}},
+			minZoom:  0,
+			maxZoom:  -1,
+			expected: [][3]int{{0, 0, 0}, {1, 1, 0}, {2, 2, 1}, {3, 5, 2}},
+		},
+		"ancestors from the min zoom": {
+			tiles:    [][3]int{{3, 5, 2}},
+			minZoom:  2,
+			maxZoom:  -1,
+			expected: [][3]int{{2, 2, 1}, {3, 5, 2}},
+		},
+		"descendants": {
+			tiles:    [][3]int{{1, 1, 0}},
+			minZoom:  1,
+			maxZoom:  2,
+			expected: [][3]int{{1, 1, 0}, {2, 2, 0}, {2, 2, 1}, {2, 3, 0}, {2, 3, 1}},
+		},
+		"tile beyond the max zoom": {
+			tiles:    [][3]int{{4, 10, 4}},
+			minZoom:  1,
+			maxZoom:  2,
+			expected: [][3]int{{1, 1, 0}, {2, 2, 1}},
+		},
+		"tile below the min zoom": {
+			tiles:    [][3]int{{0, 0, 0}},
+			minZoom:  1,
+			maxZoom:  1,
+			expected: [][3]int{{1, 0, 0}, {1, 0, 1}, {1, 1, 0}, {1, 1, 1}},
+		},
+		"shared ancestors": {
+			tiles:    [][3]int{{2, 0, 0}, {2, 1, 1}, {2, 0, 0}},
+			minZoom:  0,
+			maxZoom:  -1,
+			expected: [][3]int{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {2, 1, 1}},
+		},
+		"nested tiles": {
+			tiles:    [][3]int{{2, 3, 1}, {1, 1, 0}, {2, 0, 3}},
+			minZoom:  1,
+			maxZoom:  2,
+			expected: [][3]int{{1, 0, 1}, {1, 1, 0}, {2, 0, 3}, {2, 2, 0}, {2, 2, 1}, {2, 3, 0}, {2, 3, 1}},
+		},
+		"nested tiles at their own zoom": {
+			tiles:    [][3]int{{2, 3, 1}, {1, 1, 0}},
+			minZoom:  1,
+			maxZoom:  -1,
+			expected: [][3]int{{1, 1, 0}, {2, 3, 1}},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) { fn(t, tc) })
+	}
+}
+
+func TestTileListForEachStop(t *testing.T) {
+	tl := newTileList([]*tegola.Tile{tegola.NewTile(0, 0, 0)}, 0, 10)
+
+	var n int
+	tl.ForEach(func(z, x, y int) bool {
+		n++
+		return n < 3
+	})
+
+	if n != 3 {
+		t.Errorf("expected 3 calls got %v", n)
+	}
+}
+
+func TestTileListBounds(t *testing.T) {
+	type tcase struct {
+		tiles    []*tegola.Tile
+		expected [4]float64
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		output := newTileList(tc.tiles, 0, -1).Bounds()
+
+		for i := range output {
+			if math.Abs(output[i]-tc.expected[i]) > 1e-4 {
+				t.Errorf("expected %v got %v", tc.expected, output)
+				return
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"world": {
+			tiles:    []*tegola.Tile{tegola.NewTile(0, 0, 0)},
+			expected: [4]float64{-180, -maxLat, 180, maxLat},
+		},
+		"single tile": {
+			tiles:    []*tegola.Tile{tegola.NewTile(1, 1, 0)},
+			expected: [4]float64{0, 0, 180, maxLat},
+		},
+		"tiles of different zooms": {
+			tiles:    []*tegola.Tile{tegola.NewTile(2, 0, 1), tegola.NewTile(1, 1, 1)},
+			expected: [4]float64{-180, -maxLat, 180, 66.5133},
+		},
+		"no tiles": {
+			expected: [4]float64{-180, -maxLat, 180, maxLat},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
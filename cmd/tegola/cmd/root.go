@@ -7,12 +7,15 @@ import (
 	"log"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/atlas"
 	"github.com/go-spatial/tegola/cache"
+	//	the mbtiles cache uses sqlite (cgo) so it's registered here, like the gpkg provider, rather than by the atlas
+	_ "github.com/go-spatial/tegola/cache/mbtiles"
 	"github.com/go-spatial/tegola/config"
 	"github.com/go-spatial/tegola/provider"
 	_ "github.com/go-spatial/tegola/provider/debug"
@@ -26,6 +29,8 @@ var (
 	Version = "version not set"
 	// parsed config
 	conf config.Config
+	// the providers instantiated from the config
+	providers map[string]provider.Tiler
 )
 
 func init() {
@@ -34,6 +39,8 @@ func init() {
 
 	// server
 	serverCmd.Flags().StringVarP(&serverPort, "port", "p", ":8080", "port to bind tile server to")
+	serverCmd.Flags().BoolVarP(&serverWatch, "watch", "", false, "reload the config when the config file changes. the config is always reloaded on SIGHUP")
+	serverCmd.Flags().DurationVarP(&serverWatchInterval, "watch-interval", "", 5*time.Second, "how often to check the config file for changes when --watch is set")
 	RootCmd.AddCommand(serverCmd)
 
 	// cache seed / purge
@@ -44,6 +51,12 @@ func init() {
 	cacheCmd.Flags().StringVarP(&cacheBounds, "bounds", "", "-180,-85.0511,180,85.0511", "lat / long bounds to seed the cache with in the format: minx, miny, maxx, maxy")
 	cacheCmd.Flags().IntVarP(&cacheConcurrency, "concurrency", "", runtime.NumCPU(), "the amount of concurrency to use. defaults to the number of CPUs on the machine")
 	cacheCmd.Flags().BoolVarP(&cacheOverwrite, "overwrite", "", false, "overwrite the cache if a tile already exists")
+	cacheCmd.Flags().StringVarP(&cacheTileList, "tile-list", "", "", "path to a file of tiles in z/x/y format, one per line (i.e. an expire list), to use instead of the bounds. use - to read from stdin. the tiles are expanded to their ancestors from minzoom and their descendants to maxzoom")
+	cacheCmd.Flags().StringVarP(&cacheAOI, "aoi", "", "", "path to a GeoJSON or WKT file of polygons in lon / lat. only tiles intersecting the polygons are cached, replacing the bounds")
+	cacheCmd.Flags().StringVarP(&cacheCheckpoint, "checkpoint", "", "", "path to a file recording the completed columns of the seed. re-running the same seed with the checkpoint resumes where it stopped")
+	cacheCmd.Flags().DurationVarP(&cacheProgressInterval, "progress-interval", "", 30*time.Second, "how often to report the progress of the job. 0 disables the reports")
+	cacheCmd.Flags().StringVarP(&cachePartition, "partition", "", "", "cache a partition of the tiles in the i/n format, i.e. 2/4 for the second of four partitions. n processes, each given a partition of the same job, cache every tile once")
+	cacheCmd.Flags().StringVarP(&cachePMTiles, "pmtiles", "", "", "seed the map into a PMTiles archive at this path instead of the configured cache")
 
 	RootCmd.AddCommand(cacheCmd)
 
@@ -72,13 +85,13 @@ func initConfig() {
 	}
 
 	// init our providers
-	providers, err := initProviders(conf.Providers)
+	providers, err = initProviders(conf.Providers)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// init our maps
-	if err = initMaps(conf.Maps, providers); err != nil {
+	if err = initMaps(atlas.DefaultAtlas, conf.Maps, providers, conf.TileBuffer); err != nil {
 		log.Fatal(err)
 	}
 
@@ -91,6 +104,11 @@ func initConfig() {
 		if cache != nil {
 			atlas.SetCache(cache)
 		}
+
+		//	caches such as mbtiles store the map details alongside the tiles
+		if err = atlas.WriteCacheMetadata(); err != nil {
+			log.Fatal(err)
+		}
 	}
 }
 
@@ -110,8 +128,8 @@ func initCache(config map[string]interface{}) (cache.Interface, error) {
 	return cache.For(cType, config)
 }
 
-//	initMaps registers maps with our server
-func initMaps(maps []config.Map, providers map[string]provider.Tiler) error {
+//	initMaps registers maps with the provided atlas
+func initMaps(a *atlas.Atlas, maps []config.Map, providers map[string]provider.Tiler, tileBuffer int64) error {
 
 	//	iterate our maps
 	for _, m := range maps {
@@ -119,6 +137,29 @@ func initMaps(maps []config.Map, providers map[string]provider.Tiler) error {
 		newMap.Attribution = html.EscapeString(m.Attribution)
 		newMap.Center = m.Center
 
+		//	the global tile buffer is the default for every map
+		if tileBuffer > 0 {
+			newMap.TileBuffer = uint64(tileBuffer)
+		}
+		if m.TileBuffer > 0 {
+			newMap.TileBuffer = m.TileBuffer
+		}
+		if m.TileExtent > 0 {
+			newMap.TileExtent = m.TileExtent
+		}
+		if m.TileSize > 0 {
+			newMap.TileSize = m.TileSize
+		}
+
+		newMap.MinZoom, newMap.MaxZoom = m.ZoomRange()
+		newMap.MaxTileBytes = m.MaxTileBytes
+
+		cacheTTL, err := m.TTL()
+		if err != nil {
+			return err
+		}
+		newMap.CacheTTL = cacheTTL
+
 		if len(m.Bounds) == 4 {
 			newMap.Bounds = [4]float64{m.Bounds[0], m.Bounds[1], m.Bounds[2], m.Bounds[3]}
 		}
@@ -169,21 +210,77 @@ func initMaps(maps []config.Map, providers map[string]provider.Tiler) error {
 				}
 			}
 
+			minZoom, maxZoom := l.ZoomRange()
+
+			var cluster *atlas.Cluster
+			if l.Cluster != nil {
+				cluster = &atlas.Cluster{
+					Radius:  l.Cluster.Radius,
+					MaxZoom: maxZoom,
+				}
+				if l.Cluster.MaxZoom != nil {
+					cluster.MaxZoom = *l.Cluster.MaxZoom
+				}
+				for _, agg := range l.Cluster.Aggregations {
+					cluster.Aggregations = append(cluster.Aggregations, atlas.ClusterAggregation{
+						Name: agg.Name,
+						Op:   agg.Op,
+						Tag:  agg.Tag,
+					})
+				}
+			}
+
+			generalizeMaxZoom := maxZoom
+			if l.GeneralizeMaxZoom != nil {
+				generalizeMaxZoom = *l.GeneralizeMaxZoom
+			}
+
+			simplifyTolerances, err := l.SimplifyTolerances()
+			if err != nil {
+				return err
+			}
+
+			cacheTTL, err := l.TTL()
+			if err != nil {
+				return err
+			}
+
 			//	add our layer to our layers slice
 			newMap.Layers = append(newMap.Layers, atlas.Layer{
-				Name:              l.Name,
-				ProviderLayerName: providerLayer[1],
-				MinZoom:           l.MinZoom,
-				MaxZoom:           l.MaxZoom,
-				Provider:          provider,
-				DefaultTags:       defaultTags,
-				GeomType:          layerGeomType,
-				DontSimplify:      l.DontSimplify,
+				Name:               l.Name,
+				ProviderLayerName:  providerLayer[1],
+				MinZoom:            int(minZoom),
+				MaxZoom:            int(maxZoom),
+				Provider:           provider,
+				DefaultTags:        defaultTags,
+				GeomType:           layerGeomType,
+				DontSimplify:       l.DontSimplify,
+				DontDeduplicate:    l.DontDeduplicate,
+				TileExtent:         l.TileExtent,
+				TileBuffer:         l.TileBuffer,
+				Cluster:            cluster,
+				DropStrategy:       l.DropStrategy,
+				DropPriorityTag:    l.DropPriorityTag,
+				MergeLinesBy:       l.MergeLinesBy,
+				DissolvePolygonsBy: l.DissolvePolygonsBy,
+				GeneralizeMaxZoom:  generalizeMaxZoom,
+				LabelLayer:         l.LabelLayer,
+				SimplifyTolerance:  l.SimplifyTolerance,
+				SimplifyTolerances: simplifyTolerances,
+				SimplifyAlgorithm:  l.SimplifyAlgorithm,
+				PreserveTopology:   l.PreserveTopology,
+				Validation:         l.Validation,
+				ComplexTags:        l.ComplexTags,
+				TagKeySeparator:    l.TagKeySeparator,
+				IntegerTags:        l.IntegerTags,
+				IDStrategy:         l.IDStrategy,
+				IDTag:              l.IDTag,
+				CacheTTL:           cacheTTL,
 			})
 		}
 
 		//	register map
-		atlas.AddMap(newMap)
+		a.AddMap(newMap)
 	}
 
 	return nil
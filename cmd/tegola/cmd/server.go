@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	gdcmd "github.com/gdey/cmd"
 	"github.com/spf13/cobra"
 	"github.com/go-spatial/tegola/provider"
@@ -10,12 +12,18 @@ import (
 var (
 	serverPort      string
 	defaultHTTPPort = ":8080"
+	//	reload the config when the config file is modified
+	serverWatch bool
+	//	how often to check the config file for modifications
+	serverWatchInterval time.Duration
 )
 
 var serverCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Use tegola as a tile server",
-	Long:  `Use tegola as a vector tile server. Maps tiles will be served at /maps/:map_name/:z/:x/:y`,
+	Long: `Use tegola as a vector tile server. Maps tiles will be served at /maps/:map_name/:z/:x/:y
+
+Sending the process a SIGHUP reloads the config without restarting the server.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gdcmd.New()
 		initConfig()
@@ -36,14 +44,15 @@ var serverCmd = &cobra.Command{
 			server.CORSAllowedOrigin = conf.Webserver.CORSAllowedOrigin
 		}
 
-		//	set tile buffer
-		if conf.TileBuffer > 0 {
-			server.TileBuffer = float64(conf.TileBuffer)
-		}
+		server.CacheStaleWhileRevalidate = conf.Webserver.CacheStaleWhileRevalidate
 
 		//	start our webserver
 		srv := server.Start(serverPort)
 		shutdown(srv)
+
+		//	reload the config on SIGHUP or, optionally, when the config file changes
+		watchReload(serverWatch, serverWatchInterval, reloadConfig)
+
 		<-gdcmd.Cancelled()
 		gdcmd.Complete()
 
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	gdcmd "github.com/gdey/cmd"
+
+	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/config"
+	"github.com/go-spatial/tegola/internal/log"
+	"github.com/go-spatial/tegola/provider"
+	"github.com/go-spatial/tegola/server"
+)
+
+//	reloadConfig re-parses the config file, builds new providers and maps off to the side and
+//	swaps them into the server. The previous providers are released once the requests using them complete.
+//	The cache is kept when the [cache] section is unchanged, otherwise the previous cache is closed once the
+//	requests using it complete. If any step fails the server keeps serving the current config.
+func reloadConfig() error {
+	c, err := config.Load(configFile)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Validate(); err != nil {
+		return err
+	}
+
+	newProviders, err := initProviders(c.Providers)
+	if err != nil {
+		releaseProviders(newProviders)
+		return err
+	}
+
+	a := &atlas.Atlas{}
+	if err = initMaps(a, c.Maps, newProviders, c.TileBuffer); err != nil {
+		releaseProviders(newProviders)
+		return err
+	}
+
+	//	keep the current cache, and the tiles it holds, unless the cache config changed
+	previousCache := atlas.GetCache()
+	cacher := previousCache
+	replaceCache := cacheConfigChanged(conf.Cache, c.Cache)
+	if replaceCache {
+		cacher = nil
+		if len(c.Cache) != 0 {
+			if cacher, err = initCache(c.Cache); err != nil {
+				releaseProviders(newProviders)
+				return err
+			}
+		}
+	}
+
+	if cacher != nil {
+		a.SetCache(cacher)
+
+		//	the maps may have changed so the metadata is written to kept caches too
+		if err = a.WriteCacheMetadata(); err != nil {
+			releaseProviders(newProviders)
+			if replaceCache {
+				closeCache(cacher)
+			}
+			return err
+		}
+	}
+
+	//	webserver settings require a restart
+	if c.Webserver != conf.Webserver {
+		log.Warn("config reload: changes to the [webserver] section require a restart")
+	}
+
+	//	blocks until in-flight requests on the previous atlas complete
+	server.Reload(a)
+
+	releaseProviders(providers)
+	providers = newProviders
+	conf = c
+
+	if replaceCache && previousCache != nil {
+		closeCache(previousCache)
+	}
+
+	return nil
+}
+
+//	cacheConfigChanged reports if the [cache] section of the config changed between two configs
+func cacheConfigChanged(previous, next map[string]interface{}) bool {
+	if len(previous) == 0 && len(next) == 0 {
+		return false
+	}
+
+	return !reflect.DeepEqual(previous, next)
+}
+
+//	closeCache releases the resources (i.e. open files) of a cache which is no longer used
+func closeCache(c cache.Interface) {
+	if err := cache.Close(c); err != nil {
+		log.Errorf("error closing cache: %v", err)
+	}
+}
+
+//	releaseProviders frees the resources of the provided provider instances
+func releaseProviders(ps map[string]provider.Tiler) {
+	for name, p := range ps {
+		if err := provider.Release(p); err != nil {
+			log.Errorf("error releasing provider (%v): %v", name, err)
+		}
+	}
+}
+
+//	watchReload calls reload when the process receives a SIGHUP. If watch is true
+//	the config file is also polled for modifications every interval.
+func watchReload(watch bool, interval time.Duration, reload func() error) {
+	reloads := make(chan struct{})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloads <- struct{}{}
+		}
+	}()
+
+	if watch {
+		if strings.HasPrefix(configFile, "http") {
+			log.Warnf("config watch is not supported for remote configs (%v). use SIGHUP to reload", configFile)
+		} else {
+			go watchFile(configFile, interval, reloads)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case <-reloads:
+				log.Infof("reloading config (%v)", configFile)
+				if err := reload(); err != nil {
+					log.Errorf("config reload failed, continuing with the current config: %v", err)
+					continue
+				}
+				log.Info("config reloaded")
+			case <-gdcmd.Cancelled():
+				signal.Stop(hup)
+				return
+			}
+		}
+	}()
+}
+
+//	watchFile polls the file at the provided location and notifies reload when its modification time changes
+func watchFile(location string, interval time.Duration, reload chan<- struct{}) {
+	var modTime time.Time
+	if fi, err := os.Stat(location); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fi, err := os.Stat(location)
+			if err != nil {
+				log.Warnf("config watch: %v", err)
+				continue
+			}
+			if !fi.ModTime().Equal(modTime) {
+				modTime = fi.ModTime()
+				reload <- struct{}{}
+			}
+		case <-gdcmd.Cancelled():
+			return
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/config"
+)
+
+// closeCounter is a cache which records when it's closed
+type closeCounter struct {
+	name string
+
+	sync.Mutex
+	closed int
+}
+
+func (cc *closeCounter) Get(key *cache.Key) ([]byte, bool, error) { return nil, false, nil }
+func (cc *closeCounter) Set(key *cache.Key, val []byte) error     { return nil }
+func (cc *closeCounter) Purge(key *cache.Key) error               { return nil }
+
+func (cc *closeCounter) Close() error {
+	cc.Lock()
+	defer cc.Unlock()
+
+	cc.closed++
+	return nil
+}
+
+func (cc *closeCounter) Closed() int {
+	cc.Lock()
+	defer cc.Unlock()
+
+	return cc.closed
+}
+
+func init() {
+	cache.Register("reloadtest", func(config map[string]interface{}) (cache.Interface, error) {
+		name, _ := config["name"].(string)
+		return &closeCounter{name: name}, nil
+	})
+}
+
+const reloadTestConfig = `
+[cache]
+type = "reloadtest"
+name = "%v"
+
+[[providers]]
+name = "debug"
+type = "debug"
+
+[[maps]]
+name = "%v"
+	[[maps.layers]]
+	provider_layer = "debug.debug-tile-outline"
+`
+
+func TestCacheConfigChanged(t *testing.T) {
+	type tcase struct {
+		previous map[string]interface{}
+		next     map[string]interface{}
+		expected bool
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		if output := cacheConfigChanged(tc.previous, tc.next); output != tc.expected {
+			t.Errorf("expected %v got %v", tc.expected, output)
+		}
+	}
+
+	tests := map[string]tcase{
+		"no cache": {
+			previous: nil,
+			next:     map[string]interface{}{},
+			expected: false,
+		},
+		"unchanged": {
+			previous: map[string]interface{}{"type": "file", "basepath": "/tmp", "max_zoom": int64(10)},
+			next:     map[string]interface{}{"type": "file", "basepath": "/tmp", "max_zoom": int64(10)},
+			expected: false,
+		},
+		"changed value": {
+			previous: map[string]interface{}{"type": "file", "basepath": "/tmp"},
+			next:     map[string]interface{}{"type": "file", "basepath": "/var"},
+			expected: true,
+		},
+		"added": {
+			previous: nil,
+			next:     map[string]interface{}{"type": "memory"},
+			expected: true,
+		},
+		"removed": {
+			previous: map[string]interface{}{"type": "memory"},
+			next:     nil,
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) { fn(t, tc) })
+	}
+}
+
+func TestReloadConfigCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tegola-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	previousConfigFile, previousConf, previousProviders := configFile, conf, providers
+	defer func() {
+		configFile, conf, providers = previousConfigFile, previousConf, previousProviders
+		atlas.DefaultAtlas.Swap(&atlas.Atlas{})
+	}()
+
+	configFile = filepath.Join(dir, "config.toml")
+	conf, providers = config.Config{}, nil
+
+	write := func(cacheName, mapName string) {
+		data := []byte(fmtReloadConfig(cacheName, mapName))
+		if err := ioutil.WriteFile(configFile, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reload := func() *closeCounter {
+		if err := reloadConfig(); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		c, ok := atlas.GetCache().(*closeCounter)
+		if !ok {
+			t.Fatalf("expected a *closeCounter cache got %T", atlas.GetCache())
+		}
+		return c
+	}
+
+	write("a", "first")
+	first := reload()
+
+	//	a map change keeps the cache
+	write("a", "second")
+	if c := reload(); c != first {
+		t.Errorf("expected the cache to be kept when the cache config is unchanged")
+	}
+	if _, err := atlas.GetMap("second"); err != nil {
+		t.Errorf("expected the reloaded map: %v", err)
+	}
+	if first.Closed() != 0 {
+		t.Errorf("expected the kept cache to be open, closed %v times", first.Closed())
+	}
+
+	//	a cache change replaces the cache and closes the previous one
+	write("b", "second")
+	if c := reload(); c == first || c.name != "b" {
+		t.Errorf("expected a new cache when the cache config changed")
+	}
+	if first.Closed() != 1 {
+		t.Errorf("expected the replaced cache to be closed once, closed %v times", first.Closed())
+	}
+}
+
+func fmtReloadConfig(cacheName, mapName string) string {
+	return fmt.Sprintf(reloadTestConfig, cacheName, mapName)
+}
+
+func TestWatchFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "tegola-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	reloads := make(chan struct{}, 1)
+	go watchFile(f.Name(), 10*time.Millisecond, reloads)
+
+	//	let the watcher read the initial modification time
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case <-reloads:
+		t.Fatal("unexpected reload before the file changed")
+	default:
+	}
+
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(f.Name(), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reloads:
+	case <-time.After(time.Second):
+		t.Fatal("expected a reload after the file changed")
+	}
+}
+
+func TestWatchReloadSIGHUP(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	watchReload(false, 0, func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("expected a reload after SIGHUP")
+	}
+}
@@ -10,12 +10,14 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/BurntSushi/toml"
 
+	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/internal/log"
 )
 
@@ -38,6 +40,8 @@ type Webserver struct {
 	HostName          string `toml:"hostname"`
 	Port              string `toml:"port"`
 	CORSAllowedOrigin string `toml:"cors_allowed_origin"`
+	//	CacheStaleWhileRevalidate serves expired tiles from the cache while they are regenerated in the background
+	CacheStaleWhileRevalidate bool `toml:"cache_stale_while_revalidate"`
 }
 
 // A Map represents a map in the Tegola Config file.
@@ -47,6 +51,47 @@ type Map struct {
 	Bounds      []float64  `toml:"bounds"`
 	Center      [3]float64 `toml:"center"`
 	Layers      []MapLayer `toml:"layers"`
+	//	TileExtent is the MVT extent the map's layers are encoded with. Default: 4096
+	TileExtent uint64 `toml:"tile_extent"`
+	//	TileBuffer is the buffer (in tile extent units) added around each tile. overrides the global tile_buffer
+	TileBuffer uint64 `toml:"tile_buffer"`
+	//	TileSize is the size in pixels the map's tiles are rendered at (i.e. 512). Must be a multiple of 256, up to 1024. Default: 256
+	TileSize uint64 `toml:"tile_size"`
+	//	MinZoom and MaxZoom limit the zoom levels tiles are served for. Default: 0 - 22
+	MinZoom *uint `toml:"min_zoom"`
+	MaxZoom *uint `toml:"max_zoom"`
+	//	MaxTileBytes is the size budget, in bytes, for the map's tiles. Features are dropped from layers
+	//	with a drop_strategy until a tile fits. Default: 0 (no budget)
+	MaxTileBytes uint64 `toml:"max_tile_bytes"`
+	//	CacheTTL is how long the map's tiles are cached for (i.e. "1h"). Default: tiles don't expire
+	CacheTTL string `toml:"cache_ttl"`
+}
+
+//	ZoomRange returns the map's min and max zoom, applying the defaults when they are not set
+func (m Map) ZoomRange() (min, max uint) {
+	return zoomRange(m.MinZoom, m.MaxZoom)
+}
+
+//	TTL returns the map's cache_ttl. 0 means the tiles don't expire
+func (m Map) TTL() (time.Duration, error) {
+	return parseCacheTTL(m.Name, m.CacheTTL)
+}
+
+//	parseCacheTTL parses the cache_ttl of the map or layer with the name
+func parseCacheTTL(name, ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d < 0 {
+		return 0, ErrInvalidCacheTTL{
+			Name:     name,
+			CacheTTL: ttl,
+		}
+	}
+
+	return d, nil
 }
 
 type MapLayer struct {
@@ -54,12 +99,174 @@ type MapLayer struct {
 	//	Name can also be used to group multiple ProviderLayers under the same namespace.
 	Name          string      `toml:"name"`
 	ProviderLayer string      `toml:"provider_layer"`
-	MinZoom       int         `toml:"min_zoom"`
-	MaxZoom       int         `toml:"max_zoom"`
+	MinZoom       *uint       `toml:"min_zoom"`
+	MaxZoom       *uint       `toml:"max_zoom"`
 	DefaultTags   interface{} `toml:"default_tags"`
 	//	DontSimplify indicates wheather feature simplification should be applied.
 	//	We use a negative in the name so the default is to simplify
 	DontSimplify bool `toml:"dont_simplify"`
+	//	DontDeduplicate turns off skipping features whose ID has already been fetched for the tile
+	DontDeduplicate bool `toml:"dont_deduplicate"`
+	//	TileExtent and TileBuffer override the map's values for this layer when set
+	TileExtent uint64 `toml:"tile_extent"`
+	TileBuffer uint64 `toml:"tile_buffer"`
+	//	Cluster enables clustering of the layer's point features
+	Cluster *MapLayerCluster `toml:"cluster"`
+	//	DropStrategy is how features are dropped when a tile exceeds the map's max_tile_bytes.
+	//	One of: smallest_area, priority, uniform. Default: features are not dropped
+	DropStrategy string `toml:"drop_strategy"`
+	//	DropPriorityTag is the numeric tag features are ranked by for the priority drop strategy
+	DropPriorityTag string `toml:"drop_priority_tag"`
+	//	MergeLinesBy merges touching lines which have the same values for these tags
+	MergeLinesBy []string `toml:"merge_lines_by"`
+	//	DissolvePolygonsBy dissolves adjacent polygons which have the same values for these tags
+	DissolvePolygonsBy []string `toml:"dissolve_polygons_by"`
+	//	GeneralizeMaxZoom is the highest zoom lines are merged and polygons dissolved at. Default: the layer's max zoom
+	GeneralizeMaxZoom *uint `toml:"generalize_max_zoom"`
+	//	LabelLayer is the name of a companion point layer with a label point for each of the layer's polygons
+	LabelLayer string `toml:"label_layer"`
+	//	SimplifyTolerance overrides the default simplification tolerance (10)
+	SimplifyTolerance float64 `toml:"simplify_tolerance"`
+	//	SimplifyToleranceZooms sets the simplification tolerance from a zoom on (i.e. { "10" = 5.0 })
+	SimplifyToleranceZooms map[string]float64 `toml:"simplify_tolerance_zooms"`
+	//	SimplifyAlgorithm is the algorithm polygons are simplified with. One of: douglas_peucker, visvalingam
+	SimplifyAlgorithm string `toml:"simplify_algorithm"`
+	//	PreserveTopology simplifies the boundaries shared between the layer's polygons identically
+	PreserveTopology bool `toml:"preserve_topology"`
+	//	Validation is how the layer's geometries are validated. One of: none, clip_only, make_valid (default), strict_reject
+	Validation string `toml:"validation"`
+	//	ComplexTags is how map tag values (i.e. hstore columns) are encoded. One of: merge (default), flatten, json
+	ComplexTags string `toml:"complex_tags"`
+	//	TagKeySeparator joins the keys of maps flattened by complex_tags = "flatten". Default: "."
+	TagKeySeparator string `toml:"tag_key_separator"`
+	//	IntegerTags is the vector tile value type integer tags are encoded as. One of: sint, int, uint
+	IntegerTags string `toml:"integer_tags"`
+	//	IDStrategy is how the provider's feature IDs are encoded. One of: uint (default), hash, signed, none
+	IDStrategy string `toml:"id_strategy"`
+	//	IDTag is the tag the provider's feature ID is copied into
+	IDTag string `toml:"id_tag"`
+	//	CacheTTL is how long tiles with the layer are cached for (i.e. "10m"). Tiles with multiple layers
+	//	use the shortest of the layers' and the map's cache_ttl
+	CacheTTL string `toml:"cache_ttl"`
+}
+
+//	TTL returns the layer's cache_ttl. 0 means the layer doesn't shorten the map's cache_ttl
+func (ml MapLayer) TTL() (time.Duration, error) {
+	return parseCacheTTL(ml.ProviderLayer, ml.CacheTTL)
+}
+
+//	SimplifyTolerances returns the simplify_tolerance_zooms keyed by zoom
+func (ml MapLayer) SimplifyTolerances() (map[uint]float64, error) {
+	if len(ml.SimplifyToleranceZooms) == 0 {
+		return nil, nil
+	}
+
+	tolerances := make(map[uint]float64, len(ml.SimplifyToleranceZooms))
+	for k, v := range ml.SimplifyToleranceZooms {
+		z, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || z > tegola.MaxZ {
+			return nil, ErrInvalidSimplifyToleranceZoom{
+				ProviderLayer: ml.ProviderLayer,
+				Zoom:          k,
+			}
+		}
+		tolerances[uint(z)] = v
+	}
+
+	return tolerances, nil
+}
+
+//	MapLayerCluster configures point clustering for a map layer
+type MapLayerCluster struct {
+	//	Radius in pixels points are clustered within. Default: 40
+	Radius uint `toml:"radius"`
+	//	MaxZoom is the highest zoom points are clustered at. Default: the layer's max zoom
+	MaxZoom *uint `toml:"max_zoom"`
+	//	Aggregations are computed over the tags of the clustered points
+	Aggregations []ClusterAggregation `toml:"aggregations"`
+}
+
+//	ClusterAggregation emits the tag Name on each cluster, computed by applying Op to the Tag values of the clustered points
+type ClusterAggregation struct {
+	Name string `toml:"name"`
+	//	Op is one of: sum, min, max, mean
+	Op  string `toml:"op"`
+	Tag string `toml:"tag"`
+}
+
+//	supported cluster aggregation ops
+var clusterAggregationOps = map[string]bool{
+	"sum":  true,
+	"min":  true,
+	"max":  true,
+	"mean": true,
+}
+
+//	supported polygon simplification algorithms
+var simplifyAlgorithms = map[string]bool{
+	"douglas_peucker": true,
+	"visvalingam":     true,
+}
+
+//	supported geometry validation modes
+var validationModes = map[string]bool{
+	"none":          true,
+	"clip_only":     true,
+	"make_valid":    true,
+	"strict_reject": true,
+}
+
+//	supported encodings of map tag values
+var complexTagModes = map[string]bool{
+	"merge":   true,
+	"flatten": true,
+	"json":    true,
+}
+
+//	supported integer tag value types
+var integerTagTypes = map[string]bool{
+	"sint": true,
+	"int":  true,
+	"uint": true,
+}
+
+//	supported feature ID strategies
+var idStrategies = map[string]bool{
+	"uint":   true,
+	"hash":   true,
+	"signed": true,
+	"none":   true,
+}
+
+//	supported feature drop strategies
+var dropStrategies = map[string]bool{
+	"smallest_area": true,
+	"priority":      true,
+	"uniform":       true,
+}
+
+//	ZoomRange returns the layer's min and max zoom, applying the defaults when they are not set.
+//	a max_zoom of 0 means the layer is not bounded, as it always has for layers
+func (ml MapLayer) ZoomRange() (min, max uint) {
+	min, max = zoomRange(ml.MinZoom, ml.MaxZoom)
+	if max == 0 {
+		max = tegola.MaxZ
+	}
+
+	return min, max
+}
+
+//	zoomRange defaults a missing min zoom to 0 and a missing max zoom to tegola.MaxZ
+func zoomRange(minZoom, maxZoom *uint) (min, max uint) {
+	min, max = 0, tegola.MaxZ
+	if minZoom != nil {
+		min = *minZoom
+	}
+	if maxZoom != nil {
+		max = *maxZoom
+	}
+
+	return min, max
 }
 
 //	checks the config for issues
@@ -69,6 +276,26 @@ func (c *Config) Validate() error {
 	//	map of layers to providers
 	mapLayers := map[string]map[string]MapLayer{}
 	for _, m := range c.Maps {
+		if min, max := m.ZoomRange(); min > max || max > tegola.MaxZ {
+			return ErrInvalidZoomRange{
+				Name:    m.Name,
+				MinZoom: min,
+				MaxZoom: max,
+			}
+		}
+
+		//	tile sizes are served as multiples of the default tile size
+		if m.TileSize != 0 && (m.TileSize%tegola.DefaultTileSize != 0 || m.TileSize > tegola.DefaultTileSize*tegola.MaxTileScale) {
+			return ErrInvalidTileSize{
+				MapName:  m.Name,
+				TileSize: m.TileSize,
+			}
+		}
+
+		if _, err := m.TTL(); err != nil {
+			return err
+		}
+
 		if _, ok := mapLayers[m.Name]; !ok {
 			mapLayers[m.Name] = map[string]MapLayer{}
 		}
@@ -90,10 +317,82 @@ func (c *Config) Validate() error {
 				name = plParts[1]
 			}
 
+			lMin, lMax := l.ZoomRange()
+			if lMin > lMax || lMax > tegola.MaxZ {
+				return ErrInvalidZoomRange{
+					Name:    l.ProviderLayer,
+					MinZoom: lMin,
+					MaxZoom: lMax,
+				}
+			}
+
+			if l.SimplifyAlgorithm != "" && !simplifyAlgorithms[l.SimplifyAlgorithm] {
+				return ErrInvalidSimplifyAlgorithm{
+					ProviderLayer:     l.ProviderLayer,
+					SimplifyAlgorithm: l.SimplifyAlgorithm,
+				}
+			}
+
+			if l.Validation != "" && !validationModes[l.Validation] {
+				return ErrInvalidValidation{
+					ProviderLayer: l.ProviderLayer,
+					Validation:    l.Validation,
+				}
+			}
+
+			if l.ComplexTags != "" && !complexTagModes[l.ComplexTags] {
+				return ErrInvalidComplexTags{
+					ProviderLayer: l.ProviderLayer,
+					ComplexTags:   l.ComplexTags,
+				}
+			}
+
+			if l.IntegerTags != "" && !integerTagTypes[l.IntegerTags] {
+				return ErrInvalidIntegerTags{
+					ProviderLayer: l.ProviderLayer,
+					IntegerTags:   l.IntegerTags,
+				}
+			}
+
+			if l.IDStrategy != "" && !idStrategies[l.IDStrategy] {
+				return ErrInvalidIDStrategy{
+					ProviderLayer: l.ProviderLayer,
+					IDStrategy:    l.IDStrategy,
+				}
+			}
+
+			if _, err := l.TTL(); err != nil {
+				return err
+			}
+
+			if _, err := l.SimplifyTolerances(); err != nil {
+				return err
+			}
+
+			if l.DropStrategy != "" && (!dropStrategies[l.DropStrategy] || (l.DropStrategy == "priority" && l.DropPriorityTag == "")) {
+				return ErrInvalidDropStrategy{
+					ProviderLayer: l.ProviderLayer,
+					DropStrategy:  l.DropStrategy,
+				}
+			}
+
+			if l.Cluster != nil {
+				for _, agg := range l.Cluster.Aggregations {
+					if agg.Name == "" || agg.Tag == "" || !clusterAggregationOps[agg.Op] {
+						return ErrInvalidClusterAggregation{
+							ProviderLayer: l.ProviderLayer,
+							Name:          agg.Name,
+							Op:            agg.Op,
+						}
+					}
+				}
+			}
+
 			//	check if we already have this layer
 			if val, ok := mapLayers[m.Name][name]; ok {
+				valMin, valMax := val.ZoomRange()
 				//	we have a hit. check for zoom range overlap
-				if val.MinZoom <= l.MaxZoom && l.MinZoom <= val.MaxZoom {
+				if valMin <= lMax && lMin <= valMax {
 					return ErrOverlappingLayerZooms{
 						ProviderLayer1: val.ProviderLayer,
 						ProviderLayer2: l.ProviderLayer,
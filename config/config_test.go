@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/arolek/p"
+
 	"github.com/go-spatial/tegola/config"
 )
 
@@ -86,12 +88,14 @@ func TestParse(t *testing.T) {
 				attribution = "Test Attribution"
 				bounds = [-180.0, -85.05112877980659, 180.0, 85.0511287798066]
 				center = [-76.275329586789, 39.153492567373, 8.0]
+				tile_extent = 8192
 
 					[[maps.layers]]
 					provider_layer = "provider1.water"
 					min_zoom = 10
 					max_zoom = 20
-					dont_simplify = true`,
+					dont_simplify = true
+					tile_buffer = 256`,
 			expected: config.Config{
 				TileBuffer:   12,
 				LocationName: "",
@@ -129,12 +133,14 @@ func TestParse(t *testing.T) {
 						Attribution: "Test Attribution",
 						Bounds:      []float64{-180, -85.05112877980659, 180, 85.0511287798066},
 						Center:      [3]float64{-76.275329586789, 39.153492567373, 8.0},
+						TileExtent:  8192,
 						Layers: []config.MapLayer{
 							{
 								ProviderLayer: "provider1.water",
-								MinZoom:       10,
-								MaxZoom:       20,
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(20),
 								DontSimplify:  true,
+								TileBuffer:    256,
 							},
 						},
 					},
@@ -244,14 +250,14 @@ func TestParse(t *testing.T) {
 							{
 								Name:          "water",
 								ProviderLayer: "provider1.water_0_5",
-								MinZoom:       0,
-								MaxZoom:       5,
+								MinZoom:       p.Uint(0),
+								MaxZoom:       p.Uint(5),
 							},
 							{
 								Name:          "water",
 								ProviderLayer: "provider1.water_6_10",
-								MinZoom:       6,
-								MaxZoom:       10,
+								MinZoom:       p.Uint(6),
+								MaxZoom:       p.Uint(10),
 							},
 						},
 					},
@@ -264,14 +270,14 @@ func TestParse(t *testing.T) {
 							{
 								Name:          "water",
 								ProviderLayer: "provider1.water_0_5",
-								MinZoom:       0,
-								MaxZoom:       5,
+								MinZoom:       p.Uint(0),
+								MaxZoom:       p.Uint(5),
 							},
 							{
 								Name:          "water",
 								ProviderLayer: "provider1.water_6_10",
-								MinZoom:       6,
-								MaxZoom:       10,
+								MinZoom:       p.Uint(6),
+								MaxZoom:       p.Uint(10),
 							},
 						},
 					},
@@ -356,13 +362,13 @@ func TestValidate(t *testing.T) {
 						Layers: []config.MapLayer{
 							{
 								ProviderLayer: "provider1.water",
-								MinZoom:       10,
-								MaxZoom:       20,
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(20),
 							},
 							{
 								ProviderLayer: "provider2.water",
-								MinZoom:       10,
-								MaxZoom:       20,
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(20),
 							},
 						},
 					},
@@ -421,14 +427,14 @@ func TestValidate(t *testing.T) {
 							{
 								Name:          "water",
 								ProviderLayer: "provider1.water_0_5",
-								MinZoom:       0,
-								MaxZoom:       5,
+								MinZoom:       p.Uint(0),
+								MaxZoom:       p.Uint(5),
 							},
 							{
 								Name:          "water",
 								ProviderLayer: "provider2.water_5_10",
-								MinZoom:       5,
-								MaxZoom:       10,
+								MinZoom:       p.Uint(5),
+								MaxZoom:       p.Uint(10),
 							},
 						},
 					},
@@ -490,13 +496,13 @@ func TestValidate(t *testing.T) {
 						Layers: []config.MapLayer{
 							{
 								ProviderLayer: "provider1.water",
-								MinZoom:       10,
-								MaxZoom:       15,
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(15),
 							},
 							{
 								ProviderLayer: "provider2.water",
-								MinZoom:       16,
-								MaxZoom:       20,
+								MinZoom:       p.Uint(16),
+								MaxZoom:       p.Uint(20),
 							},
 						},
 					},
@@ -508,13 +514,13 @@ func TestValidate(t *testing.T) {
 						Layers: []config.MapLayer{
 							{
 								ProviderLayer: "provider1.water",
-								MinZoom:       10,
-								MaxZoom:       15,
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(15),
 							},
 							{
 								ProviderLayer: "provider2.water",
-								MinZoom:       16,
-								MaxZoom:       20,
+								MinZoom:       p.Uint(16),
+								MaxZoom:       p.Uint(20),
 							},
 						},
 					},
@@ -522,6 +528,318 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		"4": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name:     "osm",
+						TileSize: 300,
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(15),
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidTileSize{
+				MapName:  "osm",
+				TileSize: 300,
+			},
+		},
+		"5": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name:    "osm",
+						MinZoom: p.Uint(12),
+						MaxZoom: p.Uint(8),
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidZoomRange{
+				Name:    "osm",
+				MinZoom: 12,
+				MaxZoom: 8,
+			},
+		},
+		"6": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								MaxZoom:       p.Uint(30),
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidZoomRange{
+				Name:    "provider1.water",
+				MinZoom: 0,
+				MaxZoom: 30,
+			},
+		},
+		"7": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.poi",
+								Cluster: &config.MapLayerCluster{
+									Aggregations: []config.ClusterAggregation{
+										{Name: "total", Op: "median", Tag: "value"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidClusterAggregation{
+				ProviderLayer: "provider1.poi",
+				Name:          "total",
+				Op:            "median",
+			},
+		},
+		"8": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name:         "osm",
+						MaxTileBytes: 500000,
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								DropStrategy:  "priority",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidDropStrategy{
+				ProviderLayer: "provider1.water",
+				DropStrategy:  "priority",
+			},
+		},
+		"9": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								SimplifyToleranceZooms: map[string]float64{
+									"10":  5,
+									"z12": 2,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidSimplifyToleranceZoom{
+				ProviderLayer: "provider1.water",
+				Zoom:          "z12",
+			},
+		},
+		"10": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer:     "provider1.water",
+								SimplifyAlgorithm: "ramer",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidSimplifyAlgorithm{
+				ProviderLayer:     "provider1.water",
+				SimplifyAlgorithm: "ramer",
+			},
+		},
+		"11": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								Validation:    "repair",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidValidation{
+				ProviderLayer: "provider1.water",
+				Validation:    "repair",
+			},
+		},
+		"12": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								ComplexTags:   "hstore",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidComplexTags{
+				ProviderLayer: "provider1.water",
+				ComplexTags:   "hstore",
+			},
+		},
+		"13": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								IntegerTags:   "int32",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidIntegerTags{
+				ProviderLayer: "provider1.water",
+				IntegerTags:   "int32",
+			},
+		},
+		"14": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								IDStrategy:    "uuid",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidIDStrategy{
+				ProviderLayer: "provider1.water",
+				IDStrategy:    "uuid",
+			},
+		},
+		"15": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name:     "osm",
+						CacheTTL: "1 hour",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidCacheTTL{
+				Name:     "osm",
+				CacheTTL: "1 hour",
+			},
+		},
+		"16": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water",
+								CacheTTL:      "-5m",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrInvalidCacheTTL{
+				Name:     "provider1.water",
+				CacheTTL: "-5m",
+			},
+		},
+		"17": {
+			//	a layer max_zoom of 0 is not bounded
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water_detail",
+								Name:          "water",
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(0),
+							},
+							{
+								ProviderLayer: "provider1.water_overview",
+								Name:          "water",
+								MinZoom:       p.Uint(3),
+								MaxZoom:       p.Uint(9),
+							},
+						},
+					},
+				},
+			},
+		},
+		"18": {
+			config: config.Config{
+				Maps: []config.Map{
+					{
+						Name: "osm",
+						Layers: []config.MapLayer{
+							{
+								ProviderLayer: "provider1.water_detail",
+								Name:          "water",
+								MinZoom:       p.Uint(10),
+								MaxZoom:       p.Uint(0),
+							},
+							{
+								ProviderLayer: "provider1.water_overview",
+								Name:          "water",
+								MinZoom:       p.Uint(15),
+								MaxZoom:       p.Uint(18),
+							},
+						},
+					},
+				},
+			},
+			expectedErr: config.ErrOverlappingLayerZooms{
+				ProviderLayer1: "provider1.water_detail",
+				ProviderLayer2: "provider1.water_overview",
+			},
+		},
 	}
 
 	for name, tc := range tests {
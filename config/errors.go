@@ -1,6 +1,10 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/go-spatial/tegola"
+)
 
 type ErrMapNotFound struct {
 	MapName string
@@ -34,3 +38,105 @@ type ErrMissingEnvVar struct {
 func (e ErrMissingEnvVar) Error() string {
 	return fmt.Sprintf("config: config file is referencing an environment variable that is not set (%v)", e.EnvVar)
 }
+
+type ErrInvalidTileSize struct {
+	MapName  string
+	TileSize uint64
+}
+
+func (e ErrInvalidTileSize) Error() string {
+	return fmt.Sprintf("config: invalid tile_size (%v) for map (%v). must be a multiple of %v and no greater than %v", e.TileSize, e.MapName, tegola.DefaultTileSize, tegola.DefaultTileSize*tegola.MaxTileScale)
+}
+
+type ErrInvalidZoomRange struct {
+	Name    string
+	MinZoom uint
+	MaxZoom uint
+}
+
+func (e ErrInvalidZoomRange) Error() string {
+	return fmt.Sprintf("config: invalid zoom range (%v - %v) for (%v). min_zoom must be less than or equal to max_zoom and max_zoom can not be greater than %v", e.MinZoom, e.MaxZoom, e.Name, tegola.MaxZ)
+}
+
+type ErrInvalidClusterAggregation struct {
+	ProviderLayer string
+	Name          string
+	Op            string
+}
+
+func (e ErrInvalidClusterAggregation) Error() string {
+	return fmt.Sprintf("config: invalid cluster aggregation (name: %v, op: %v) for provider_layer (%v). name and tag are required and op must be one of: sum, min, max, mean", e.Name, e.Op, e.ProviderLayer)
+}
+
+type ErrInvalidDropStrategy struct {
+	ProviderLayer string
+	DropStrategy  string
+}
+
+func (e ErrInvalidDropStrategy) Error() string {
+	return fmt.Sprintf("config: invalid drop_strategy (%v) for provider_layer (%v). must be one of: smallest_area, priority, uniform. priority requires drop_priority_tag", e.DropStrategy, e.ProviderLayer)
+}
+
+type ErrInvalidSimplifyAlgorithm struct {
+	ProviderLayer     string
+	SimplifyAlgorithm string
+}
+
+func (e ErrInvalidSimplifyAlgorithm) Error() string {
+	return fmt.Sprintf("config: invalid simplify_algorithm (%v) for provider_layer (%v). must be one of: douglas_peucker, visvalingam", e.SimplifyAlgorithm, e.ProviderLayer)
+}
+
+type ErrInvalidSimplifyToleranceZoom struct {
+	ProviderLayer string
+	Zoom          string
+}
+
+func (e ErrInvalidSimplifyToleranceZoom) Error() string {
+	return fmt.Sprintf("config: invalid zoom (%v) in simplify_tolerance_zooms for provider_layer (%v). must be between 0 and %v", e.Zoom, e.ProviderLayer, tegola.MaxZ)
+}
+
+type ErrInvalidValidation struct {
+	ProviderLayer string
+	Validation    string
+}
+
+func (e ErrInvalidValidation) Error() string {
+	return fmt.Sprintf("config: invalid validation (%v) for provider_layer (%v). must be one of: none, clip_only, make_valid, strict_reject", e.Validation, e.ProviderLayer)
+}
+
+type ErrInvalidComplexTags struct {
+	ProviderLayer string
+	ComplexTags   string
+}
+
+func (e ErrInvalidComplexTags) Error() string {
+	return fmt.Sprintf("config: invalid complex_tags (%v) for provider_layer (%v). must be one of: merge, flatten, json", e.ComplexTags, e.ProviderLayer)
+}
+
+type ErrInvalidIntegerTags struct {
+	ProviderLayer string
+	IntegerTags   string
+}
+
+func (e ErrInvalidIntegerTags) Error() string {
+	return fmt.Sprintf("config: invalid integer_tags (%v) for provider_layer (%v). must be one of: sint, int, uint", e.IntegerTags, e.ProviderLayer)
+}
+
+type ErrInvalidCacheTTL struct {
+	//	Name is the name of the map or the provider_layer of the layer
+	Name     string
+	CacheTTL string
+}
+
+func (e ErrInvalidCacheTTL) Error() string {
+	return fmt.Sprintf("config: invalid cache_ttl (%v) for (%v). must be a duration (i.e. 1h30m)", e.CacheTTL, e.Name)
+}
+
+type ErrInvalidIDStrategy struct {
+	ProviderLayer string
+	IDStrategy    string
+}
+
+func (e ErrInvalidIDStrategy) Error() string {
+	return fmt.Sprintf("config: invalid id_strategy (%v) for provider_layer (%v). must be one of: uint, hash, signed, none", e.IDStrategy, e.ProviderLayer)
+}
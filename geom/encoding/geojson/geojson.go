@@ -2,6 +2,7 @@ package geojson
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/go-spatial/tegola/geom"
 	"github.com/go-spatial/tegola/geom/encoding"
@@ -19,6 +20,15 @@ const (
 	GeometryCollectionType GeoJSONType = "GeometryCollection"
 )
 
+// ErrUnknownType is returned when decoding a GeoJSON object of an unknown or unexpected type
+type ErrUnknownType struct {
+	Type string
+}
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("unknown geojson type: %v", e.Type)
+}
+
 type Geometry struct {
 	geom.Geometry
 }
@@ -99,6 +109,70 @@ func (geo Geometry) MarshalJSON() ([]byte, error) {
 	}
 }
 
+// UnmarshalJSON decodes a GeoJSON geometry. positions are decoded as x, y; additional
+// elements such as the altitude are discarded. a null geometry decodes to a nil Geometry
+func (geo *Geometry) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		geo.Geometry = nil
+		return nil
+	}
+
+	var raw struct {
+		Type       GeoJSONType     `json:"type"`
+		Coords     json.RawMessage `json:"coordinates"`
+		Geometries []Geometry      `json:"geometries"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var err error
+
+	switch raw.Type {
+	case PointType:
+		var g geom.Point
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case MultiPointType:
+		var g geom.MultiPoint
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case LineStringType:
+		var g geom.LineString
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case MultiLineStringType:
+		var g geom.MultiLineString
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case PolygonType:
+		var g geom.Polygon
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case MultiPolygonType:
+		var g geom.MultiPolygon
+		err = json.Unmarshal(raw.Coords, &g)
+		geo.Geometry = g
+
+	case GeometryCollectionType:
+		g := make(geom.Collection, 0, len(raw.Geometries))
+		for i := range raw.Geometries {
+			g = append(g, raw.Geometries[i].Geometry)
+		}
+		geo.Geometry = g
+
+	default:
+		return ErrUnknownType{string(raw.Type)}
+	}
+
+	return err
+}
+
 // featureType allows the GeoJSON type for Feature to be automatically set during json Marshalling
 // which avoids the user from accidenlty setting the incorrect GeoJSON type.
 type featureType struct{}
@@ -107,6 +181,10 @@ func (_ featureType) MarshalJSON() ([]byte, error) {
 	return []byte(`"Feature"`), nil
 }
 
+func (_ *featureType) UnmarshalJSON(b []byte) error {
+	return unmarshalType(b, "Feature")
+}
+
 type Feature struct {
 	Type featureType `json:"type"`
 	ID   *uint64     `json:"id,omitempty"`
@@ -124,6 +202,23 @@ func (_ featureCollectionType) MarshalJSON() ([]byte, error) {
 	return []byte(`"FeatureCollection"`), nil
 }
 
+func (_ *featureCollectionType) UnmarshalJSON(b []byte) error {
+	return unmarshalType(b, "FeatureCollection")
+}
+
+// unmarshalType checks the type member of a decoded object is the expected type
+func unmarshalType(b []byte, expected string) error {
+	var typ string
+	if err := json.Unmarshal(b, &typ); err != nil {
+		return err
+	}
+	if typ != expected {
+		return ErrUnknownType{typ}
+	}
+
+	return nil
+}
+
 type FeatureCollection struct {
 	Type     featureCollectionType `json:"type"`
 	Features []Feature             `json:"features"`
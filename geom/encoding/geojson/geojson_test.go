@@ -119,3 +119,114 @@ func TestFeatureMarshalJSON(t *testing.T) {
 		t.Run(name, func(t *testing.T) { fn(t, tc) })
 	}
 }
+
+func TestFeatureUnmarshalJSON(t *testing.T) {
+	type tcase struct {
+		data        []byte
+		expected    geom.Geometry
+		expectedErr error
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		t.Parallel()
+
+		var f geojson.Feature
+		err := json.Unmarshal(tc.data, &f)
+		if tc.expectedErr != nil {
+			if err == nil || err.Error() != tc.expectedErr.Error() {
+				t.Errorf("expected err %v got %v", tc.expectedErr, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("unexpected err: %v", err)
+			return
+		}
+
+		if !reflect.DeepEqual(tc.expected, f.Geometry.Geometry) {
+			t.Errorf("expected %v got %v", tc.expected, f.Geometry.Geometry)
+			return
+		}
+	}
+
+	tests := map[string]tcase{
+		"point": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[12.2,17.7]},"properties":null}`),
+			expected: geom.Point{12.2, 17.7},
+		},
+		"point with altitude": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[12.2,17.7,100]},"properties":null}`),
+			expected: geom.Point{12.2, 17.7},
+		},
+		"multi point": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[12.2,17.7],[13.3,18.8]]},"properties":null}`),
+			expected: geom.MultiPoint{{12.2, 17.7}, {13.3, 18.8}},
+		},
+		"linestring": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[3.2,4.3],[5.4,6.5]]},"properties":null}`),
+			expected: geom.LineString{{3.2, 4.3}, {5.4, 6.5}},
+		},
+		"multi linestring": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"MultiLineString","coordinates":[[[3.2,4.3],[5.4,6.5]],[[2.3,3.4],[4.5,5.6]]]},"properties":null}`),
+			expected: geom.MultiLineString{{{3.2, 4.3}, {5.4, 6.5}}, {{2.3, 3.4}, {4.5, 5.6}}},
+		},
+		"polygon": {
+			data:     []byte(`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[3.2,4.3],[5.4,6.5],[7.6,8.7],[3.2,4.3]]]},"properties":{"name":"a"}}`),
+			expected: geom.Polygon{{{3.2, 4.3}, {5.4, 6.5}, {7.6, 8.7}, {3.2, 4.3}}},
+		},
+		"multi polygon": {
+			data: []byte(`{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[10.1,10.1],[5.5,20.2],[7.7,30.3],[10.1,10.1]]],[[[75.5,75.5],[71.1,74.4],[71.1,71.1],[75.5,75.5]]]]},"properties":null}`),
+			expected: geom.MultiPolygon{
+				{{{10.1, 10.1}, {5.5, 20.2}, {7.7, 30.3}, {10.1, 10.1}}},
+				{{{75.5, 75.5}, {71.1, 74.4}, {71.1, 71.1}, {75.5, 75.5}}},
+			},
+		},
+		"geometry collection": {
+			data: []byte(`{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[12.2,17.7]},{"type":"LineString","coordinates":[[3.2,4.3],[5.4,6.5]]}]},"properties":null}`),
+			expected: geom.Collection{
+				geom.Point{12.2, 17.7},
+				geom.LineString{{3.2, 4.3}, {5.4, 6.5}},
+			},
+		},
+		"null geometry": {
+			data:     []byte(`{"type":"Feature","geometry":null,"properties":null}`),
+			expected: nil,
+		},
+		"unknown geometry type": {
+			data:        []byte(`{"type":"Feature","geometry":{"type":"Circle","coordinates":[12.2,17.7]},"properties":null}`),
+			expectedErr: geojson.ErrUnknownType{"Circle"},
+		},
+		"not a feature": {
+			data:        []byte(`{"type":"FeatureCollection","features":[]}`),
+			expectedErr: geojson.ErrUnknownType{"FeatureCollection"},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) { fn(t, tc) })
+	}
+}
+
+func TestFeatureCollectionUnmarshalJSON(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[12.2,17.7]},"properties":null},{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},"properties":null}]}`)
+	expected := []geom.Geometry{
+		geom.Point{12.2, 17.7},
+		geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+	}
+
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if len(fc.Features) != len(expected) {
+		t.Fatalf("expected %v features got %v", len(expected), len(fc.Features))
+	}
+
+	for i := range expected {
+		if !reflect.DeepEqual(expected[i], fc.Features[i].Geometry.Geometry) {
+			t.Errorf("feature %v: expected %v got %v", i, expected[i], fc.Features[i].Geometry.Geometry)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package wkt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-spatial/tegola/geom"
+)
+
+// Decode parses a WKT representation into a Geometry. Z and M values are discarded.
+// Empty geometries decode to an empty geometry of the type, and POINT EMPTY to a nil *geom.Point.
+func Decode(text string) (geo geom.Geometry, err error) {
+	d := decoder{text: text}
+
+	if geo, err = d.geometry(); err != nil {
+		return nil, err
+	}
+
+	d.skipSpace()
+	if !d.atEnd() {
+		return nil, d.errorf("unexpected %q after the geometry", d.text[d.pos:])
+	}
+
+	return geo, nil
+}
+
+type decoder struct {
+	text string
+	pos  int
+}
+
+func (d *decoder) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("wkt: "+format+" at position %v", append(a, d.pos)...)
+}
+
+func (d *decoder) atEnd() bool { return d.pos >= len(d.text) }
+
+func (d *decoder) skipSpace() {
+	for !d.atEnd() && strings.IndexByte(" \t\r\n", d.text[d.pos]) != -1 {
+		d.pos++
+	}
+}
+
+// peek returns the next non space byte, or 0 at the end of the text.
+func (d *decoder) peek() byte {
+	d.skipSpace()
+	if d.atEnd() {
+		return 0
+	}
+	return d.text[d.pos]
+}
+
+func (d *decoder) expect(c byte) error {
+	if d.peek() != c {
+		if d.atEnd() {
+			return d.errorf("expected %q found the end of the text", c)
+		}
+		return d.errorf("expected %q found %q", c, d.text[d.pos])
+	}
+	d.pos++
+	return nil
+}
+
+// word reads the next word in upper case, or "" if the next token is not a word.
+func (d *decoder) word() string {
+	d.skipSpace()
+	start := d.pos
+	for !d.atEnd() {
+		c := d.text[d.pos]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		d.pos++
+	}
+	return strings.ToUpper(d.text[start:d.pos])
+}
+
+// empty reads the optional dimension of the geometry and reports if the geometry is EMPTY.
+func (d *decoder) empty() (bool, error) {
+	start := d.pos
+	switch w := d.word(); w {
+	case "":
+		return false, nil
+	case "EMPTY":
+		return true, nil
+	case "Z", "M", "ZM":
+		return d.empty()
+	default:
+		d.pos = start
+		return false, d.errorf("unexpected %q", w)
+	}
+}
+
+func (d *decoder) number() (float64, error) {
+	d.skipSpace()
+	start := d.pos
+	for !d.atEnd() && strings.IndexByte("0123456789+-.eE", d.text[d.pos]) != -1 {
+		d.pos++
+	}
+	if start == d.pos {
+		return 0, d.errorf("expected a number")
+	}
+
+	f, err := strconv.ParseFloat(d.text[start:d.pos], 64)
+	if err != nil {
+		d.pos = start
+		return 0, d.errorf("invalid number %q", d.text[start:d.pos])
+	}
+	return f, nil
+}
+
+// point reads the x and y of a point, discarding any z and m values.
+func (d *decoder) point() (pt [2]float64, err error) {
+	if pt[0], err = d.number(); err != nil {
+		return pt, err
+	}
+	if pt[1], err = d.number(); err != nil {
+		return pt, err
+	}
+	for i := 0; i < 2; i++ {
+		if c := d.peek(); c == ',' || c == ')' {
+			break
+		}
+		if _, err = d.number(); err != nil {
+			return pt, err
+		}
+	}
+	return pt, nil
+}
+
+// list reads a parenthesized, comma separated list calling item for each item.
+func (d *decoder) list(item func() error) error {
+	if err := d.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if d.peek() != ',' {
+			break
+		}
+		d.pos++
+	}
+	return d.expect(')')
+}
+
+func (d *decoder) points() (pts [][2]float64, err error) {
+	err = d.list(func() error {
+		pt, err := d.point()
+		pts = append(pts, pt)
+		return err
+	})
+	return pts, err
+}
+
+func (d *decoder) rings() (rings [][][2]float64, err error) {
+	err = d.list(func() error {
+		pts, err := d.points()
+		rings = append(rings, pts)
+		return err
+	})
+	return rings, err
+}
+
+func (d *decoder) geometry() (geom.Geometry, error) {
+	typ := d.word()
+	if typ == "" {
+		return nil, d.errorf("expected a geometry type")
+	}
+
+	empty, err := d.empty()
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case "POINT":
+		if empty {
+			return (*geom.Point)(nil), nil
+		}
+		var pt geom.Point
+		err = d.list(func() error {
+			var err error
+			pt, err = d.point()
+			return err
+		})
+		return pt, err
+
+	case "MULTIPOINT":
+		mp := geom.MultiPoint{}
+		if empty {
+			return mp, nil
+		}
+		//	the points can be parenthesized, i.e. MULTIPOINT ((10 40), (40 30))
+		err = d.list(func() error {
+			var pt [2]float64
+			var err error
+			if d.peek() == '(' {
+				err = d.list(func() error {
+					var err error
+					pt, err = d.point()
+					return err
+				})
+			} else {
+				pt, err = d.point()
+			}
+			mp = append(mp, pt)
+			return err
+		})
+		return mp, err
+
+	case "LINESTRING":
+		if empty {
+			return geom.LineString{}, nil
+		}
+		pts, err := d.points()
+		return geom.LineString(pts), err
+
+	case "MULTILINESTRING", "MULTILINE":
+		if empty {
+			return geom.MultiLineString{}, nil
+		}
+		lns, err := d.rings()
+		return geom.MultiLineString(lns), err
+
+	case "POLYGON":
+		if empty {
+			return geom.Polygon{}, nil
+		}
+		rings, err := d.rings()
+		return geom.Polygon(rings), err
+
+	case "MULTIPOLYGON":
+		mp := geom.MultiPolygon{}
+		if empty {
+			return mp, nil
+		}
+		err = d.list(func() error {
+			rings, err := d.rings()
+			mp = append(mp, rings)
+			return err
+		})
+		return mp, err
+
+	case "GEOMETRYCOLLECTION":
+		col := geom.Collection{}
+		if empty {
+			return col, nil
+		}
+		err = d.list(func() error {
+			g, err := d.geometry()
+			col = append(col, g)
+			return err
+		})
+		return col, err
+
+	default:
+		return nil, d.errorf("unknown geometry type %q", typ)
+	}
+}
@@ -0,0 +1,171 @@
+package wkt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola/geom"
+)
+
+func TestDecode(t *testing.T) {
+	type tcase struct {
+		Rep    string
+		Geom   geom.Geometry
+		HasErr bool
+	}
+	fn := func(t *testing.T, tc tcase) {
+		t.Parallel()
+		geo, err := Decode(tc.Rep)
+		if tc.HasErr {
+			if err == nil {
+				t.Errorf("error, expected an error got nil")
+			}
+			return
+		}
+		if err != nil {
+			t.Errorf("error, expected nil got %v", err)
+			return
+		}
+		if !reflect.DeepEqual(tc.Geom, geo) {
+			t.Errorf("geometry, expected %#v got %#v", tc.Geom, geo)
+		}
+	}
+	tests := map[string]map[string]tcase{
+		"Point": map[string]tcase{
+			"empty": tcase{
+				Rep:  "POINT EMPTY",
+				Geom: (*geom.Point)(nil),
+			},
+			"one": tcase{
+				Rep:  "POINT (10 0)",
+				Geom: geom.Point{10, 0},
+			},
+			"lower case": tcase{
+				Rep:  "point(10.5 -2e3)",
+				Geom: geom.Point{10.5, -2000},
+			},
+			"zm": tcase{
+				Rep:  "POINT ZM (1 2 3 4)",
+				Geom: geom.Point{1, 2},
+			},
+			"missing y": tcase{
+				Rep:    "POINT (1)",
+				HasErr: true,
+			},
+		},
+		"MultiPoint": map[string]tcase{
+			"empty": tcase{
+				Rep:  "MULTIPOINT EMPTY",
+				Geom: geom.MultiPoint{},
+			},
+			"two": tcase{
+				Rep:  "MULTIPOINT (10 40, 40 30)",
+				Geom: geom.MultiPoint{{10, 40}, {40, 30}},
+			},
+			"parenthesized": tcase{
+				Rep:  "MULTIPOINT ((10 40), (40 30))",
+				Geom: geom.MultiPoint{{10, 40}, {40, 30}},
+			},
+		},
+		"LineString": map[string]tcase{
+			"empty": tcase{
+				Rep:  "LINESTRING EMPTY",
+				Geom: geom.LineString{},
+			},
+			"two": tcase{
+				Rep:  "LINESTRING (30 10, 10 30)",
+				Geom: geom.LineString{{30, 10}, {10, 30}},
+			},
+			"unclosed": tcase{
+				Rep:    "LINESTRING (30 10, 10 30",
+				HasErr: true,
+			},
+		},
+		"MultiLineString": map[string]tcase{
+			"two": tcase{
+				Rep:  "MULTILINESTRING ((10 10, 20 20), (40 40, 30 30))",
+				Geom: geom.MultiLineString{{{10, 10}, {20, 20}}, {{40, 40}, {30, 30}}},
+			},
+		},
+		"Polygon": map[string]tcase{
+			"empty": tcase{
+				Rep:  "POLYGON EMPTY",
+				Geom: geom.Polygon{},
+			},
+			"with hole": tcase{
+				Rep: "POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10),\n(20 30, 35 35, 30 20, 20 30))",
+				Geom: geom.Polygon{
+					{{35, 10}, {45, 45}, {15, 40}, {10, 20}, {35, 10}},
+					{{20, 30}, {35, 35}, {30, 20}, {20, 30}},
+				},
+			},
+		},
+		"MultiPolygon": map[string]tcase{
+			"two": tcase{
+				Rep: "MULTIPOLYGON (((30 20, 45 40, 10 40, 30 20)), ((15 5, 40 10, 10 20, 5 10, 15 5)))",
+				Geom: geom.MultiPolygon{
+					{{{30, 20}, {45, 40}, {10, 40}, {30, 20}}},
+					{{{15, 5}, {40, 10}, {10, 20}, {5, 10}, {15, 5}}},
+				},
+			},
+		},
+		"Collection": map[string]tcase{
+			"point and linestring": tcase{
+				Rep: "GEOMETRYCOLLECTION (POINT (40 10), LINESTRING (10 10, 20 20))",
+				Geom: geom.Collection{
+					geom.Point{40, 10},
+					geom.LineString{{10, 10}, {20, 20}},
+				},
+			},
+		},
+		"Invalid": map[string]tcase{
+			"unknown type": tcase{
+				Rep:    "CIRCLE (1 2)",
+				HasErr: true,
+			},
+			"trailing text": tcase{
+				Rep:    "POINT (1 2) POINT (3 4)",
+				HasErr: true,
+			},
+			"no type": tcase{
+				Rep:    "(1 2)",
+				HasErr: true,
+			},
+		},
+	}
+	for name, subtests := range tests {
+		subtests := subtests
+		t.Run(name, func(t *testing.T) {
+			for subname, tc := range subtests {
+				tc := tc
+				t.Run(subname, func(t *testing.T) { fn(t, tc) })
+			}
+		})
+	}
+}
+
+func TestDecodeEncoded(t *testing.T) {
+	geos := []geom.Geometry{
+		geom.Point{1.5, 2},
+		geom.MultiPoint{{1, 2}, {3, 4}},
+		geom.LineString{{1, 2}, {3, 4}},
+		geom.MultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+		geom.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		geom.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+	}
+	for _, g := range geos {
+		rep, err := Encode(g)
+		if err != nil {
+			t.Errorf("encode %v: %v", g, err)
+			continue
+		}
+		geo, err := Decode(rep)
+		if err != nil {
+			t.Errorf("decode %v: %v", rep, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, geo) {
+			t.Errorf("%v, expected %#v got %#v", rep, g, geo)
+		}
+	}
+}
@@ -226,7 +226,3 @@ func Encode(geo geom.Geometry) (string, error) {
 		return "GEOMETRYCOLLECTION (" + strings.Join(geometries, ",") + ")", nil
 	}
 }
-
-func Decode(text string) (geo geom.Geometry, err error) {
-	return nil, nil
-}
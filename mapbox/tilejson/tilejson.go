@@ -102,6 +102,9 @@ type TileJSON struct {
 	// For security reasons, make absolutely sure that this field can't be
 	// abused as a vector for XSS or beacon tracking.
 	Legend *string `json:"legend"`
+	//	the size in pixels tiles are intended to be rendered at. This is not part of the
+	//	tileJSON spec but is read by clients such as Mapbox GL
+	TileSize int `json:"tileSize,omitempty"`
 	//	vector layer details. This is not part of the tileJSON spec
 	//	properties mimiced based on other vector provider implementations
 	VectorLayers []VectorLayer `json:"vector_layers"`
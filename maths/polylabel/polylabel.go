@@ -0,0 +1,180 @@
+/*
+Package polylabel finds the pole of inaccessibility of a polygon: the interior point furthest from
+the polygon's outline. Unlike the centroid, the pole of inaccessibility is always inside the polygon,
+which makes it well suited for placing labels on concave polygons.
+
+The implementation follows the iterative grid algorithm described at https://github.com/mapbox/polylabel
+*/
+package polylabel
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/go-spatial/tegola/maths"
+)
+
+// cell is a square cell of the search grid
+type cell struct {
+	center maths.Pt
+	// half the cell size
+	half float64
+	// distance from the cell center to the polygon outline. negative when outside
+	dist float64
+	// the max distance to the polygon within the cell
+	max float64
+}
+
+func newCell(x, y, half float64, rings [][]maths.Pt) *cell {
+	c := &cell{
+		center: maths.Pt{X: x, Y: y},
+		half:   half,
+	}
+	c.dist = pointToPolygonDist(c.center, rings)
+	c.max = c.dist + c.half*math.Sqrt2
+
+	return c
+}
+
+// cellQueue is a max heap of cells ordered by their max distance
+type cellQueue []*cell
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].max > q[j].max }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(*cell)) }
+func (q *cellQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// Polylabel returns the pole of inaccessibility of the polygon made up of rings. The first ring is
+// the exterior ring and the rest are holes. precision is the distance, in the units of the rings,
+// the result is allowed to be from the true pole.
+func Polylabel(rings [][]maths.Pt, precision float64) maths.Pt {
+	if len(rings) == 0 || len(rings[0]) == 0 {
+		return maths.Pt{}
+	}
+
+	// bounding box of the exterior ring
+	minX, minY := rings[0][0].X, rings[0][0].Y
+	maxX, maxY := minX, minY
+	for _, pt := range rings[0][1:] {
+		minX, maxX = math.Min(minX, pt.X), math.Max(maxX, pt.X)
+		minY, maxY = math.Min(minY, pt.Y), math.Max(maxY, pt.Y)
+	}
+
+	width, height := maxX-minX, maxY-minY
+	cellSize := math.Min(width, height)
+	if cellSize == 0 {
+		return maths.Pt{X: minX, Y: minY}
+	}
+	half := cellSize / 2
+
+	// cover the polygon with the initial cells
+	var q cellQueue
+	for x := minX; x < maxX; x += cellSize {
+		for y := minY; y < maxY; y += cellSize {
+			q = append(q, newCell(x+half, y+half, half, rings))
+		}
+	}
+	heap.Init(&q)
+
+	// the first best guess is the centroid of the exterior ring
+	best := centroidCell(rings)
+
+	// the center of the bounding box can be a better guess for rectangular shapes
+	if bbox := newCell(minX+width/2, minY+height/2, 0, rings); bbox.dist > best.dist {
+		best = bbox
+	}
+
+	for q.Len() > 0 {
+		c := heap.Pop(&q).(*cell)
+
+		if c.dist > best.dist {
+			best = c
+		}
+
+		// this cell can not contain a better solution
+		if c.max-best.dist <= precision {
+			continue
+		}
+
+		// split the cell into four
+		h := c.half / 2
+		heap.Push(&q, newCell(c.center.X-h, c.center.Y-h, h, rings))
+		heap.Push(&q, newCell(c.center.X+h, c.center.Y-h, h, rings))
+		heap.Push(&q, newCell(c.center.X-h, c.center.Y+h, h, rings))
+		heap.Push(&q, newCell(c.center.X+h, c.center.Y+h, h, rings))
+	}
+
+	return best.center
+}
+
+// centroidCell returns a cell at the centroid of the exterior ring
+func centroidCell(rings [][]maths.Pt) *cell {
+	ring := rings[0]
+
+	var area, x, y float64
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		f := a.X*b.Y - b.X*a.Y
+		x += (a.X + b.X) * f
+		y += (a.Y + b.Y) * f
+		area += f * 3
+	}
+
+	if area == 0 {
+		return newCell(ring[0].X, ring[0].Y, 0, rings)
+	}
+
+	return newCell(x/area, y/area, 0, rings)
+}
+
+// pointToPolygonDist returns the signed distance from the point to the polygon outline.
+// the distance is negative when the point is outside of the polygon
+func pointToPolygonDist(pt maths.Pt, rings [][]maths.Pt) float64 {
+	inside := false
+	minDistSq := math.Inf(1)
+
+	for _, ring := range rings {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+
+			if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+				inside = !inside
+			}
+
+			minDistSq = math.Min(minDistSq, segDistSq(pt, a, b))
+		}
+	}
+
+	dist := math.Sqrt(minDistSq)
+	if !inside {
+		return -dist
+	}
+
+	return dist
+}
+
+// segDistSq returns the squared distance from the point to the segment a, b
+func segDistSq(pt, a, b maths.Pt) float64 {
+	x, y := a.X, a.Y
+	dx, dy := b.X-x, b.Y-y
+
+	if dx != 0 || dy != 0 {
+		t := ((pt.X-x)*dx + (pt.Y-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b.X, b.Y
+		} else if t > 0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+
+	dx, dy = pt.X-x, pt.Y-y
+
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,55 @@
+package polylabel
+
+import (
+	"testing"
+
+	"github.com/go-spatial/tegola/maths"
+)
+
+func TestPolylabel(t *testing.T) {
+	testcases := []struct {
+		rings     [][]maths.Pt
+		precision float64
+		//	the distance from the pole to the polygon outline
+		expected float64
+	}{
+		{
+			//	square
+			rings:     [][]maths.Pt{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+			precision: 0.1,
+			expected:  5,
+		},
+		{
+			//	square with a hole off center. the label moves to the widest part of the ring
+			rings: [][]maths.Pt{
+				{{0, 0}, {20, 0}, {20, 20}, {0, 20}},
+				{{2, 2}, {18, 2}, {18, 8}, {2, 8}},
+			},
+			precision: 0.1,
+			expected:  6,
+		},
+		{
+			//	U shape. the centroid is outside of the polygon
+			rings: [][]maths.Pt{
+				{{0, 0}, {30, 0}, {30, 30}, {20, 30}, {20, 10}, {10, 10}, {10, 30}, {0, 30}},
+			},
+			precision: 0.1,
+			expected:  5,
+		},
+		{
+			//	degenerate polygon
+			rings:     [][]maths.Pt{{{0, 0}, {10, 0}, {20, 0}}},
+			precision: 0.1,
+			expected:  0,
+		},
+	}
+
+	for i, tc := range testcases {
+		output := Polylabel(tc.rings, tc.precision)
+
+		dist := pointToPolygonDist(output, tc.rings)
+		if dist < tc.expected-tc.precision {
+			t.Errorf("[%v] distance to outline, expected %v got %v (%v)", i, tc.expected, dist, output)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package maths
+
+import (
+	"container/heap"
+	"math"
+)
+
+//https://en.wikipedia.org/wiki/Visvalingam%E2%80%93Whyatt_algorithm
+
+// vwPoint is a point of the line being simplified, linked to its current neighbors
+type vwPoint struct {
+	idx        int
+	area       float64
+	prev, next *vwPoint
+	// position in the heap. -1 once removed
+	heapIdx int
+}
+
+type vwHeap []*vwPoint
+
+func (h vwHeap) Len() int { return len(h) }
+func (h vwHeap) Less(i, j int) bool {
+	if h[i].area == h[j].area {
+		// break ties by position so the result is deterministic
+		return h[i].idx < h[j].idx
+	}
+	return h[i].area < h[j].area
+}
+func (h vwHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIdx = i
+	h[j].heapIdx = j
+}
+func (h *vwHeap) Push(x interface{}) {
+	p := x.(*vwPoint)
+	p.heapIdx = len(*h)
+	*h = append(*h, p)
+}
+func (h *vwHeap) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	p.heapIdx = -1
+	*h = old[:len(old)-1]
+	return p
+}
+
+// Visvalingam simplifies the line by repeatedly removing the point which forms the triangle with the
+// smallest area with its neighbors, until every remaining triangle has an area of at least minArea.
+// The end points of the line are always kept.
+func Visvalingam(points []Pt, minArea float64) []Pt {
+	if minArea <= 0 || len(points) <= 2 {
+		return points
+	}
+
+	pts := make([]*vwPoint, len(points))
+	for i := range points {
+		pts[i] = &vwPoint{idx: i, heapIdx: -1}
+	}
+	for i := range pts {
+		if i > 0 {
+			pts[i].prev = pts[i-1]
+		}
+		if i < len(pts)-1 {
+			pts[i].next = pts[i+1]
+		}
+	}
+
+	area := func(p *vwPoint) float64 {
+		return math.Abs(AreaOfTriangle(points[p.prev.idx], points[p.idx], points[p.next.idx]))
+	}
+
+	var h vwHeap
+	for _, p := range pts[1 : len(pts)-1] {
+		p.area = area(p)
+		heap.Push(&h, p)
+	}
+
+	var maxArea float64
+	for h.Len() > 0 {
+		p := heap.Pop(&h).(*vwPoint)
+
+		// the area of a point can't be less than the area of a point removed before it,
+		// otherwise the point would be removed before the point it neighbored
+		if p.area < maxArea {
+			p.area = maxArea
+		} else {
+			maxArea = p.area
+		}
+
+		if p.area >= minArea {
+			break
+		}
+
+		// unlink the point and update its neighbors
+		p.prev.next = p.next
+		p.next.prev = p.prev
+		for _, n := range []*vwPoint{p.prev, p.next} {
+			if n.prev == nil || n.next == nil || n.heapIdx < 0 {
+				continue
+			}
+			n.area = area(n)
+			heap.Fix(&h, n.heapIdx)
+		}
+		p.prev, p.next = nil, nil
+	}
+
+	simplified := make([]Pt, 0, len(points))
+	for p := pts[0]; p != nil; p = p.next {
+		simplified = append(simplified, points[p.idx])
+	}
+
+	return simplified
+}
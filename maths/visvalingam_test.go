@@ -0,0 +1,46 @@
+package maths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVisvalingam(t *testing.T) {
+	testcases := []struct {
+		line     []Pt
+		minArea  float64
+		expected []Pt
+	}{
+		{
+			//	nothing to simplify
+			line:     []Pt{{0, 0}, {10, 10}},
+			minArea:  10,
+			expected: []Pt{{0, 0}, {10, 10}},
+		},
+		{
+			//	the small bump is removed, the large one is kept
+			line:     []Pt{{0, 0}, {5, 1}, {10, 0}, {15, 10}, {20, 0}},
+			minArea:  10,
+			expected: []Pt{{0, 0}, {10, 0}, {15, 10}, {20, 0}},
+		},
+		{
+			//	collinear points are removed
+			line:     []Pt{{0, 0}, {1, 0}, {2, 0}, {3, 0}},
+			minArea:  0.5,
+			expected: []Pt{{0, 0}, {3, 0}},
+		},
+		{
+			//	no tolerance
+			line:     []Pt{{0, 0}, {5, 1}, {10, 0}},
+			minArea:  0,
+			expected: []Pt{{0, 0}, {5, 1}, {10, 0}},
+		},
+	}
+
+	for i, tc := range testcases {
+		output := Visvalingam(tc.line, tc.minArea)
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, output)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package mvt
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/geom"
+	"github.com/go-spatial/tegola/internal/convert"
+	"github.com/go-spatial/tegola/maths"
+	"github.com/go-spatial/tegola/mvt/vector_tile"
+)
+
+// ptsPool holds the scratch buffers lines are scaled into before they are simplified and clipped
+var ptsPool = sync.Pool{
+	New: func() interface{} {
+		pts := make([]maths.Pt, 0, 256)
+		return &pts
+	},
+}
+
+// isGeom reports whether the geometry is one of the geom package types the layer can prepare natively,
+// without converting it to the legacy tegola types first.
+func isGeom(g tegola.Geometry) bool {
+	switch g.(type) {
+	case geom.Point, geom.MultiPoint, geom.LineString, geom.MultiLineString, geom.Polygon, geom.MultiPolygon:
+		return true
+	default:
+		return false
+	}
+}
+
+// toLegacyGeometry converts geom package geometries to the legacy tegola types. Other geometries are returned as is.
+func toLegacyGeometry(g tegola.Geometry) (tegola.Geometry, error) {
+	switch g.(type) {
+	case geom.Point, geom.MultiPoint, geom.LineString, geom.MultiLineString, geom.Polygon, geom.MultiPolygon, geom.Collection:
+		return convert.ToTegola(g)
+	default:
+		return g, nil
+	}
+}
+
+// prepareGeom scales a geom package geometry into the tile's pixel space, simplifies and clips it like
+// prepareFeatures does for the legacy types. Points and lines never leave the geom types. Polygons are scaled
+// straight into the basic types and then take the legacy path: they're simplified, clipped and made valid
+// by the basic and makevalid packages, so the native encoding only saves polygons the conversion.
+func (l *Layer) prepareGeom(ctx context.Context, tile *tegola.Tile, f Feature, s simplifier, simplify bool) (tegola.Geometry, error) {
+	switch g := f.Geometry.(type) {
+	case geom.Point:
+		return geom.Point(scaleGeomPt(tile, g)), nil
+
+	case geom.MultiPoint:
+		seen := make(map[[2]float64]struct{}, len(g))
+		mp := make(geom.MultiPoint, 0, len(g))
+		for _, pt := range g {
+			spt := scaleGeomPt(tile, pt)
+			// skip duplicate points
+			if _, ok := seen[spt]; ok {
+				continue
+			}
+			seen[spt] = struct{}{}
+			mp = append(mp, spt)
+		}
+		if len(mp) == 0 {
+			return nil, nil
+		}
+		return mp, nil
+
+	case geom.LineString:
+		return l.prepareGeomLines(tile, [][][2]float64{g}, simplify)
+
+	case geom.MultiLineString:
+		return l.prepareGeomLines(tile, g, simplify)
+
+	case geom.Polygon:
+		sg := s.simplifyGeometry(scaleGeomPolygon(tile, g), tile.ZEpislon(), simplify)
+		return l.validateGeometry(ctx, tile, f, sg)
+
+	case geom.MultiPolygon:
+		var mp basic.MultiPolygon
+		for _, p := range g {
+			if sp := scaleGeomPolygon(tile, p); len(sp) > 0 {
+				mp = append(mp, sp)
+			}
+		}
+		sg := s.simplifyGeometry(mp, tile.ZEpislon(), simplify)
+		return l.validateGeometry(ctx, tile, f, sg)
+
+	default:
+		return nil, ErrUnknownGeometryType
+	}
+}
+
+// prepareGeomLines scales, simplifies and clips the lines to the tile. The lines are scaled into a pooled
+// buffer so the only allocations are the simplified points and the clipped lines.
+func (l *Layer) prepareGeomLines(tile *tegola.Tile, lines [][][2]float64, simplify bool) (tegola.Geometry, error) {
+	pbb, err := tile.PixelBufferedBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := ptsPool.Get().(*[]maths.Pt)
+	defer ptsPool.Put(buf)
+
+	var ml geom.MultiLineString
+	for _, line := range lines {
+		pts := (*buf)[:0]
+		for _, pt := range line {
+			spt := scaleGeomPt(tile, pt)
+			p := maths.Pt{X: spt[0], Y: spt[1]}
+			// drop any duplicate points
+			if len(pts) > 0 && pts[len(pts)-1] == p {
+				continue
+			}
+			pts = append(pts, p)
+		}
+		// keep the grown buffer for the next line
+		*buf = pts
+
+		// not enough points to make a line. the zoom must be too far out for this line
+		if len(pts) < 2 {
+			continue
+		}
+
+		if simplify {
+			pts = simplifyPts(pts, tile.ZEpislon())
+		}
+
+		if l.Validation == ValidationNone {
+			ln := make([][2]float64, len(pts))
+			for i := range pts {
+				ln[i] = [2]float64{pts[i].X, pts[i].Y}
+			}
+			ml = append(ml, ln)
+			continue
+		}
+
+		ml = clipLine(pts, pbb, ml)
+	}
+
+	if len(ml) == 0 {
+		return nil, nil
+	}
+
+	return ml, nil
+}
+
+// scaleGeomPt projects the point into the tile's pixel space
+func scaleGeomPt(tile *tegola.Tile, pt [2]float64) [2]float64 {
+	spt, err := tile.ToPixel(tegola.WebMercator, pt)
+	if err != nil {
+		panic(err)
+	}
+	return spt
+}
+
+// scaleGeomPolygon projects the rings of the polygon into the tile's pixel space dropping duplicate points.
+// Rings reduced to less than 2 points are skipped.
+func scaleGeomPolygon(tile *tegola.Tile, p [][][2]float64) basic.Polygon {
+	plg := make(basic.Polygon, 0, len(p))
+	for _, ring := range p {
+		ln := make(basic.Line, 0, len(ring))
+		for _, pt := range ring {
+			spt := basic.Point(scaleGeomPt(tile, pt))
+			if len(ln) > 0 && ln[len(ln)-1] == spt {
+				continue
+			}
+			ln = append(ln, spt)
+		}
+		if len(ln) < 2 {
+			continue
+		}
+		plg = append(plg, ln)
+	}
+
+	return plg
+}
+
+// simplifyPts simplifies the points of a line like simplifyLineString
+func simplifyPts(pts []maths.Pt, tolerance float64) []maths.Pt {
+	if len(pts) <= 4 {
+		return pts
+	}
+
+	// the same (manhattan) distance as maths.DistOfLine
+	var dist float64
+	for i := 1; i < len(pts); i++ {
+		dist += math.Abs(pts[i].X-pts[i-1].X) + math.Abs(pts[i].Y-pts[i-1].Y)
+	}
+	if dist < tolerance {
+		return pts
+	}
+
+	return maths.DouglasPeucker(pts, tolerance, true)
+}
+
+// clipLine clips the line to the bounds and appends the parts of the line within the bounds to out
+func clipLine(pts []maths.Pt, bounds [2][2]float64, out geom.MultiLineString) geom.MultiLineString {
+	var cur [][2]float64
+
+	flush := func() {
+		if len(cur) > 2 || (len(cur) == 2 && cur[0] != cur[1]) {
+			out = append(out, cur)
+		}
+		cur = nil
+	}
+
+	for i := 0; i+1 < len(pts); i++ {
+		a, b := pts[i], pts[i+1]
+
+		t0, t1, ok := clipSegment(a, b, bounds)
+		if !ok {
+			flush()
+			continue
+		}
+
+		if len(cur) == 0 {
+			cur = append(cur, clipPoint(a, b, t0, bounds))
+		}
+		cur = append(cur, clipPoint(a, b, t1, bounds))
+
+		// the line leaves the bounds
+		if t1 < 1 {
+			flush()
+		}
+	}
+	flush()
+
+	return out
+}
+
+// clipSegment clips the segment from a to b to the bounds using Liang–Barsky. The part of the segment
+// within the bounds runs from t0 to t1, as fractions of the segment.
+func clipSegment(a, b maths.Pt, bounds [2][2]float64) (t0, t1 float64, ok bool) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+
+	t0, t1 = 0, 1
+	for _, e := range [4][2]float64{
+		{-dx, a.X - bounds[0][0]},
+		{dx, bounds[1][0] - a.X},
+		{-dy, a.Y - bounds[0][1]},
+		{dy, bounds[1][1] - a.Y},
+	} {
+		p, q := e[0], e[1]
+		if p == 0 {
+			// parallel to the edge and outside of it
+			if q < 0 {
+				return 0, 0, false
+			}
+			continue
+		}
+
+		r := q / p
+		if p < 0 {
+			if r > t1 {
+				return 0, 0, false
+			}
+			if r > t0 {
+				t0 = r
+			}
+		} else {
+			if r < t0 {
+				return 0, 0, false
+			}
+			if r < t1 {
+				t1 = r
+			}
+		}
+	}
+
+	return t0, t1, true
+}
+
+// clipPoint returns the point at t along the segment from a to b. Points where the segment crosses the bounds
+// are put exactly on the edge: the rounding of the interpolation can leave them a hair inside the bounds,
+// which the truncation of the encoding turns into a pixel.
+func clipPoint(a, b maths.Pt, t float64, bounds [2][2]float64) [2]float64 {
+	pt := lerp(a, b, t)
+	if t == 0 || t == 1 {
+		return pt
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, edge := range [2]float64{bounds[0][i], bounds[1][i]} {
+			if math.Abs(pt[i]-edge) < 1e-6 {
+				pt[i] = edge
+			}
+		}
+	}
+
+	return pt
+}
+
+func lerp(a, b maths.Pt, t float64) [2]float64 {
+	switch t {
+	case 0:
+		return [2]float64{a.X, a.Y}
+	case 1:
+		return [2]float64{b.X, b.Y}
+	}
+	return [2]float64{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+}
+
+// encodeGeom encodes the points and lines prepared by prepareGeom into a single, exactly sized, command buffer.
+// false is returned for other geometries.
+func encodeGeom(g tegola.Geometry) ([]uint32, vectorTile.Tile_GeomType, bool) {
+	switch t := g.(type) {
+	case geom.Point:
+		x, y := int64(t[0]), int64(t[1])
+		return []uint32{uint32(NewCommand(cmdMoveTo, 1)), encodeZigZag(x), encodeZigZag(y)}, vectorTile.Tile_POINT, true
+
+	case geom.MultiPoint:
+		if len(t) == 0 {
+			return nil, vectorTile.Tile_POINT, true
+		}
+		c := geomCursor{g: make([]uint32, 0, 1+2*len(t))}
+		c.g = append(c.g, uint32(NewCommand(cmdMoveTo, len(t))))
+		c.deltas(t)
+		return c.g, vectorTile.Tile_POINT, true
+
+	case geom.MultiLineString:
+		var n int
+		for _, ln := range t {
+			if len(ln) >= 2 {
+				// a MoveTo with one point and a LineTo with the rest
+				n += 2 + 2*len(ln)
+			}
+		}
+
+		c := geomCursor{g: make([]uint32, 0, n)}
+		for _, ln := range t {
+			if len(ln) < 2 {
+				continue
+			}
+			c.g = append(c.g, uint32(NewCommand(cmdMoveTo, 1)))
+			c.deltas(ln[:1])
+			c.g = append(c.g, uint32(NewCommand(cmdLineTo, len(ln)-1)))
+			c.deltas(ln[1:])
+		}
+		return c.g, vectorTile.Tile_LINESTRING, true
+
+	default:
+		return nil, vectorTile.Tile_UNKNOWN, false
+	}
+}
+
+// geomCursor encodes the points of geom geometries, which are already in pixel space, as deltas
+type geomCursor struct {
+	x, y int64
+	g    []uint32
+}
+
+func (c *geomCursor) deltas(pts [][2]float64) {
+	for _, pt := range pts {
+		x, y := int64(pt[0]), int64(pt[1])
+		c.g = append(c.g, encodeZigZag(x-c.x), encodeZigZag(y-c.y))
+		c.x, c.y = x, y
+	}
+}
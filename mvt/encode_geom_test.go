@@ -0,0 +1,270 @@
+package mvt
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/geom"
+	"github.com/go-spatial/tegola/internal/convert"
+	"github.com/go-spatial/tegola/maths"
+)
+
+// geomFeatures builds features in web mercator from geometries in the pixel space of the tile
+func geomFeatures(t testing.TB, tile *tegola.Tile, geos ...geom.Geometry) []Feature {
+	toWM := func(pt [2]float64) [2]float64 {
+		wm, err := tile.FromPixel(tegola.WebMercator, pt)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// nudge the point into the pixel so truncation lands on the same pixel
+		return [2]float64{wm[0] + 1, wm[1] + 1}
+	}
+	ring := func(pts [][2]float64) [][2]float64 {
+		r := make([][2]float64, len(pts))
+		for i := range pts {
+			r[i] = toWM(pts[i])
+		}
+		return r
+	}
+
+	features := make([]Feature, len(geos))
+	for i, g := range geos {
+		var wm geom.Geometry
+		switch geo := g.(type) {
+		case geom.Point:
+			wm = geom.Point(toWM(geo))
+		case geom.MultiPoint:
+			wm = geom.MultiPoint(ring(geo))
+		case geom.LineString:
+			wm = geom.LineString(ring(geo))
+		case geom.MultiLineString:
+			var ml geom.MultiLineString
+			for _, ln := range geo {
+				ml = append(ml, ring(ln))
+			}
+			wm = ml
+		case geom.Polygon:
+			var p geom.Polygon
+			for _, r := range geo {
+				p = append(p, ring(r))
+			}
+			wm = p
+		case geom.MultiPolygon:
+			var mp geom.MultiPolygon
+			for _, poly := range geo {
+				var p geom.Polygon
+				for _, r := range poly {
+					p = append(p, ring(r))
+				}
+				mp = append(mp, p)
+			}
+			wm = mp
+		}
+
+		id := uint64(i)
+		features[i] = Feature{
+			ID:       &id,
+			Tags:     map[string]interface{}{"i": i},
+			Geometry: wm,
+		}
+	}
+
+	return features
+}
+
+func legacyFeatures(t testing.TB, features []Feature) []Feature {
+	legacy := make([]Feature, len(features))
+	for i, f := range features {
+		g, err := convert.ToTegola(f.Geometry)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f.Geometry = g
+		legacy[i] = f
+	}
+
+	return legacy
+}
+
+func TestLayerGeomEncoding(t *testing.T) {
+	tile := tegola.NewTile(2, 1, 1)
+
+	features := geomFeatures(t, tile,
+		geom.Point{10, 20},
+		geom.MultiPoint{{10, 20}, {30, 40}, {10, 20}},
+		geom.LineString{{10, 10}, {100, 10}, {100, 100}, {200, 300}, {400, 50}, {500, 500}},
+		geom.MultiLineString{{{10, 10}, {20, 20}}, {{30, 30}, {40, 60}, {80, 30}}},
+		geom.Polygon{
+			{{100, 100}, {1000, 100}, {1000, 1000}, {100, 1000}},
+			{{200, 200}, {200, 400}, {400, 400}, {400, 200}},
+		},
+		// clipped to the tile
+		geom.LineString{{-500, 100}, {2000, 2000}, {5000, 3000}},
+		geom.Polygon{{{-300, -300}, {5000, -300}, {5000, 2000}, {-300, 2000}}},
+		geom.MultiPolygon{
+			{{{3000, 3000}, {4500, 3000}, {4500, 4500}, {3000, 4500}}},
+			{{{100, 3000}, {500, 3000}, {500, 3500}, {100, 3500}}},
+		},
+	)
+
+	for _, simplify := range []bool{true, false} {
+		native := Layer{Name: "native", DontSimplify: !simplify}
+		native.AddFeatures(features...)
+		legacy := Layer{Name: "native", DontSimplify: !simplify}
+		legacy.AddFeatures(legacyFeatures(t, features)...)
+
+		got, err := native.VTileLayer(context.Background(), tile)
+		if err != nil {
+			t.Fatalf("[simplify %v] unexpected error: %v", simplify, err)
+		}
+		expected, err := legacy.VTileLayer(context.Background(), tile)
+		if err != nil {
+			t.Fatalf("[simplify %v] unexpected error: %v", simplify, err)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("[simplify %v] encoded layer\nexpected %v\n     got %v", simplify, expected, got)
+		}
+	}
+}
+
+func TestClipLine(t *testing.T) {
+	bounds := [2][2]float64{{0, 0}, {10, 10}}
+
+	testcases := []struct {
+		pts      []maths.Pt
+		expected geom.MultiLineString
+	}{
+		{
+			// within the bounds
+			pts:      []maths.Pt{{1, 1}, {5, 5}, {9, 1}},
+			expected: geom.MultiLineString{{{1, 1}, {5, 5}, {9, 1}}},
+		},
+		{
+			// leaves and re-enters the bounds
+			pts:      []maths.Pt{{5, 5}, {15, 5}, {15, 8}, {5, 8}},
+			expected: geom.MultiLineString{{{5, 5}, {10, 5}}, {{10, 8}, {5, 8}}},
+		},
+		{
+			// crosses the bounds
+			pts:      []maths.Pt{{-5, 5}, {15, 5}},
+			expected: geom.MultiLineString{{{0, 5}, {10, 5}}},
+		},
+		{
+			// outside of the bounds
+			pts:      []maths.Pt{{-5, -5}, {-5, 15}},
+			expected: nil,
+		},
+	}
+
+	for i, tc := range testcases {
+		got := clipLine(tc.pts, bounds, nil)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestClipLineEdge(t *testing.T) {
+	// the interpolated crossing lands a hair inside the edge at x -64
+	bounds := [2][2]float64{{-64, -64}, {4160, 4160}}
+	pts := []maths.Pt{{-499.4, 100.3}, {2000.7, 2000.9}}
+
+	got := clipLine(pts, bounds, nil)
+	if len(got) != 1 || len(got[0]) != 2 {
+		t.Fatalf("expected a single line of 2 points got %v", got)
+	}
+	if got[0][0][0] != -64 {
+		t.Errorf("expected the line to start on the edge at x -64 got %v", got[0][0][0])
+	}
+}
+
+// denseLines builds lines with many points, like the road layers of a dense city
+func denseLines(t testing.TB, tile *tegola.Tile) []Feature {
+	var geos []geom.Geometry
+	for i := 0; i < 200; i++ {
+		var ln geom.LineString
+		for j := 0; j < 500; j++ {
+			x := float64(j*9) - 100
+			ln = append(ln, [2]float64{x, float64(i*20) + 50*math.Sin(x/100)})
+		}
+		geos = append(geos, ln)
+	}
+
+	return geomFeatures(t, tile, geos...)
+}
+
+func benchmarkLayerEncoding(b *testing.B, features []Feature) {
+	tile := tegola.NewTile(14, 8192, 8192)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := Layer{Name: "roads"}
+		l.AddFeatures(features...)
+		if _, err := l.VTileLayer(context.Background(), tile); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkLayerEncodingGeom(b *testing.B) {
+	features := denseLines(b, tegola.NewTile(14, 8192, 8192))
+	benchmarkLayerEncoding(b, features)
+}
+
+// benchmarkLayerEncodingLegacy includes the conversion to the legacy types, like atlas did before the geom types
+// were encoded natively
+func benchmarkLayerEncodingLegacy(b *testing.B, features []Feature) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		l := Layer{Name: "roads"}
+		b.StartTimer()
+
+		l.AddFeatures(legacyFeatures(b, features)...)
+		if _, err := l.VTileLayer(context.Background(), tegola.NewTile(14, 8192, 8192)); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkLayerEncodingLegacy(b *testing.B) {
+	features := denseLines(b, tegola.NewTile(14, 8192, 8192))
+	benchmarkLayerEncodingLegacy(b, features)
+}
+
+// densePolygons builds rings with many points, like the building layers of a dense city. Polygons are
+// encoded through the legacy types once scaled, so the native encoding only saves the conversion.
+func densePolygons(t testing.TB, tile *tegola.Tile) []Feature {
+	var geos []geom.Geometry
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 20; j++ {
+			// the outer polygons cross the edges of the tile and are clipped
+			cx, cy := float64(i*220)-100, float64(j*220)-100
+			var ring [][2]float64
+			for k := 0; k < 40; k++ {
+				a := float64(k) * 2 * math.Pi / 40
+				r := 80 + 20*math.Sin(5*a)
+				ring = append(ring, [2]float64{cx + r*math.Cos(a), cy + r*math.Sin(a)})
+			}
+			geos = append(geos, geom.Polygon{ring})
+		}
+	}
+
+	return geomFeatures(t, tile, geos...)
+}
+
+func BenchmarkLayerEncodingGeomPolygons(b *testing.B) {
+	features := densePolygons(b, tegola.NewTile(14, 8192, 8192))
+	benchmarkLayerEncoding(b, features)
+}
+
+func BenchmarkLayerEncodingLegacyPolygons(b *testing.B) {
+	features := densePolygons(b, tegola.NewTile(14, 8192, 8192))
+	benchmarkLayerEncodingLegacy(b, features)
+}
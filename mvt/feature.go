@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/basic"
@@ -36,7 +37,8 @@ type Feature struct {
 	ID   *uint64
 	Tags map[string]interface{}
 	// Does not support the collection geometry, for this you have to create a feature for each
-	// geometry in the collection.
+	// geometry in the collection. The geom package types are encoded natively, without
+	// being converted to the legacy tegola types.
 	Geometry tegola.Geometry
 	// Unsimplifed weather the Geometry is simple already and thus does not need to be simplified.
 	Unsimplifed *bool
@@ -109,6 +111,31 @@ func (f *Feature) VTileFeature(ctx context.Context, keys []string, vals []interf
 	return tf, nil
 }
 
+// preparedVTileFeature returns the vectorTile.Feature for a Feature whose geometry has already been prepared
+// (scaled, simplified and clipped) for the tile
+func (f *Feature) preparedVTileFeature(keys []string, vals []interface{}, tile *tegola.Tile) (tf *vectorTile.Tile_Feature, err error) {
+	tf = new(vectorTile.Tile_Feature)
+	tf.Id = f.ID
+
+	if tf.Tags, err = keyvalTagsMap(keys, vals, f); err != nil {
+		return tf, err
+	}
+
+	geo, gtype, err := encodePreparedGeometry(f.Geometry, tile)
+	if err != nil {
+		return tf, err
+	}
+
+	if len(geo) == 0 {
+		return nil, nil
+	}
+
+	tf.Geometry = geo
+	tf.Type = &gtype
+
+	return tf, nil
+}
+
 // These values came from: https://github.com/mapbox/vector-tile-spec/tree/master/2.1
 const (
 	cmdMoveTo    uint32 = 1
@@ -306,7 +333,7 @@ func normalizePoints(pts []maths.Pt) (pnts []maths.Pt) {
 	return pnts
 }
 
-func simplifyPolygon(g tegola.Polygon, tolerance float64, simplify bool) basic.Polygon {
+func (s simplifier) simplifyPolygon(g tegola.Polygon, tolerance float64, simplify bool) basic.Polygon {
 
 	lines := g.Sublines()
 	if len(lines) <= 0 {
@@ -320,6 +347,19 @@ func simplifyPolygon(g tegola.Polygon, tolerance float64, simplify bool) basic.P
 		area := maths.AreaOfPolygonLineString(lines[i])
 		l := basic.CloneLine(lines[i])
 
+		// rings sharing boundaries have to be simplified the same way regardless of their size
+		if s.preserveTopology() {
+			pts := s.simplifyRingTopology(l.AsPts(), sqTolerance)
+			if len(pts) <= 2 {
+				if i == 0 {
+					return nil
+				}
+				continue
+			}
+			poly = append(poly, basic.NewLineTruncatedFromPt(pts...))
+			continue
+		}
+
 		if area < sqTolerance {
 			if i == 0 {
 				return basic.ClonePolygon(g)
@@ -346,7 +386,7 @@ func simplifyPolygon(g tegola.Polygon, tolerance float64, simplify bool) basic.P
 			continue
 		}
 
-		pts = maths.DouglasPeucker(pts, sqTolerance, simplify)
+		pts = s.simplifyRing(pts, sqTolerance)
 		if len(pts) <= 2 {
 			if i == 0 {
 				return nil
@@ -366,16 +406,20 @@ func simplifyPolygon(g tegola.Polygon, tolerance float64, simplify bool) basic.P
 }
 
 func SimplifyGeometry(g tegola.Geometry, tolerance float64, simplify bool) tegola.Geometry {
+	return simplifier{}.simplifyGeometry(g, tolerance, simplify)
+}
+
+func (s simplifier) simplifyGeometry(g tegola.Geometry, tolerance float64, simplify bool) tegola.Geometry {
 	if !simplify || g == nil {
 		return g
 	}
 	switch gg := g.(type) {
 	case tegola.Polygon:
-		return simplifyPolygon(gg, tolerance, simplify)
+		return s.simplifyPolygon(gg, tolerance, simplify)
 	case tegola.MultiPolygon:
 		var newMP basic.MultiPolygon
 		for _, p := range gg.Polygons() {
-			sp := simplifyPolygon(p, tolerance, simplify)
+			sp := s.simplifyPolygon(p, tolerance, simplify)
 			if sp == nil {
 				continue
 			}
@@ -575,31 +619,61 @@ func encodeGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile
 		return nil, vectorTile.Tile_UNKNOWN, ErrNilGeometryType
 	}
 
-	//	new cursor
-	c := NewCursor(tile)
-	// We are scaling separately, no need to scale in cursor.
-	c.DisableScaling = true
+	geom, err = prepareGeometry(ctx, geom, tile, simplify)
+	if err != nil {
+		return nil, vectorTile.Tile_UNKNOWN, err
+	}
+	if geom == nil {
+		return []uint32{}, -1, nil
+	}
 
-	// Project Geom
+	return encodePreparedGeometry(geom, tile)
+}
 
+// prepareGeometry scales the geometry into the tile's pixel space then simplifies and clips it
+// to the tile's buffered bounds.
+func prepareGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile, simplify bool) (tegola.Geometry, error) {
 	// TODO: gdey: We need to separate out the transform, simplification, and clipping from the encoding process. #224
 
-	geo := c.ScaleGeo(geom)
+	geo := scaleGeometry(geom, tile)
 	sg := SimplifyGeometry(geo, tile.ZEpislon(), simplify)
 
+	return cleanGeometry(ctx, sg, tile)
+}
+
+// scaleGeometry projects the geometry into the tile's pixel space
+func scaleGeometry(geom tegola.Geometry, tile *tegola.Tile) basic.Geometry {
+	//	new cursor
+	c := NewCursor(tile)
+	// We are scaling separately, no need to scale in cursor.
+	c.DisableScaling = true
+
+	return c.ScaleGeo(geom)
+}
+
+// cleanGeometry makes the scaled geometry valid and clips it to the tile's buffered bounds
+func cleanGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile) (tegola.Geometry, error) {
 	pbb, err := tile.PixelBufferedBounds()
 	if err != nil {
-		return nil, vectorTile.Tile_UNKNOWN, err
+		return nil, err
 	}
 	ext := points.Extent(pbb)
 
-	geom, err = validate.CleanGeometry(ctx, sg, &ext)
-	if err != nil {
-		return nil, vectorTile.Tile_UNKNOWN, err
-	}
-	if geom == nil {
-		return []uint32{}, -1, nil
+	return validate.CleanGeometry(ctx, geom, &ext)
+}
+
+// encodePreparedGeometry encodes a geometry which has already been scaled and clipped by prepareGeometry.
+func encodePreparedGeometry(geom tegola.Geometry, tile *tegola.Tile) (g []uint32, vtyp vectorTile.Tile_GeomType, err error) {
+	// points and lines prepared from the geom types
+	if g, vtyp, ok := encodeGeom(geom); ok {
+		return g, vtyp, nil
 	}
+
+	//	new cursor
+	c := NewCursor(tile)
+	// the geometry has already been scaled
+	c.DisableScaling = true
+
 	switch t := geom.(type) {
 	case tegola.Point:
 		g = append(g, c.MoveTo(t)...)
@@ -615,6 +689,9 @@ func encodeGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile
 
 	case tegola.LineString:
 		points := t.Subpoints()
+		if len(points) == 0 {
+			return g, vectorTile.Tile_LINESTRING, nil
+		}
 		g = append(g, c.MoveTo(points[0])...)
 		g = append(g, c.LineTo(points[1:]...)...)
 		return g, vectorTile.Tile_LINESTRING, nil
@@ -623,6 +700,9 @@ func encodeGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile
 		lines := t.Lines()
 		for _, l := range lines {
 			points := l.Subpoints()
+			if len(points) == 0 {
+				continue
+			}
 			g = append(g, c.MoveTo(points[0])...)
 			g = append(g, c.LineTo(points[1:]...)...)
 		}
@@ -661,10 +741,13 @@ func encodeGeometry(ctx context.Context, geom tegola.Geometry, tile *tegola.Tile
 // to mapbox tile format. In the Tile format, the Tile contains a mapping of all the unique
 // keys and values, and then each feature contains a vector map to these two. This is an
 // intermediate data structure to help with the construction of the three mappings.
+// The keys and values are in the order they first appear in the features, with the tags of
+// each feature in key order, so the same features always produce the same mappings.
 func keyvalMapsFromFeatures(features []Feature) (keyMap []string, valMap []interface{}, err error) {
 	var didFind bool
 	for _, f := range features {
-		for k, v := range f.Tags {
+		for _, k := range sortedTagKeys(f.Tags) {
+			v := f.Tags[k]
 			didFind = false
 			for _, mk := range keyMap {
 				if k == mk {
@@ -829,6 +912,18 @@ func keyvalMapsFromFeatures(features []Feature) (keyMap []string, valMap []inter
 					}
 				}
 
+			case sintValue:
+				for _, mv := range valMap {
+					tmv, ok := mv.(sintValue)
+					if !ok {
+						continue
+					}
+					if tmv == vt {
+						didFind = true
+						break
+					}
+				}
+
 			case float32:
 				for _, mv := range valMap {
 					tmv, ok := mv.(float32)
@@ -887,7 +982,8 @@ func keyvalTagsMap(keyMap []string, valueMap []interface{}, f *Feature) (tags []
 
 	var kidx, vidx int64
 
-	for key, val := range f.Tags {
+	for _, key := range sortedTagKeys(f.Tags) {
+		val := f.Tags[key]
 
 		kidx, vidx = -1, -1 // Set to known not found value.
 
@@ -975,6 +1071,12 @@ func keyvalTagsMap(keyMap []string, valueMap []interface{}, f *Feature) (tags []
 					continue
 				}
 
+			case sintValue:
+				vmt, ok := v.(sintValue)
+				if !ok || vmt != tv {
+					continue
+				}
+
 			case float32:
 				vmt, ok := v.(float32)
 				if !ok || vmt != tv {
@@ -1004,3 +1106,15 @@ func keyvalTagsMap(keyMap []string, valueMap []interface{}, f *Feature) (tags []
 
 	return tags, nil
 }
+
+// sortedTagKeys returns the keys of the tags in order. Go randomizes the iteration order of maps,
+// which would make the encoded tiles differ from one encoding to the next.
+func sortedTagKeys(tags map[string]interface{}) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
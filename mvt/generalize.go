@@ -0,0 +1,274 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+)
+
+// prepareFeatures scales, simplifies and clips the geometries of the layer's features to the tile using
+// the layer's SimplifyAlgorithm and Validation mode. When PreserveTopology is set the boundaries shared
+// between polygons are simplified identically. Features without a geometry in the tile are dropped.
+func (l *Layer) prepareFeatures(ctx context.Context, tile *tegola.Tile, simplify bool) ([]Feature, error) {
+	s := simplifier{algorithm: l.SimplifyAlgorithm}
+
+	//	geom geometries are prepared natively unless the boundaries shared between the layer's
+	//	features are needed, which is only supported for the legacy types
+	native := !l.PreserveTopology && len(l.MergeLinesBy) == 0 && len(l.DissolvePolygonsBy) == 0
+
+	scaled := make([]tegola.Geometry, len(l.features))
+	for i := range l.features {
+		geo := l.features[i].Geometry
+		if geo == nil {
+			return nil, fmt.Errorf("Error getting VTileFeature: %v", ErrNilGeometryType)
+		}
+		if native && isGeom(geo) {
+			continue
+		}
+
+		geo, err := toLegacyGeometry(geo)
+		if err != nil {
+			return nil, err
+		}
+		scaled[i] = scaleGeometry(geo, tile)
+	}
+
+	if l.PreserveTopology && simplify {
+		s.locked = sharedJunctions(scaled)
+	}
+
+	out := make([]Feature, 0, len(l.features))
+	for i, f := range l.features {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var geo tegola.Geometry
+		var err error
+		if scaled[i] == nil {
+			geo, err = l.prepareGeom(ctx, tile, f, s, simplify)
+		} else {
+			sg := s.simplifyGeometry(scaled[i], tile.ZEpislon(), simplify)
+			geo, err = l.validateGeometry(ctx, tile, f, sg)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if geo == nil {
+			continue
+		}
+
+		f.Geometry = geo
+		out = append(out, f)
+	}
+
+	return out, nil
+}
+
+// generalizeFeatures merges touching lines which share the values of the MergeLinesBy tags and dissolves
+// polygons which share the values of the DissolvePolygonsBy tags. The features must have been prepared
+// with prepareFeatures. Merged features are placed at the position of the first feature of their group,
+// carry only the tags they were grouped by and have no ID. The output order only depends on the order
+// of the features: groups are merged in the order they were created in and never by iterating the map.
+func (l *Layer) generalizeFeatures(ctx context.Context, tile *tegola.Tile, features []Feature) ([]Feature, error) {
+	type group struct {
+		//	index into out of the group's feature
+		idx     int
+		members []Feature
+		tags    []string
+	}
+
+	var out []Feature
+	groups := map[string]*group{}
+	// the order groups were created in, so merging is deterministic
+	var order []*group
+
+	for _, f := range features {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var tags []string
+		var kind string
+		switch f.Geometry.(type) {
+		case tegola.LineString, tegola.MultiLine:
+			tags, kind = l.MergeLinesBy, "line"
+		case tegola.Polygon, tegola.MultiPolygon:
+			tags, kind = l.DissolvePolygonsBy, "polygon"
+		}
+
+		if len(tags) == 0 {
+			out = append(out, f)
+			continue
+		}
+
+		key := groupKey(kind, tags, f.Tags)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{idx: len(out), tags: tags}
+			groups[key] = g
+			order = append(order, g)
+			out = append(out, f)
+		}
+		g.members = append(g.members, f)
+	}
+
+	for _, g := range order {
+		// nothing to merge
+		if len(g.members) == 1 {
+			continue
+		}
+
+		merged := Feature{
+			Tags: make(map[string]interface{}, len(g.tags)),
+		}
+		for _, t := range g.tags {
+			if v, ok := g.members[0].Tags[t]; ok {
+				merged.Tags[t] = v
+			}
+		}
+
+		switch out[g.idx].Geometry.(type) {
+		case tegola.LineString, tegola.MultiLine:
+			merged.Geometry = mergeLines(g.members)
+		default:
+			geo, err := dissolvePolygons(ctx, tile, g.members)
+			if err != nil {
+				return nil, err
+			}
+			merged.Geometry = geo
+		}
+
+		out[g.idx] = merged
+	}
+
+	return out, nil
+}
+
+// groupKey builds the key features are grouped by from the values of the tags
+func groupKey(kind string, tags []string, values map[string]interface{}) string {
+	parts := make([]string, 0, len(tags)+1)
+	parts = append(parts, kind)
+	for _, t := range tags {
+		parts = append(parts, fmt.Sprintf("%v", values[t]))
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// mergeLines joins the lines of the features which share an endpoint with no other lines.
+// Lines meeting at a junction of three or more lines are not joined.
+func mergeLines(features []Feature) tegola.Geometry {
+	var lines []basic.Line
+	for _, f := range features {
+		switch g := f.Geometry.(type) {
+		case tegola.LineString:
+			lines = append(lines, basic.CloneLine(g))
+		case tegola.MultiLine:
+			for _, ln := range g.Lines() {
+				lines = append(lines, basic.CloneLine(ln))
+			}
+		}
+	}
+
+	// index the lines by their endpoints
+	ends := map[basic.Point][]int{}
+	for i, ln := range lines {
+		if len(ln) < 2 {
+			continue
+		}
+		ends[ln[0]] = append(ends[ln[0]], i)
+		ends[ln[len(ln)-1]] = append(ends[ln[len(ln)-1]], i)
+	}
+
+	used := make([]bool, len(lines))
+
+	// next returns the unused line which can be joined at pt
+	next := func(pt basic.Point) (int, bool) {
+		idxs := ends[pt]
+		if len(idxs) != 2 {
+			return 0, false
+		}
+		for _, i := range idxs {
+			if !used[i] {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	var ml basic.MultiLine
+	for i := range lines {
+		if used[i] || len(lines[i]) < 2 {
+			continue
+		}
+		used[i] = true
+		chain := lines[i]
+
+		// extend the tail
+		for {
+			tail := chain[len(chain)-1]
+			j, ok := next(tail)
+			if !ok {
+				break
+			}
+			used[j] = true
+			ln := lines[j]
+			if ln[0] != tail {
+				ln = reverseLine(ln)
+			}
+			chain = append(chain, ln[1:]...)
+		}
+
+		// extend the head
+		for {
+			head := chain[0]
+			j, ok := next(head)
+			if !ok {
+				break
+			}
+			used[j] = true
+			ln := lines[j]
+			if ln[len(ln)-1] != head {
+				ln = reverseLine(ln)
+			}
+			chain = append(ln[:len(ln)-1:len(ln)-1], chain...)
+		}
+
+		ml = append(ml, chain)
+	}
+
+	if len(ml) == 1 {
+		return ml[0]
+	}
+
+	return ml
+}
+
+func reverseLine(ln basic.Line) basic.Line {
+	r := make(basic.Line, len(ln))
+	for i := range ln {
+		r[len(ln)-1-i] = ln[i]
+	}
+
+	return r
+}
+
+// dissolvePolygons unions the polygons of the features, removing the edges shared between them
+func dissolvePolygons(ctx context.Context, tile *tegola.Tile, features []Feature) (tegola.Geometry, error) {
+	var mp basic.MultiPolygon
+	for _, f := range features {
+		switch g := f.Geometry.(type) {
+		case tegola.Polygon:
+			mp = append(mp, basic.ClonePolygon(g))
+		case tegola.MultiPolygon:
+			for _, p := range g.Polygons() {
+				mp = append(mp, basic.ClonePolygon(p))
+			}
+		}
+	}
+
+	// making the combined polygons valid merges the areas they cover
+	return cleanGeometry(ctx, mp, tile)
+}
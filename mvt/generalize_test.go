@@ -0,0 +1,70 @@
+package mvt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+)
+
+func TestMergeLines(t *testing.T) {
+	testcases := []struct {
+		features []Feature
+		expected tegola.Geometry
+	}{
+		{
+			//	touching lines, the second line is reversed
+			features: []Feature{
+				{Geometry: basic.Line{{0, 0}, {10, 0}}},
+				{Geometry: basic.Line{{20, 10}, {10, 0}}},
+			},
+			expected: basic.Line{{0, 0}, {10, 0}, {20, 10}},
+		},
+		{
+			//	the head of the first line is extended
+			features: []Feature{
+				{Geometry: basic.Line{{10, 0}, {20, 0}}},
+				{Geometry: basic.MultiLine{{{0, 0}, {10, 0}}, {{50, 50}, {60, 60}}}},
+			},
+			expected: basic.MultiLine{{{0, 0}, {10, 0}, {20, 0}}, {{50, 50}, {60, 60}}},
+		},
+		{
+			//	lines meeting at a junction are not merged
+			features: []Feature{
+				{Geometry: basic.Line{{0, 0}, {10, 0}}},
+				{Geometry: basic.Line{{10, 0}, {20, 0}}},
+				{Geometry: basic.Line{{10, 0}, {10, 10}}},
+			},
+			expected: basic.MultiLine{{{0, 0}, {10, 0}}, {{10, 0}, {20, 0}}, {{10, 0}, {10, 10}}},
+		},
+	}
+
+	for i, tc := range testcases {
+		output := mergeLines(tc.features)
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, output)
+		}
+	}
+}
+
+func TestDissolvePolygons(t *testing.T) {
+	tile := tegola.NewTile(0, 0, 0)
+
+	features := []Feature{
+		{Geometry: basic.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}},
+		{Geometry: basic.Polygon{{{10, 0}, {20, 0}, {20, 10}, {10, 10}}}},
+	}
+
+	output, err := dissolvePolygons(context.Background(), tile, features)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	//	the shared edge is removed
+	expected := basic.MultiPolygon{{{{0, 0}, {20, 0}, {20, 10}, {0, 10}}}}
+	if !reflect.DeepEqual(output, expected) {
+		t.Errorf("expected %v got %v", expected, output)
+	}
+}
@@ -0,0 +1,110 @@
+package mvt
+
+import (
+	"context"
+	"math"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/maths"
+	"github.com/go-spatial/tegola/maths/polylabel"
+	"github.com/go-spatial/tegola/mvt/vector_tile"
+)
+
+// labelPrecision is the precision, in tile units, label points are computed to
+const labelPrecision = 1.0
+
+// VTileLabelLayer returns a vectorTile Tile_Layer, named LabelLayer, with a label point for each of the
+// layer's polygons. The label is the pole of inaccessibility of the part of the polygon which is visible
+// in the tile, so labels are always placed inside the polygon and within the tile. The labels carry the
+// ID and tags of their polygon.
+func (l *Layer) VTileLabelLayer(ctx context.Context, tile *tegola.Tile) (*vectorTile.Tile_Layer, error) {
+	tile = l.layerTile(tile)
+	simplify := l.simplify(tile)
+
+	// the visible part of the tile, without the buffer
+	visible := *tile
+	visible.Buffer = 0
+	visible.Init()
+
+	var labels []Feature
+	for _, f := range l.features {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fgeo, err := toLegacyGeometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+
+		switch fgeo.(type) {
+		case tegola.Polygon, tegola.MultiPolygon:
+		default:
+			continue
+		}
+
+		geo, err := prepareGeometry(ctx, fgeo, &visible, simplify)
+		if err != nil {
+			return nil, err
+		}
+
+		rings := largestPolygon(geo)
+		if len(rings) == 0 {
+			continue
+		}
+
+		pt := polylabel.Polylabel(rings, labelPrecision)
+		labels = append(labels, Feature{
+			ID:       f.ID,
+			Tags:     f.Tags,
+			Geometry: basic.Point{pt.X, pt.Y},
+		})
+	}
+
+	return l.encodeFeatures(ctx, l.LabelLayer, tile, labels)
+}
+
+// largestPolygon returns the rings of the polygon with the largest exterior ring of the geometry
+func largestPolygon(geo tegola.Geometry) (rings [][]maths.Pt) {
+	var polygons []tegola.Polygon
+	switch g := geo.(type) {
+	case tegola.Polygon:
+		polygons = append(polygons, g)
+	case tegola.MultiPolygon:
+		polygons = g.Polygons()
+	}
+
+	var largest float64
+	for _, p := range polygons {
+		lines := p.Sublines()
+		if len(lines) == 0 {
+			continue
+		}
+
+		prings := make([][]maths.Pt, 0, len(lines))
+		for _, ln := range lines {
+			pts := ln.Subpoints()
+			ring := make([]maths.Pt, len(pts))
+			for i := range pts {
+				ring[i] = maths.Pt{X: pts[i].X(), Y: pts[i].Y()}
+			}
+			prings = append(prings, ring)
+		}
+
+		if area := math.Abs(ringArea(prings[0])); area > largest || rings == nil {
+			largest, rings = area, prings
+		}
+	}
+
+	return rings
+}
+
+// ringArea returns the signed area of the ring
+func ringArea(ring []maths.Pt) (area float64) {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		area += ring[j].X*ring[i].Y - ring[i].X*ring[j].Y
+	}
+
+	return area / 2
+}
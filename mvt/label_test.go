@@ -0,0 +1,61 @@
+package mvt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/mvt/vector_tile"
+)
+
+func TestLayerVTileLabelLayer(t *testing.T) {
+	id := uint64(7)
+	l := Layer{
+		Name:       "lakes",
+		LabelLayer: "lakes_labels",
+	}
+	l.AddFeatures(
+		Feature{
+			ID:   &id,
+			Tags: map[string]interface{}{"name": "lake"},
+			//	the top right quarter of the north eastern quadrant of the world
+			Geometry: basic.Polygon{
+				{{0, 0}, {10018754.17, 0}, {10018754.17, 10018754.17}, {0, 10018754.17}},
+			},
+		},
+		//	non polygons are not labeled
+		Feature{
+			Tags:     map[string]interface{}{"name": "river"},
+			Geometry: basic.Line{{0, 0}, {10018754.17, 10018754.17}},
+		},
+	)
+
+	vtl, err := l.VTileLabelLayer(context.Background(), tegola.NewTile(0, 0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if vtl.GetName() != "lakes_labels" {
+		t.Errorf("name, expected lakes_labels got %v", vtl.GetName())
+	}
+
+	if len(vtl.Features) != 1 {
+		t.Fatalf("number of features, expected 1 got %v", len(vtl.Features))
+	}
+
+	f := vtl.Features[0]
+	if f.GetId() != id {
+		t.Errorf("id, expected %v got %v", id, f.GetId())
+	}
+	if f.GetType() != vectorTile.Tile_POINT {
+		t.Errorf("type, expected %v got %v", vectorTile.Tile_POINT, f.GetType())
+	}
+
+	//	MoveTo the center of the polygon (2560, 1536)
+	expected := []uint32{9, 5120, 3072}
+	if !reflect.DeepEqual(f.Geometry, expected) {
+		t.Errorf("geometry, expected %v got %v", expected, f.Geometry)
+	}
+}
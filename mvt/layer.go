@@ -50,12 +50,49 @@ type Layer struct {
 	// This is the name of the feature, is has to be unique within a tile.
 	Name string
 	// The set of features
-	features []Feature
-	extent   *int // default is 4096
+	features  []Feature
+	extent    *int     // default is 4096
+	buffer    *int     // default is 64
+	tolerance *float64 // default is tegola.DefaultEpislon
 	// DontSimplify truns off simplification for this layer.
 	DontSimplify bool
 	// MaxSimplificationZoom is the zoom level at which point simplification is turned off. if value is zero Max is set to 14. If you do not want to simplify at any level set DontSimplify to true.
 	MaxSimplificationZoom uint
+	// MergeLinesBy are the tags touching lines must have equal values for to be merged into a single feature.
+	// If empty, lines are not merged.
+	MergeLinesBy []string
+	// DissolvePolygonsBy are the tags adjacent polygons must have equal values for to be dissolved into a
+	// single feature. If empty, polygons are not dissolved.
+	DissolvePolygonsBy []string
+	// LabelLayer is the name of the companion point layer holding a label point for each polygon of
+	// this layer. If empty, no label layer is encoded.
+	LabelLayer string
+	// SimplifyAlgorithm is the algorithm polygons are simplified with (i.e. SimplifyVisvalingam).
+	// Defaults to SimplifyDouglasPeucker.
+	SimplifyAlgorithm string
+	// PreserveTopology simplifies the boundaries shared between the layer's polygons identically
+	// so no gaps or slivers open up between neighbors.
+	PreserveTopology bool
+	// Validation is how the layer's geometries are validated (i.e. ValidationClipOnly).
+	// Defaults to ValidationMakeValid.
+	Validation string
+	// DontDeduplicate turns off skipping features whose ID is already in the layer.
+	DontDeduplicate bool
+	// ComplexTags is how map tag values are encoded (i.e. ComplexTagsFlatten). Lists are encoded as
+	// JSON strings. Defaults to ComplexTagsMerge.
+	ComplexTags string
+	// TagKeySeparator joins the keys of maps flattened by ComplexTagsFlatten. Defaults to DefaultTagKeySeparator.
+	TagKeySeparator string
+	// IntegerTags is the vector tile value type integer tag values are encoded as (i.e. IntegerTagsSint).
+	// By default the value type follows the Go type of the value.
+	IntegerTags string
+
+	// counts of the invalid polygons found during the last encoding
+	validationStats ValidationStats
+	// the IDs of the features in the layer
+	ids map[uint64]struct{}
+	// when streaming, features are encoded as they are added
+	stream *layerStream
 }
 
 func valMapToVTileValue(valMap []interface{}) (vt []*vectorTile.Tile_Value) {
@@ -67,24 +104,73 @@ func valMapToVTileValue(valMap []interface{}) (vt []*vectorTile.Tile_Value) {
 
 // VTileLayer returns a vectorTile Tile_Layer object that represents this layer.
 func (l *Layer) VTileLayer(ctx context.Context, tile *tegola.Tile) (*vectorTile.Tile_Layer, error) {
-	kmap, vmap, err := keyvalMapsFromFeatures(l.features)
+	if l.stream != nil {
+		return l.stream.vtileLayer(l.Name, l.Version())
+	}
+
+	// the layer's extent and buffer take precedence over the tile's
+	tile = l.layerTile(tile)
+
+	simplify := l.simplify(tile)
+
+	l.validationStats = ValidationStats{}
+
+	lfeatures, err := l.prepareFeatures(ctx, tile, simplify)
+	if err != nil {
+		return nil, err
+	}
+
+	// merging and dissolving happen after the geometries have been clipped to the tile
+	if len(l.MergeLinesBy) > 0 || len(l.DissolvePolygonsBy) > 0 {
+		if lfeatures, err = l.generalizeFeatures(ctx, tile, lfeatures); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.encodeFeatures(ctx, l.Name, tile, lfeatures)
+}
+
+// ValidationStats returns the counts of the invalid polygons found during the last encoding of the layer
+func (l *Layer) ValidationStats() ValidationStats {
+	if l == nil {
+		return ValidationStats{}
+	}
+	return l.validationStats
+}
+
+// simplify reports whether the layer's geometries should be simplified for the tile
+func (l *Layer) simplify(tile *tegola.Tile) bool {
+	simplify := simplifyGeometries && !l.DontSimplify
+	if l.MaxSimplificationZoom == 0 {
+		l.MaxSimplificationZoom = uint(simplificationMaxZoom)
+	}
+
+	return simplify && tile.Z < int(l.MaxSimplificationZoom)
+}
+
+// encodeFeatures encodes the features into a vectorTile Tile_Layer with the provided name. The feature
+// geometries must have already been scaled and clipped to the tile.
+func (l *Layer) encodeFeatures(ctx context.Context, name string, tile *tegola.Tile, lfeatures []Feature) (*vectorTile.Tile_Layer, error) {
+	// the tags are encoded on copies of the features so the caller's tags aren't modified
+	tagged := make([]Feature, len(lfeatures))
+	for i := range lfeatures {
+		tagged[i] = lfeatures[i]
+		tagged[i].Tags = l.encodeTags(lfeatures[i].Tags)
+	}
+	lfeatures = tagged
+
+	kmap, vmap, err := keyvalMapsFromFeatures(lfeatures)
 	if err != nil {
 		return nil, err
 	}
 	valmap := valMapToVTileValue(vmap)
-	var features = make([]*vectorTile.Tile_Feature, 0, len(l.features))
-	for _, f := range l.features {
+	var features = make([]*vectorTile.Tile_Feature, 0, len(lfeatures))
+	for _, f := range lfeatures {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		simplify := simplifyGeometries && !l.DontSimplify
-		if l.MaxSimplificationZoom == 0 {
-			l.MaxSimplificationZoom = uint(simplificationMaxZoom)
-		}
-
-		simplify = simplify && tile.Z < int(l.MaxSimplificationZoom)
 
-		vtf, err := f.VTileFeature(ctx, kmap, vmap, tile, simplify)
+		vtf, err := f.preparedVTileFeature(kmap, vmap, tile)
 		if err != nil {
 			switch err {
 			case context.Canceled:
@@ -101,7 +187,6 @@ func (l *Layer) VTileLayer(ctx context.Context, tile *tegola.Tile) (*vectorTile.
 	version := uint32(l.Version())
 	vtl := new(vectorTile.Tile_Layer)
 	vtl.Version = &version
-	name := l.Name // Need to make a copy of the string.
 	vtl.Name = &name
 	vtl.Features = features
 	vtl.Keys = kmap
@@ -129,8 +214,63 @@ func (l *Layer) SetExtent(e int) {
 	l.extent = &e
 }
 
+// Buffer defaults to 64
+func (l *Layer) Buffer() int {
+	if l == nil || l.buffer == nil {
+		return tegola.DefaultTileBuffer
+	}
+	return *(l.buffer)
+}
+
+// SetBuffer sets the buffer value
+func (l *Layer) SetBuffer(b int) {
+	if l == nil {
+		l = new(Layer)
+	}
+	l.buffer = &b
+}
+
+// Tolerance defaults to tegola.DefaultEpislon
+func (l *Layer) Tolerance() float64 {
+	if l == nil || l.tolerance == nil {
+		return tegola.DefaultEpislon
+	}
+	return *(l.tolerance)
+}
+
+// SetTolerance sets the simplification tolerance value
+func (l *Layer) SetTolerance(t float64) {
+	if l == nil {
+		l = new(Layer)
+	}
+	l.tolerance = &t
+}
+
+// layerTile returns a copy of the tile using the extent, buffer and tolerance set on the layer.
+// If none have been set the tile is returned as is.
+func (l *Layer) layerTile(tile *tegola.Tile) *tegola.Tile {
+	if l.extent == nil && l.buffer == nil && l.tolerance == nil {
+		return tile
+	}
+
+	t := *tile
+	if l.extent != nil {
+		t.Extent = float64(*l.extent)
+	}
+	if l.buffer != nil {
+		t.Buffer = float64(*l.buffer)
+	}
+	if l.tolerance != nil {
+		t.Tolerance = *l.tolerance
+	}
+	// recompute the cached bounds
+	t.Init()
+
+	return &t
+}
+
 // Features returns a copy of the features in the layer, use the index of the this
-// array to remove any features from the layer
+// array to remove any features from the layer. Streamed features are not included.
 func (l *Layer) Features() (f []Feature) {
 	if l == nil || l.features == nil {
 		return nil
@@ -140,30 +280,28 @@ func (l *Layer) Features() (f []Feature) {
 }
 
 //AddFeatures will add one or more Features to the Layer, if a features ID is a the same as
-//Any already in the Layer, it will ignore those features.
+//Any already in the Layer, it will ignore those features, unless DontDeduplicate is set.
 //If the id fields is nil, the feature will always be added.
+//When the layer is streaming the features are encoded right away.
 func (l *Layer) AddFeatures(features ...Feature) (skipped bool) {
-
-	b := make([]Feature, len(l.features), len(l.features)+len(features))
-	copy(b, l.features)
-	l.features = b
-FEATURES_LOOP:
 	for _, f := range features {
-		if f.ID == nil {
-			l.features = append(l.features, f)
-			continue
-		}
-		for _, cf := range l.features {
-			if cf.ID == nil {
-				continue
-			}
+		if f.ID != nil && !l.DontDeduplicate {
 			// We matched, we skip
-			if *cf.ID == *f.ID {
+			if _, ok := l.ids[*f.ID]; ok {
 				skipped = true
-				continue FEATURES_LOOP
+				continue
 			}
+			if l.ids == nil {
+				l.ids = make(map[uint64]struct{})
+			}
+			l.ids[*f.ID] = struct{}{}
 		}
-		// There were no matches, let's add it to our list.
+
+		if l.stream != nil {
+			l.streamFeature(f)
+			continue
+		}
+
 		l.features = append(l.features, f)
 	}
 	return skipped
@@ -172,16 +310,23 @@ FEATURES_LOOP:
 //RemoveFeature allows you to remove one or more features, with the provided indexes.
 //To figure out the indexes, use the indexs from the Features array.
 func (l *Layer) RemoveFeature(idxs ...int) {
+	skip := make(map[int]struct{}, len(idxs))
+	for _, j := range idxs {
+		skip[j] = struct{}{}
+	}
+
 	var features = make([]Feature, 0, len(l.features))
-SKIP:
 	for i, f := range l.features {
-		for _, j := range idxs {
-			if i == j {
-				continue SKIP
+		if _, ok := skip[i]; ok {
+			// the ID can be added again
+			if f.ID != nil {
+				delete(l.ids, *f.ID)
 			}
+			continue
 		}
 		features = append(features, f)
 	}
+	l.features = features
 }
 
 func vectorTileValue(i interface{}) *vectorTile.Tile_Value {
@@ -217,9 +362,17 @@ func vectorTileValue(i interface{}) *vectorTile.Tile_Value {
 		intv := int64(t)
 		tv.SintValue = &intv
 
+	case int:
+		intv := int64(t)
+		tv.IntValue = &intv
+
 	case int64:
 		tv.IntValue = &t
 
+	case sintValue:
+		intv := int64(t)
+		tv.SintValue = &intv
+
 	case uint8:
 		intv := int64(t)
 		tv.SintValue = &intv
@@ -232,6 +385,10 @@ func vectorTileValue(i interface{}) *vectorTile.Tile_Value {
 		intv := int64(t)
 		tv.SintValue = &intv
 
+	case uint:
+		uintv := uint64(t)
+		tv.UintValue = &uintv
+
 	case uint64:
 		tv.UintValue = &t
 
@@ -31,6 +31,8 @@ func TestLayerAddFeatures(t *testing.T) {
 		features []Feature
 		expected []Feature // Nil means that it's the same as the features.
 		skipped  bool
+
+		dontDeduplicate bool
 	}
 	fn := func(idx int, tcase tc) {
 		defer func() {
@@ -40,6 +42,7 @@ func TestLayerAddFeatures(t *testing.T) {
 		}()
 		// First create a blank layer to add the features to.
 		l := new(Layer)
+		l.DontDeduplicate = tcase.dontDeduplicate
 		skipped := l.AddFeatures(tcase.features...)
 		if tcase.skipped != skipped {
 			t.Errorf("[%v] skipped value; expected: %v got: %v", idx, tcase.skipped, skipped)
@@ -111,6 +114,22 @@ func TestLayerAddFeatures(t *testing.T) {
 			},
 			skipped: true,
 		},
+		//	same feature without deduplication test
+		tc{
+			features: []Feature{
+				{
+					ID:       newID(1),
+					Tags:     map[string]interface{}{"atag": "tag"},
+					Geometry: basic.Point{12.0, 15.0},
+				},
+				{
+					ID:       newID(1),
+					Tags:     map[string]interface{}{"atag": "tag"},
+					Geometry: basic.Point{12.0, 15.0},
+				},
+			},
+			dontDeduplicate: true,
+		},
 		//	different feature test
 		tc{
 			features: []Feature{
@@ -0,0 +1,184 @@
+package mvt
+
+import (
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/maths"
+)
+
+// Supported polygon simplification algorithms. Lines are always simplified with Douglas-Peucker.
+const (
+	SimplifyDouglasPeucker = "douglas_peucker"
+	SimplifyVisvalingam    = "visvalingam"
+)
+
+// simplifier configures how geometries are simplified. The zero value simplifies polygons with
+// Douglas-Peucker, without preserving topology.
+type simplifier struct {
+	// algorithm used to simplify polygon rings (i.e. SimplifyVisvalingam)
+	algorithm string
+	// locked points are never removed. they split polygon rings into arcs which are simplified on their own,
+	// so boundaries shared between polygons are simplified identically. nil disables topology preservation
+	locked map[maths.Pt]bool
+}
+
+func (s simplifier) preserveTopology() bool {
+	return s.locked != nil
+}
+
+// simplifyRing simplifies the points of a polygon ring with the simplifier's algorithm
+func (s simplifier) simplifyRing(pts []maths.Pt, sqTolerance float64) []maths.Pt {
+	if s.algorithm != SimplifyVisvalingam {
+		return maths.DouglasPeucker(pts, sqTolerance, true)
+	}
+
+	// close the ring so the first point is kept, then reopen it
+	closed := append(append(make([]maths.Pt, 0, len(pts)+1), pts...), pts[0])
+	simplified := maths.Visvalingam(closed, sqTolerance)
+
+	return simplified[:len(simplified)-1]
+}
+
+// simplifyArc simplifies the open line with the simplifier's algorithm. The arc is always simplified in the
+// same direction so an arc shared by two rings, which is walked in opposite directions, is simplified identically.
+func (s simplifier) simplifyArc(arc []maths.Pt, sqTolerance float64) []maths.Pt {
+	pts := append(make([]maths.Pt, 0, len(arc)), arc...)
+
+	reversed := arcReversed(pts)
+	if reversed {
+		reversePts(pts)
+	}
+
+	if s.algorithm == SimplifyVisvalingam {
+		pts = maths.Visvalingam(pts, sqTolerance)
+	} else {
+		pts = maths.DouglasPeucker(pts, sqTolerance, true)
+	}
+
+	if reversed {
+		pts = append(make([]maths.Pt, 0, len(pts)), pts...)
+		reversePts(pts)
+	}
+
+	return pts
+}
+
+// simplifyRingTopology splits the ring into arcs at its locked points and simplifies each arc.
+// A ring without locked points starts from its lowest point so rings which are shared in their
+// entirety (i.e. an island filling a hole) are simplified identically.
+func (s simplifier) simplifyRingTopology(pts []maths.Pt, sqTolerance float64) []maths.Pt {
+	if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) < 3 {
+		return pts
+	}
+
+	start := -1
+	for i := range pts {
+		if s.locked[pts[i]] {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		start = 0
+		for i := range pts {
+			if ptLess(pts[i], pts[start]) {
+				start = i
+			}
+		}
+	}
+
+	// rotate the ring to start at a locked point and close it
+	ring := make([]maths.Pt, 0, len(pts)+1)
+	ring = append(ring, pts[start:]...)
+	ring = append(ring, pts[:start]...)
+	ring = append(ring, ring[0])
+
+	var simplified []maths.Pt
+	from := 0
+	for i := 1; i < len(ring); i++ {
+		if i != len(ring)-1 && !s.locked[ring[i]] {
+			continue
+		}
+
+		arc := s.simplifyArc(ring[from:i+1], sqTolerance)
+		// the last point of the arc is the first point of the next arc
+		simplified = append(simplified, arc[:len(arc)-1]...)
+		from = i
+	}
+
+	return simplified
+}
+
+// sharedJunctions returns the points where polygon rings of the geometries meet and diverge. These are
+// the points which are shared by more than one ring and which have more than two distinct neighbors.
+func sharedJunctions(geos []tegola.Geometry) map[maths.Pt]bool {
+	occurrences := map[maths.Pt]int{}
+	neighbors := map[maths.Pt]map[maths.Pt]struct{}{}
+
+	addRing := func(ring tegola.LineString) {
+		subpts := ring.Subpoints()
+		pts := make([]maths.Pt, len(subpts))
+		for i := range subpts {
+			pts[i] = maths.Pt{X: subpts[i].X(), Y: subpts[i].Y()}
+		}
+		if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+
+		for i, pt := range pts {
+			occurrences[pt]++
+			if neighbors[pt] == nil {
+				neighbors[pt] = map[maths.Pt]struct{}{}
+			}
+			neighbors[pt][pts[(i+len(pts)-1)%len(pts)]] = struct{}{}
+			neighbors[pt][pts[(i+1)%len(pts)]] = struct{}{}
+		}
+	}
+
+	for _, geo := range geos {
+		var polygons []tegola.Polygon
+		switch g := geo.(type) {
+		case tegola.Polygon:
+			polygons = append(polygons, g)
+		case tegola.MultiPolygon:
+			polygons = g.Polygons()
+		}
+
+		for _, p := range polygons {
+			for _, ring := range p.Sublines() {
+				addRing(ring)
+			}
+		}
+	}
+
+	junctions := map[maths.Pt]bool{}
+	for pt, n := range occurrences {
+		if n > 1 && len(neighbors[pt]) > 2 {
+			junctions[pt] = true
+		}
+	}
+
+	return junctions
+}
+
+// arcReversed reports whether the arc runs opposite to its canonical direction
+func arcReversed(arc []maths.Pt) bool {
+	first, last := arc[0], arc[len(arc)-1]
+	if first == last && len(arc) > 2 {
+		first, last = arc[1], arc[len(arc)-2]
+	}
+
+	return ptLess(last, first)
+}
+
+func ptLess(a, b maths.Pt) bool {
+	return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+}
+
+func reversePts(pts []maths.Pt) {
+	for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+		pts[i], pts[j] = pts[j], pts[i]
+	}
+}
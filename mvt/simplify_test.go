@@ -0,0 +1,54 @@
+package mvt
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/maths"
+)
+
+func TestSimplifyPreserveTopology(t *testing.T) {
+	//	two polygons sharing the boundary between (10,0) and (10,10). the rings walk the boundary in opposite directions
+	left := basic.Polygon{{{0, 0}, {10, 0}, {10, 3}, {11, 5}, {10, 7}, {10, 10}, {0, 10}}}
+	right := basic.Polygon{{{20, 0}, {20, 10}, {10, 10}, {10, 7}, {11, 5}, {10, 3}, {10, 0}}}
+
+	//	the points of the ring between (10,0) and (10,10)
+	shared := func(p basic.Polygon, onBoundary func(pt maths.Pt) bool) (pts []maths.Pt) {
+		for _, pt := range p[0].AsPts() {
+			if onBoundary(pt) {
+				pts = append(pts, pt)
+			}
+		}
+		sort.Slice(pts, func(i, j int) bool { return ptLess(pts[i], pts[j]) })
+		return pts
+	}
+
+	for _, algorithm := range []string{SimplifyDouglasPeucker, SimplifyVisvalingam} {
+		s := simplifier{
+			algorithm: algorithm,
+			locked:    sharedJunctions([]tegola.Geometry{left, right}),
+		}
+
+		if !s.locked[maths.Pt{X: 10, Y: 0}] || !s.locked[maths.Pt{X: 10, Y: 10}] || len(s.locked) != 2 {
+			t.Errorf("[%v] junctions, expected (10,0) and (10,10) got %v", algorithm, s.locked)
+			continue
+		}
+
+		sl := s.simplifyPolygon(left, 0.95, true)
+		sr := s.simplifyPolygon(right, 0.95, true)
+		if sl == nil || sr == nil {
+			t.Errorf("[%v] unexpected nil polygon", algorithm)
+			continue
+		}
+
+		leftShared := shared(sl, func(pt maths.Pt) bool { return pt.X >= 10 })
+		rightShared := shared(sr, func(pt maths.Pt) bool { return pt.X <= 11 })
+
+		if !reflect.DeepEqual(leftShared, rightShared) {
+			t.Errorf("[%v] shared boundary, left %v does not match right %v", algorithm, leftShared, rightShared)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package mvt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/mvt/vector_tile"
+)
+
+// ErrStreamingUnsupported is returned by Stream for layers which need all of their features at once to be
+// encoded: layers which merge lines, dissolve polygons, preserve topology or have a label layer.
+var ErrStreamingUnsupported = errors.New("mvt: streaming is not supported for layers which generalize, preserve topology or have a label layer")
+
+// layerStream holds the features of a streaming layer once their geometries have been encoded
+type layerStream struct {
+	ctx      context.Context
+	tile     *tegola.Tile
+	simplify bool
+	// the first error encountered while encoding a feature. it's returned by VTileLayer
+	err      error
+	features []streamedFeature
+}
+
+// streamedFeature is a feature whose geometry has already been encoded for the tile
+type streamedFeature struct {
+	id       *uint64
+	tags     map[string]interface{}
+	geometry []uint32
+	gtype    vectorTile.Tile_GeomType
+}
+
+// Stream switches the layer to encoding the features as they are added, instead of when the layer is encoded,
+// so only the encoded geometries are kept in memory. The layer's extent, buffer and tolerance must be set
+// before streaming. Errors encountered while encoding the features are returned by VTileLayer.
+// Features which are streamed are not returned by Features and can't be removed.
+func (l *Layer) Stream(ctx context.Context, tile *tegola.Tile) error {
+	if l.PreserveTopology || len(l.MergeLinesBy) > 0 || len(l.DissolvePolygonsBy) > 0 || l.LabelLayer != "" {
+		return ErrStreamingUnsupported
+	}
+
+	tile = l.layerTile(tile)
+
+	l.validationStats = ValidationStats{}
+	l.stream = &layerStream{
+		ctx:      ctx,
+		tile:     tile,
+		simplify: l.simplify(tile),
+	}
+
+	return nil
+}
+
+// streamFeature encodes the geometry of the feature. features without a geometry in the tile are dropped.
+func (l *Layer) streamFeature(f Feature) {
+	st := l.stream
+	if st.err != nil {
+		return
+	}
+	if err := st.ctx.Err(); err != nil {
+		st.err = err
+		return
+	}
+
+	geo, err := l.prepareFeature(st.ctx, st.tile, f, simplifier{algorithm: l.SimplifyAlgorithm}, st.simplify)
+	if err != nil {
+		st.err = err
+		return
+	}
+	if geo == nil {
+		return
+	}
+
+	g, gtype, err := encodePreparedGeometry(geo, st.tile)
+	if err != nil {
+		st.err = fmt.Errorf("Error getting VTileFeature: %v", err)
+		return
+	}
+	if len(g) == 0 {
+		return
+	}
+
+	st.features = append(st.features, streamedFeature{
+		id:       f.ID,
+		tags:     l.encodeTags(f.Tags),
+		geometry: g,
+		gtype:    gtype,
+	})
+}
+
+// prepareFeature prepares the geometry of a single feature like prepareFeatures does for features
+// which don't depend on the other features of the layer.
+func (l *Layer) prepareFeature(ctx context.Context, tile *tegola.Tile, f Feature, s simplifier, simplify bool) (tegola.Geometry, error) {
+	if f.Geometry == nil {
+		return nil, fmt.Errorf("Error getting VTileFeature: %v", ErrNilGeometryType)
+	}
+
+	if isGeom(f.Geometry) {
+		return l.prepareGeom(ctx, tile, f, s, simplify)
+	}
+
+	geo, err := toLegacyGeometry(f.Geometry)
+	if err != nil {
+		return nil, err
+	}
+
+	sg := s.simplifyGeometry(scaleGeometry(geo, tile), tile.ZEpislon(), simplify)
+	return l.validateGeometry(ctx, tile, f, sg)
+}
+
+// vtileLayer builds the vectorTile Tile_Layer from the streamed features
+func (st *layerStream) vtileLayer(name string, version int) (*vectorTile.Tile_Layer, error) {
+	if st.err != nil {
+		return nil, st.err
+	}
+
+	// the keys and values are collected from the tags of the features
+	tagged := make([]Feature, len(st.features))
+	for i := range st.features {
+		tagged[i].Tags = st.features[i].tags
+	}
+
+	kmap, vmap, err := keyvalMapsFromFeatures(tagged)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]*vectorTile.Tile_Feature, 0, len(st.features))
+	for i := range st.features {
+		tags, err := keyvalTagsMap(kmap, vmap, &tagged[i])
+		if err != nil {
+			return nil, fmt.Errorf("Error getting VTileFeature: %v", err)
+		}
+
+		gtype := st.features[i].gtype
+		features = append(features, &vectorTile.Tile_Feature{
+			Id:       st.features[i].id,
+			Tags:     tags,
+			Type:     &gtype,
+			Geometry: st.features[i].geometry,
+		})
+	}
+
+	ext := uint32(st.tile.Extent)
+	v := uint32(version)
+
+	return &vectorTile.Tile_Layer{
+		Version:  &v,
+		Name:     &name,
+		Features: features,
+		Keys:     kmap,
+		Values:   valMapToVTileValue(vmap),
+		Extent:   &ext,
+	}, nil
+}
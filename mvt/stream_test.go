@@ -0,0 +1,66 @@
+package mvt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/geom"
+)
+
+func TestLayerStream(t *testing.T) {
+	tile := tegola.NewTile(2, 1, 1)
+
+	features := geomFeatures(t, tile,
+		geom.Point{10, 20},
+		geom.LineString{{10, 10}, {100, 10}, {100, 100}, {200, 300}, {400, 50}, {5000, 500}},
+		geom.Polygon{
+			{{100, 100}, {1000, 100}, {1000, 1000}, {100, 1000}},
+		},
+	)
+	//	the legacy types are streamed too
+	for i, f := range legacyFeatures(t, features) {
+		id := uint64(100 + i)
+		f.ID = &id
+		features = append(features, f)
+	}
+	//	a duplicate is skipped
+	features = append(features, features[0])
+
+	collected := Layer{Name: "layer"}
+	collected.AddFeatures(features...)
+	expected, err := collected.VTileLayer(context.Background(), tile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	streamed := Layer{Name: "layer"}
+	if err := streamed.Stream(context.Background(), tile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range features {
+		streamed.AddFeatures(f)
+	}
+
+	if n := len(streamed.stream.features); n != 6 {
+		t.Errorf("expected 6 streamed features, got %v", n)
+	}
+	if len(streamed.Features()) != 0 {
+		t.Errorf("expected the streamed features to not be kept, got %v", len(streamed.Features()))
+	}
+
+	got, err := streamed.VTileLayer(context.Background(), tile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("encoded layer\nexpected %v\n     got %v", expected, got)
+	}
+
+	generalized := Layer{Name: "layer", MergeLinesBy: []string{"name"}}
+	if err := generalized.Stream(context.Background(), tile); err != ErrStreamingUnsupported {
+		t.Errorf("expected %v got %v", ErrStreamingUnsupported, err)
+	}
+}
@@ -0,0 +1,205 @@
+package mvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Supported encodings of list and map tag values, which have no vector tile value type.
+// Lists are always encoded as JSON strings.
+const (
+	// ComplexTagsMerge adds the entries of maps to the tags of the feature without overwriting
+	// the feature's tags. Nested maps are encoded as JSON strings. This is the default.
+	ComplexTagsMerge = "merge"
+	// ComplexTagsFlatten adds the entries of maps, including nested maps, to the tags of the feature
+	// with their keys prefixed by the key of the map and the TagKeySeparator (i.e. "tags.name").
+	ComplexTagsFlatten = "flatten"
+	// ComplexTagsJSON encodes maps as JSON strings.
+	ComplexTagsJSON = "json"
+)
+
+// Supported vector tile value types for integer tag values. By default the value type
+// follows the Go type of the value.
+const (
+	IntegerTagsSint = "sint"
+	IntegerTagsInt  = "int"
+	// IntegerTagsUint encodes negative values as sint
+	IntegerTagsUint = "uint"
+)
+
+// DefaultTagKeySeparator joins the keys of flattened maps
+const DefaultTagKeySeparator = "."
+
+// sintValue is an integer encoded as a vector tile sint value
+type sintValue int64
+
+// encodeTags converts the tag values the vector tile spec has no value type for using the layer's ComplexTags
+// and IntegerTags settings. The tags are returned as is when there is nothing to convert.
+func (l *Layer) encodeTags(tags map[string]interface{}) map[string]interface{} {
+	convert := false
+	for _, v := range tags {
+		if l.IntegerTags != "" || !scalarTag(v) {
+			convert = true
+			break
+		}
+	}
+	if !convert {
+		return tags
+	}
+
+	tags = l.ExpandTags(tags)
+
+	encoded := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		encoded[k] = l.encodeTag(v)
+	}
+
+	return encoded
+}
+
+// ExpandTags adds the entries of map tag values to the tags according to the layer's ComplexTags
+// setting, so they can be read like the feature's other tags (i.e. to add default tags or rank
+// features). The feature's own tags take precedence. No maps are left once expanded, except with
+// ComplexTagsJSON, so expanding the tags again returns them as is. The tags are not modified.
+func (l *Layer) ExpandTags(tags map[string]interface{}) map[string]interface{} {
+	if l.ComplexTags == ComplexTagsJSON {
+		return tags
+	}
+
+	var maps []string
+	for k, v := range tags {
+		if reflect.ValueOf(v).Kind() == reflect.Map {
+			maps = append(maps, k)
+		}
+	}
+	if len(maps) == 0 {
+		return tags
+	}
+	sort.Strings(maps)
+
+	expanded := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		if reflect.ValueOf(v).Kind() != reflect.Map {
+			expanded[k] = v
+		}
+	}
+
+	// the feature's own tags take precedence, so maps are added last
+	for _, k := range maps {
+		rv := reflect.ValueOf(tags[k])
+		switch l.ComplexTags {
+		case ComplexTagsFlatten:
+			l.flattenTag(expanded, k, rv)
+		default:
+			for _, mk := range sortedMapKeys(rv) {
+				if _, ok := expanded[mk.String()]; ok {
+					continue
+				}
+				v := rv.MapIndex(mk).Interface()
+				// nested maps are encoded as JSON strings
+				if reflect.ValueOf(v).Kind() == reflect.Map {
+					v = l.encodeTag(v)
+				}
+				expanded[mk.String()] = v
+			}
+		}
+	}
+
+	return expanded
+}
+
+// flattenTag adds the entries of the map to the tags with their keys prefixed by the key of the map
+func (l *Layer) flattenTag(tags map[string]interface{}, prefix string, rv reflect.Value) {
+	sep := l.TagKeySeparator
+	if sep == "" {
+		sep = DefaultTagKeySeparator
+	}
+
+	for _, mk := range sortedMapKeys(rv) {
+		key := prefix + sep + mk.String()
+		v := rv.MapIndex(mk).Interface()
+
+		if nested := reflect.ValueOf(v); nested.Kind() == reflect.Map {
+			l.flattenTag(tags, key, nested)
+			continue
+		}
+		if _, ok := tags[key]; ok {
+			continue
+		}
+		tags[key] = v
+	}
+}
+
+// encodeTag converts a single tag value. Lists and maps are encoded as JSON strings.
+func (l *Layer) encodeTag(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil, string, bool, float32, float64, fmt.Stringer:
+		return v
+	case []byte:
+		return string(t)
+	case int, int8, int16, int32, int64:
+		if l.IntegerTags == "" {
+			return v
+		}
+		return l.encodeInteger(reflect.ValueOf(t).Int())
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(t).Uint()
+		if l.IntegerTags == "" || u > math.MaxInt64 {
+			return v
+		}
+		return l.encodeInteger(int64(u))
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		b, err := json.Marshal(v)
+		if err != nil {
+			// the value is dropped
+			return nil
+		}
+		return string(b)
+	}
+
+	return v
+}
+
+// encodeInteger returns the integer as the Go type of the layer's IntegerTags value type
+func (l *Layer) encodeInteger(i int64) interface{} {
+	switch l.IntegerTags {
+	case IntegerTagsSint:
+		return sintValue(i)
+	case IntegerTagsUint:
+		if i < 0 {
+			return sintValue(i)
+		}
+		return uint64(i)
+	default:
+		return i
+	}
+}
+
+// scalarTag reports whether the value has a vector tile value type
+func scalarTag(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, bool, float32, float64, fmt.Stringer,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
+
+// sortedMapKeys returns the string keys of the map in order. keys which are not strings are skipped.
+func sortedMapKeys(rv reflect.Value) []reflect.Value {
+	var keys []reflect.Value
+	for _, k := range rv.MapKeys() {
+		if k.Kind() == reflect.String {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	return keys
+}
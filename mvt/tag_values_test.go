@@ -0,0 +1,197 @@
+package mvt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/geom"
+)
+
+func TestLayerEncodeTags(t *testing.T) {
+	tags := map[string]interface{}{
+		"name":  "main st",
+		"lanes": int64(2),
+		"ref":   []string{"A1", "E15"},
+		"tags": map[string]interface{}{
+			"name":    "overwritten",
+			"surface": "asphalt",
+			"oneway":  map[string]interface{}{"forward": true},
+		},
+	}
+
+	testcases := []struct {
+		layer    Layer
+		tags     map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			// scalars are returned as is
+			tags:     map[string]interface{}{"name": "main st", "lanes": 2},
+			expected: map[string]interface{}{"name": "main st", "lanes": 2},
+		},
+		{
+			layer: Layer{ComplexTags: ComplexTagsMerge},
+			tags:  tags,
+			expected: map[string]interface{}{
+				"name":    "main st",
+				"lanes":   int64(2),
+				"ref":     `["A1","E15"]`,
+				"surface": "asphalt",
+				"oneway":  `{"forward":true}`,
+			},
+		},
+		{
+			layer: Layer{ComplexTags: ComplexTagsFlatten},
+			tags:  tags,
+			expected: map[string]interface{}{
+				"name":                "main st",
+				"lanes":               int64(2),
+				"ref":                 `["A1","E15"]`,
+				"tags.name":           "overwritten",
+				"tags.surface":        "asphalt",
+				"tags.oneway.forward": true,
+			},
+		},
+		{
+			layer: Layer{ComplexTags: ComplexTagsFlatten, TagKeySeparator: ":"},
+			tags:  map[string]interface{}{"tags": map[string]string{"name": "main st"}},
+			expected: map[string]interface{}{
+				"tags:name": "main st",
+			},
+		},
+		{
+			layer: Layer{ComplexTags: ComplexTagsJSON},
+			tags:  tags,
+			expected: map[string]interface{}{
+				"name":  "main st",
+				"lanes": int64(2),
+				"ref":   `["A1","E15"]`,
+				"tags":  `{"name":"overwritten","oneway":{"forward":true},"surface":"asphalt"}`,
+			},
+		},
+		{
+			layer:    Layer{IntegerTags: IntegerTagsSint},
+			tags:     map[string]interface{}{"a": 2, "b": uint8(3), "c": int64(-4), "d": 1.5},
+			expected: map[string]interface{}{"a": sintValue(2), "b": sintValue(3), "c": sintValue(-4), "d": 1.5},
+		},
+		{
+			layer:    Layer{IntegerTags: IntegerTagsUint},
+			tags:     map[string]interface{}{"a": 2, "b": int64(-4)},
+			expected: map[string]interface{}{"a": uint64(2), "b": sintValue(-4)},
+		},
+		{
+			layer:    Layer{IntegerTags: IntegerTagsInt},
+			tags:     map[string]interface{}{"a": uint32(2)},
+			expected: map[string]interface{}{"a": int64(2)},
+		},
+	}
+
+	for i, tc := range testcases {
+		got := tc.layer.encodeTags(tc.tags)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestLayerExpandTags(t *testing.T) {
+	hstore := map[string]interface{}{
+		"name":    "overwritten",
+		"surface": "asphalt",
+		"oneway":  map[string]interface{}{"forward": true},
+	}
+
+	testcases := []struct {
+		layer    Layer
+		tags     map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			// the values which are not maps are left as is
+			tags:     map[string]interface{}{"name": "main st", "lanes": 2, "ref": []string{"A1"}},
+			expected: map[string]interface{}{"name": "main st", "lanes": 2, "ref": []string{"A1"}},
+		},
+		{
+			tags: map[string]interface{}{"name": "main st", "tags": hstore},
+			expected: map[string]interface{}{
+				"name":    "main st",
+				"surface": "asphalt",
+				"oneway":  `{"forward":true}`,
+			},
+		},
+		{
+			layer: Layer{ComplexTags: ComplexTagsFlatten},
+			tags:  map[string]interface{}{"name": "main st", "tags": hstore},
+			expected: map[string]interface{}{
+				"name":                "main st",
+				"tags.name":           "overwritten",
+				"tags.surface":        "asphalt",
+				"tags.oneway.forward": true,
+			},
+		},
+		{
+			layer:    Layer{ComplexTags: ComplexTagsJSON},
+			tags:     map[string]interface{}{"tags": hstore},
+			expected: map[string]interface{}{"tags": hstore},
+		},
+	}
+
+	for i, tc := range testcases {
+		got := tc.layer.ExpandTags(tc.tags)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, got)
+		}
+		// expanding again returns the tags as is
+		if again := tc.layer.ExpandTags(got); !reflect.DeepEqual(again, tc.expected) {
+			t.Errorf("[%v] expanded twice, expected %v got %v", i, tc.expected, again)
+		}
+	}
+
+	// the tags are not modified
+	if _, ok := hstore["oneway"].(map[string]interface{}); !ok || len(hstore) != 3 {
+		t.Errorf("expected the map tag to be left as is got %v", hstore)
+	}
+}
+
+func TestLayerEncodeTagValues(t *testing.T) {
+	tile := tegola.NewTile(0, 0, 0)
+	tags := map[string]interface{}{
+		"ref":   []int64{1, 2},
+		"lanes": 2,
+		"tags":  map[string]interface{}{"name": "main st"},
+	}
+
+	l := Layer{Name: "roads", ComplexTags: ComplexTagsFlatten, IntegerTags: IntegerTagsSint}
+	id := uint64(1)
+	l.AddFeatures(Feature{ID: &id, Tags: tags, Geometry: geom.Point{0, 0}})
+
+	vtl, err := l.VTileLayer(context.Background(), tile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the feature's tags are not modified
+	if _, ok := tags["tags"].(map[string]interface{}); !ok {
+		t.Errorf("expected the feature's tags to be unmodified got %v", tags)
+	}
+
+	expectedKeys := []string{"lanes", "ref", "tags.name"}
+	if !reflect.DeepEqual(vtl.Keys, expectedKeys) {
+		t.Errorf("keys, expected %v got %v", expectedKeys, vtl.Keys)
+	}
+
+	if len(vtl.Values) != 3 {
+		t.Fatalf("values, expected 3 got %v", len(vtl.Values))
+	}
+	if v := vtl.Values[0].SintValue; v == nil || *v != 2 {
+		t.Errorf("lanes, expected a sint value of 2 got %v", vtl.Values[0])
+	}
+	if v := vtl.Values[1].StringValue; v == nil || *v != "[1,2]" {
+		t.Errorf("ref, expected a string value of [1,2] got %v", vtl.Values[1])
+	}
+	if v := vtl.Values[2].StringValue; v == nil || *v != "main st" {
+		t.Errorf("tags.name, expected a string value of main st got %v", vtl.Values[2])
+	}
+}
@@ -11,7 +11,8 @@ import (
 
 //Tile describes a tile.
 type Tile struct {
-	layers []Layer
+	// the layers are kept as added so their validation stats are read from the caller's layers
+	layers []*Layer
 }
 
 //AddLayers adds a Layer to the tile
@@ -28,14 +29,16 @@ func (t *Tile) AddLayers(layers ...*Layer) error {
 				return fmt.Errorf("Layer %v, already is named %v, new layer not added.", i, l.Name)
 			}
 		}
-		t.layers = append(t.layers, *nl)
+		t.layers = append(t.layers, nl)
 	}
 	return nil
 }
 
 // Layers returns a copy of the layers in this tile.
 func (t *Tile) Layers() (l []Layer) {
-	l = append(l, t.layers...)
+	for i := range t.layers {
+		l = append(l, *t.layers[i])
+	}
 	return l
 }
 
@@ -54,6 +57,21 @@ func (t *Tile) VTile(ctx context.Context, tile *tegola.Tile) (vt *vectorTile.Til
 			}
 		}
 		vt.Layers = append(vt.Layers, vtl)
+
+		if l.LabelLayer == "" {
+			continue
+		}
+
+		lbl, err := l.VTileLabelLayer(ctx, tile)
+		if err != nil {
+			switch err {
+			case context.Canceled:
+				return nil, err
+			default:
+				return nil, fmt.Errorf("Error Getting VTileLabelLayer: %v", err)
+			}
+		}
+		vt.Layers = append(vt.Layers, lbl)
 	}
 	return vt, nil
 }
@@ -0,0 +1,94 @@
+package mvt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/geom"
+)
+
+func TestTileDeterministic(t *testing.T) {
+	tile := tegola.NewTile(2, 1, 1)
+
+	type tcase struct {
+		layer Layer
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		features := geomFeatures(t, tile,
+			geom.Point{10, 20},
+			geom.LineString{{10, 10}, {100, 10}, {100, 100}, {200, 300}},
+			// touches the end of the previous line so they're merged
+			geom.LineString{{200, 300}, {300, 300}, {300, 400}},
+			// the ring crosses itself so it's made valid
+			geom.Polygon{{{100, 100}, {1000, 1000}, {1000, 100}, {100, 1000}}},
+			// shares an edge with the next polygon so they're dissolved
+			geom.Polygon{{{2000, 2000}, {3000, 2000}, {3000, 3000}, {2000, 3000}}},
+			geom.Polygon{{{3000, 2000}, {4000, 2000}, {4000, 3000}, {3000, 3000}}},
+		)
+		for i := range features {
+			features[i].Tags = map[string]interface{}{
+				"name":   "feature",
+				"class":  []string{"a", "b", "c", "d", "e", "f"}[i],
+				"rank":   i,
+				"area":   float64(i) / 2,
+				"oneway": i%2 == 0,
+			}
+		}
+
+		encode := func() []byte {
+			l := tc.layer
+			l.Name = "layer"
+			l.AddFeatures(features...)
+
+			var mvtTile Tile
+			mvtTile.AddLayers(&l)
+
+			vtile, err := mvtTile.VTile(context.Background(), tile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := proto.Marshal(vtile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return b
+		}
+
+		expected := encode()
+		// map iteration order is randomized on every iteration, so any dependence on it shows up quickly
+		for i := 0; i < 50; i++ {
+			if got := encode(); !bytes.Equal(got, expected) {
+				t.Fatalf("[%v] encoding differs from the first encoding", i)
+			}
+		}
+	}
+
+	tests := map[string]tcase{
+		"features": {},
+		"merge lines and dissolve polygons": {
+			layer: Layer{
+				MergeLinesBy:       []string{"name"},
+				DissolvePolygonsBy: []string{"name"},
+			},
+		},
+		"merge lines and dissolve polygons by tags with distinct values": {
+			layer: Layer{
+				MergeLinesBy:       []string{"name", "class"},
+				DissolvePolygonsBy: []string{"oneway"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
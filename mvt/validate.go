@@ -0,0 +1,275 @@
+package mvt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+	"github.com/go-spatial/tegola/internal/log"
+	"github.com/go-spatial/tegola/maths"
+)
+
+// Supported geometry validation modes.
+const (
+	// ValidationNone encodes the geometries as they are. They are neither clipped nor repaired.
+	ValidationNone = "none"
+	// ValidationClipOnly clips the geometries to the tile's buffered bounds without repairing polygons.
+	ValidationClipOnly = "clip_only"
+	// ValidationMakeValid clips the geometries and repairs invalid polygons. This is the default.
+	ValidationMakeValid = "make_valid"
+	// ValidationStrictReject clips the geometries and drops the features with invalid polygons.
+	ValidationStrictReject = "strict_reject"
+)
+
+// ValidationStats counts the invalid polygons found while encoding a layer.
+type ValidationStats struct {
+	// Repaired is the number of invalid polygons which were made valid.
+	Repaired int
+	// Dropped is the number of features dropped because their polygons were rejected,
+	// or nothing was left of them once they were repaired.
+	Dropped int
+}
+
+// validateGeometry clips the scaled geometry of the feature to the tile and checks its polygons
+// according to the layer's Validation mode. A nil geometry is returned when the feature is dropped.
+func (l *Layer) validateGeometry(ctx context.Context, tile *tegola.Tile, f Feature, geo tegola.Geometry) (tegola.Geometry, error) {
+	switch l.Validation {
+	case ValidationNone:
+		return geo, nil
+	case ValidationClipOnly:
+		return clipGeometry(ctx, geo, tile)
+	}
+
+	switch geo.(type) {
+	case tegola.Polygon, tegola.MultiPolygon:
+	default:
+		return cleanGeometry(ctx, geo, tile)
+	}
+
+	valid := polygonsValid(geo)
+
+	if l.Validation == ValidationStrictReject {
+		if !valid {
+			l.validationStats.Dropped++
+			log.Debugf("dropped feature (%v) with an invalid polygon in tile (z: %v, x: %v, y: %v)", featureID(f), tile.Z, tile.X, tile.Y)
+			return nil, nil
+		}
+		// valid polygons only need clipping
+		return clipGeometry(ctx, geo, tile)
+	}
+
+	clean, err := cleanGeometry(ctx, geo, tile)
+	if err != nil || valid {
+		return clean, err
+	}
+
+	if mp, ok := clean.(tegola.MultiPolygon); ok && len(mp.Polygons()) == 0 {
+		l.validationStats.Dropped++
+		log.Debugf("dropped feature (%v), nothing was left of its invalid polygon once repaired in tile (z: %v, x: %v, y: %v)", featureID(f), tile.Z, tile.X, tile.Y)
+		return nil, nil
+	}
+
+	l.validationStats.Repaired++
+	log.Debugf("repaired the invalid polygon of feature (%v) in tile (z: %v, x: %v, y: %v)", featureID(f), tile.Z, tile.X, tile.Y)
+
+	return clean, nil
+}
+
+func featureID(f Feature) string {
+	if f.ID == nil {
+		return "no id"
+	}
+
+	return fmt.Sprintf("%v", *f.ID)
+}
+
+// clipGeometry clips the scaled geometry to the tile's buffered bounds. Polygons are clipped ring by ring and
+// are not repaired, though their rings are wound as the vector tile spec requires.
+func clipGeometry(ctx context.Context, geo tegola.Geometry, tile *tegola.Tile) (tegola.Geometry, error) {
+	pbb, err := tile.PixelBufferedBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	switch g := geo.(type) {
+	case tegola.Polygon:
+		p := clipPolygon(g, pbb)
+		if p == nil {
+			return nil, nil
+		}
+		return p, nil
+
+	case tegola.MultiPolygon:
+		var mp basic.MultiPolygon
+		for _, p := range g.Polygons() {
+			if cp := clipPolygon(p, pbb); cp != nil {
+				mp = append(mp, cp)
+			}
+		}
+		if len(mp) == 0 {
+			return nil, nil
+		}
+		return mp, nil
+
+	default:
+		// lines are only clipped
+		return cleanGeometry(ctx, geo, tile)
+	}
+}
+
+// clipPolygon clips the rings of the polygon to the bounds using Sutherland–Hodgman. The exterior ring is
+// wound clockwise and the holes counter clockwise. nil is returned when the exterior ring is clipped away.
+func clipPolygon(p tegola.Polygon, bounds [2][2]float64) basic.Polygon {
+	var clipped basic.Polygon
+	for i, ring := range p.Sublines() {
+		pts := clipRing(ringPts(ring), bounds)
+		if len(pts) < 3 {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+
+		order := maths.Clockwise
+		if i > 0 {
+			order = maths.CounterClockwise
+		}
+		if maths.WindingOrderOfPts(pts) != order {
+			reversePts(pts)
+		}
+
+		line := make(basic.Line, len(pts))
+		for j := range pts {
+			line[j] = basic.Point{pts[j].X, pts[j].Y}
+		}
+		clipped = append(clipped, line)
+	}
+
+	return clipped
+}
+
+// clipRing clips the open ring to the bounds one edge of the bounds at a time
+func clipRing(pts []maths.Pt, bounds [2][2]float64) []maths.Pt {
+	minx, miny, maxx, maxy := bounds[0][0], bounds[0][1], bounds[1][0], bounds[1][1]
+
+	edges := []struct {
+		inside    func(pt maths.Pt) bool
+		intersect func(a, b maths.Pt) maths.Pt
+	}{
+		{
+			inside: func(pt maths.Pt) bool { return pt.X >= minx },
+			intersect: func(a, b maths.Pt) maths.Pt {
+				return maths.Pt{X: minx, Y: a.Y + (b.Y-a.Y)*(minx-a.X)/(b.X-a.X)}
+			},
+		},
+		{
+			inside: func(pt maths.Pt) bool { return pt.X <= maxx },
+			intersect: func(a, b maths.Pt) maths.Pt {
+				return maths.Pt{X: maxx, Y: a.Y + (b.Y-a.Y)*(maxx-a.X)/(b.X-a.X)}
+			},
+		},
+		{
+			inside: func(pt maths.Pt) bool { return pt.Y >= miny },
+			intersect: func(a, b maths.Pt) maths.Pt {
+				return maths.Pt{X: a.X + (b.X-a.X)*(miny-a.Y)/(b.Y-a.Y), Y: miny}
+			},
+		},
+		{
+			inside: func(pt maths.Pt) bool { return pt.Y <= maxy },
+			intersect: func(a, b maths.Pt) maths.Pt {
+				return maths.Pt{X: a.X + (b.X-a.X)*(maxy-a.Y)/(b.Y-a.Y), Y: maxy}
+			},
+		},
+	}
+
+	for _, e := range edges {
+		if len(pts) == 0 {
+			return nil
+		}
+
+		in := pts
+		pts = make([]maths.Pt, 0, len(in)+4)
+		prev := in[len(in)-1]
+		for _, cur := range in {
+			switch {
+			case e.inside(cur):
+				if !e.inside(prev) {
+					pts = append(pts, e.intersect(prev, cur))
+				}
+				pts = append(pts, cur)
+			case e.inside(prev):
+				pts = append(pts, e.intersect(prev, cur))
+			}
+			prev = cur
+		}
+	}
+
+	return pts
+}
+
+// ringPts returns the points of the ring without the closing point or repeated points
+func ringPts(ring tegola.LineString) []maths.Pt {
+	subpts := ring.Subpoints()
+	pts := make([]maths.Pt, 0, len(subpts))
+	for _, pt := range subpts {
+		p := maths.Pt{X: pt.X(), Y: pt.Y()}
+		if len(pts) > 0 && pts[len(pts)-1] == p {
+			continue
+		}
+		pts = append(pts, p)
+	}
+	if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+
+	return pts
+}
+
+// polygonsValid reports whether the polygons of the geometry are valid: every ring has an area
+// and no ring crosses or touches itself or another ring.
+func polygonsValid(geo tegola.Geometry) bool {
+	var polygons []tegola.Polygon
+	switch g := geo.(type) {
+	case tegola.Polygon:
+		polygons = []tegola.Polygon{g}
+	case tegola.MultiPolygon:
+		polygons = g.Polygons()
+	}
+
+	type segment struct {
+		ring, idx, count int
+	}
+
+	var lines []maths.Line
+	var segments []segment
+	var ring int
+	for _, p := range polygons {
+		for _, r := range p.Sublines() {
+			pts := ringPts(r)
+			if len(pts) < 3 || maths.AreaOfRing(pts...) == 0 {
+				return false
+			}
+
+			for i := range pts {
+				lines = append(lines, maths.Line{pts[i], pts[(i+1)%len(pts)]})
+				segments = append(segments, segment{ring: ring, idx: i, count: len(pts)})
+			}
+			ring++
+		}
+	}
+
+	valid := true
+	maths.FindIntersects(lines, func(src, dest int, _ func() maths.Pt) bool {
+		s, d := segments[src], segments[dest]
+		// consecutive segments of a ring share an end point
+		if s.ring == d.ring && ((s.idx+1)%s.count == d.idx || (d.idx+1)%d.count == s.idx) {
+			return true
+		}
+
+		valid = false
+		return false
+	})
+
+	return valid
+}
@@ -0,0 +1,107 @@
+package mvt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/tegola"
+	"github.com/go-spatial/tegola/basic"
+)
+
+func TestLayerValidateGeometry(t *testing.T) {
+	// the ring crosses itself
+	bowtie := basic.Polygon{{{0, 0}, {100, 100}, {100, 0}, {0, 100}}}
+	square := basic.Polygon{{{0, 0}, {100, 0}, {100, 100}, {0, 100}}}
+	// extends past the tile's buffer of 64 pixels
+	overhanging := basic.Polygon{{{-100, -100}, {100, -100}, {100, 100}, {-100, 100}}}
+
+	testcases := []struct {
+		validation string
+		geo        tegola.Geometry
+		expected   tegola.Geometry
+		stats      ValidationStats
+	}{
+		{
+			validation: ValidationNone,
+			geo:        bowtie,
+			expected:   bowtie,
+		},
+		{
+			validation: ValidationClipOnly,
+			geo:        overhanging,
+			expected:   basic.Polygon{{{-64, -64}, {100, -64}, {100, 100}, {-64, 100}}},
+		},
+		{
+			validation: ValidationClipOnly,
+			geo:        basic.Polygon{{{-200, -200}, {-100, -200}, {-100, -100}}},
+			expected:   nil,
+		},
+		{
+			validation: ValidationStrictReject,
+			geo:        bowtie,
+			expected:   nil,
+			stats:      ValidationStats{Dropped: 1},
+		},
+		{
+			validation: ValidationStrictReject,
+			geo:        square,
+			expected:   square,
+		},
+	}
+
+	tile := tegola.NewTile(0, 0, 0)
+	for i, tc := range testcases {
+		l := Layer{Validation: tc.validation}
+
+		geo, err := l.validateGeometry(context.Background(), tile, Feature{}, tc.geo)
+		if err != nil {
+			t.Errorf("[%v] unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(geo, tc.expected) {
+			t.Errorf("[%v] geometry, expected %v got %v", i, tc.expected, geo)
+		}
+
+		if l.ValidationStats() != tc.stats {
+			t.Errorf("[%v] stats, expected %+v got %+v", i, tc.stats, l.ValidationStats())
+		}
+	}
+}
+
+func TestLayerValidateGeometryMakeValid(t *testing.T) {
+	tile := tegola.NewTile(0, 0, 0)
+
+	testcases := []struct {
+		geo   tegola.Geometry
+		stats ValidationStats
+	}{
+		{
+			geo: basic.Polygon{{{0, 0}, {100, 0}, {100, 100}, {0, 100}}},
+		},
+		{
+			geo:   basic.Polygon{{{0, 0}, {100, 100}, {100, 0}, {0, 100}}},
+			stats: ValidationStats{Repaired: 1},
+		},
+	}
+
+	for i, tc := range testcases {
+		// make_valid is the default
+		var l Layer
+
+		geo, err := l.validateGeometry(context.Background(), tile, Feature{}, tc.geo)
+		if err != nil {
+			t.Errorf("[%v] unexpected error: %v", i, err)
+			continue
+		}
+
+		if mp, ok := geo.(tegola.MultiPolygon); !ok || len(mp.Polygons()) == 0 {
+			t.Errorf("[%v] expected a polygon got %v", i, geo)
+		}
+
+		if l.ValidationStats() != tc.stats {
+			t.Errorf("[%v] stats, expected %+v got %+v", i, tc.stats, l.ValidationStats())
+		}
+	}
+}
@@ -1,18 +1,42 @@
 package provider
 
 import (
+	"fmt"
+	"hash/fnv"
+	"math"
 	"strconv"
 
 	"github.com/go-spatial/tegola/geom"
 )
 
 type Feature struct {
-	ID       uint64
+	ID uint64
+	// IDValue is the feature's ID as read from the data source, before it was converted to ID.
+	// Providers set it so IDs which are not unsigned integers (i.e. UUIDs) can be encoded
+	// with EncodeFeatureID. When nil, ID is used.
+	IDValue  interface{}
 	Geometry geom.Geometry
 	SRID     uint64
 	Tags     map[string]interface{}
 }
 
+// Supported strategies for encoding feature IDs as vector tile feature IDs
+const (
+	// FeatureIDUint converts the ID with ConvertFeatureID. IDs which are not integers or numeric strings
+	// are rejected. This is the default.
+	FeatureIDUint = "uint"
+	// FeatureIDHash hashes string and binary IDs (i.e. UUIDs) to a stable uint64 with 64-bit FNV-1a.
+	// Integer IDs are converted with ConvertFeatureID. Layers deduplicate the features of a tile by ID,
+	// so if two IDs of a tile hash to the same value the second feature is dropped unless the layer
+	// sets DontDeduplicate. A collision is unlikely below billions of features in a tile.
+	FeatureIDHash = "hash"
+	// FeatureIDSigned zigzag encodes signed IDs so negative IDs don't wrap around:
+	// 0, -1, 1, -2, 2 are encoded as 0, 1, 2, 3, 4.
+	FeatureIDSigned = "signed"
+	// FeatureIDNone omits the feature ID
+	FeatureIDNone = "none"
+)
+
 // ConvertFeatureID attempts to convert an interface value to an uint64
 func ConvertFeatureID(v interface{}) (uint64, error) {
 	switch aval := v.(type) {
@@ -40,3 +64,99 @@ func ConvertFeatureID(v interface{}) (uint64, error) {
 		return 0, ErrUnableToConvertFeatureID{val: v}
 	}
 }
+
+// EncodeFeatureID converts the ID read from a data source to a vector tile feature ID using the strategy
+// (i.e. FeatureIDHash). An empty strategy is FeatureIDUint. nil is returned for FeatureIDNone.
+func EncodeFeatureID(v interface{}, strategy string) (*uint64, error) {
+	var (
+		id  uint64
+		err error
+	)
+
+	switch strategy {
+	case FeatureIDNone:
+		return nil, nil
+	case "", FeatureIDUint:
+		id, err = ConvertFeatureID(v)
+	case FeatureIDHash:
+		id, err = hashFeatureID(v)
+	case FeatureIDSigned:
+		id, err = signedFeatureID(v)
+	default:
+		return nil, fmt.Errorf("provider: unsupported feature id strategy (%v)", strategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// hashFeatureID hashes string and binary IDs. 16 byte arrays are hashed in the textual form
+// of a UUID so a UUID hashes the same whether it's read as text or bytes.
+func hashFeatureID(v interface{}) (uint64, error) {
+	var b []byte
+	switch aval := v.(type) {
+	case string:
+		b = []byte(aval)
+	case []byte:
+		b = aval
+	case [16]byte:
+		b = []byte(fmt.Sprintf("%x-%x-%x-%x-%x", aval[0:4], aval[4:6], aval[6:8], aval[8:10], aval[10:16]))
+	default:
+		return ConvertFeatureID(v)
+	}
+
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64(), nil
+}
+
+// signedFeatureID zigzag encodes the ID. Floats must hold integers, they're not truncated.
+func signedFeatureID(v interface{}) (uint64, error) {
+	var i int64
+	switch aval := v.(type) {
+	case float64:
+		if aval != math.Trunc(aval) || aval < math.MinInt64 || aval >= math.MaxInt64 {
+			return 0, ErrUnableToConvertFeatureID{val: v}
+		}
+		i = int64(aval)
+	case float32:
+		if f := float64(aval); f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, ErrUnableToConvertFeatureID{val: v}
+		}
+		i = int64(aval)
+	case int:
+		i = int64(aval)
+	case int8:
+		i = int64(aval)
+	case int16:
+		i = int64(aval)
+	case int32:
+		i = int64(aval)
+	case int64:
+		i = aval
+	case uint:
+		i = int64(aval)
+	case uint8:
+		i = int64(aval)
+	case uint16:
+		i = int64(aval)
+	case uint32:
+		i = int64(aval)
+	case uint64:
+		if aval > math.MaxInt64 {
+			return 0, ErrUnableToConvertFeatureID{val: v}
+		}
+		i = int64(aval)
+	case string:
+		var err error
+		if i, err = strconv.ParseInt(aval, 10, 64); err != nil {
+			return 0, ErrUnableToConvertFeatureID{val: v}
+		}
+	default:
+		return 0, ErrUnableToConvertFeatureID{val: v}
+	}
+
+	return uint64((i << 1) ^ (i >> 63)), nil
+}
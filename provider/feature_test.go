@@ -0,0 +1,69 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/go-spatial/tegola/provider"
+)
+
+func TestEncodeFeatureID(t *testing.T) {
+	uuid := "0d4f6ad9-54b0-4f3f-9b4b-1f3b0a4d6c2e"
+	uuidBytes := [16]byte{0x0d, 0x4f, 0x6a, 0xd9, 0x54, 0xb0, 0x4f, 0x3f, 0x9b, 0x4b, 0x1f, 0x3b, 0x0a, 0x4d, 0x6c, 0x2e}
+
+	testcases := []struct {
+		id       interface{}
+		strategy string
+		expected uint64
+		omitted  bool
+		err      bool
+	}{
+		{id: int64(42), strategy: "", expected: 42},
+		{id: "42", strategy: provider.FeatureIDUint, expected: 42},
+		{id: uuid, strategy: provider.FeatureIDUint, err: true},
+		{id: uuid, strategy: provider.FeatureIDHash, expected: 0xf8d4ac4fda009853},
+		// a UUID hashes the same as text or bytes
+		{id: uuidBytes, strategy: provider.FeatureIDHash, expected: 0xf8d4ac4fda009853},
+		{id: int32(7), strategy: provider.FeatureIDHash, expected: 7},
+		{id: int64(0), strategy: provider.FeatureIDSigned, expected: 0},
+		{id: int64(-1), strategy: provider.FeatureIDSigned, expected: 1},
+		{id: int64(1), strategy: provider.FeatureIDSigned, expected: 2},
+		{id: "-2", strategy: provider.FeatureIDSigned, expected: 3},
+		{id: uuid, strategy: provider.FeatureIDSigned, err: true},
+		{id: float64(-3), strategy: provider.FeatureIDSigned, expected: 5},
+		// floats are not truncated
+		{id: float64(-3.5), strategy: provider.FeatureIDSigned, err: true},
+		{id: float32(1.25), strategy: provider.FeatureIDSigned, err: true},
+		{id: float64(1 << 63), strategy: provider.FeatureIDSigned, err: true},
+		{id: uuid, strategy: provider.FeatureIDNone, omitted: true},
+		{id: int64(1), strategy: "uuid", err: true},
+	}
+
+	for i, tc := range testcases {
+		id, err := provider.EncodeFeatureID(tc.id, tc.strategy)
+		if tc.err {
+			if err == nil {
+				t.Errorf("[%v] expected an error got id %v", i, id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%v] unexpected error: %v", i, err)
+			continue
+		}
+
+		if tc.omitted {
+			if id != nil {
+				t.Errorf("[%v] expected the id to be omitted got %v", i, *id)
+			}
+			continue
+		}
+
+		if id == nil {
+			t.Errorf("[%v] expected %v got nil", i, tc.expected)
+			continue
+		}
+		if *id != tc.expected {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, *id)
+		}
+	}
+}
@@ -177,10 +177,11 @@ func (p *Provider) TileFeatures(ctx context.Context, layer string, tile provider
 
 			switch cols[i] {
 			case pLayer.idFieldname:
-				feature.ID, err = provider.ConvertFeatureID(vals[i])
-				if err != nil {
-					return err
-				}
+				//	the conversion error is ignored on purpose: the ID is kept as read in IDValue and converted
+				//	again, and its errors reported, by the map layer's id strategy (provider.EncodeFeatureID).
+				//	IDs which aren't unsigned integers (i.e. UUIDs) are only valid with the hash strategy
+				feature.ID, _ = provider.ConvertFeatureID(vals[i])
+				feature.IDValue = vals[i]
 
 			case pLayer.geomFieldname:
 				log.Debugf("extracting geopackage geometry header.", vals[i])
@@ -331,6 +331,13 @@ func (p Provider) layerGeomType(l *Layer) error {
 	return nil
 }
 
+// Close closes the provider's database connection pool. It's called when the provider
+// is no longer in use (i.e. after a config reload)
+func (p *Provider) Close() error {
+	p.pool.Close()
+	return nil
+}
+
 // Layer fetches an individual layer from the provider, if it's configured
 // if no name is provider, the first layer is returned
 func (p *Provider) Layer(name string) (Layer, bool) {
@@ -412,8 +419,14 @@ func (p Provider) TileFeatures(ctx context.Context, layer string, tile provider.
 			return fmt.Errorf("unable to decode layer (%v) geometry field (%v) into wkb where (%v = %v): %v", layer, plyr.GeomFieldName(), plyr.IDFieldName(), gid, err)
 		}
 
+		//	the conversion error is ignored on purpose: the ID is kept as read in IDValue and converted
+		//	again, and its errors reported, by the map layer's id strategy (provider.EncodeFeatureID).
+		//	IDs which aren't unsigned integers (i.e. UUIDs) are only valid with the hash strategy
+		id, _ := provider.ConvertFeatureID(gid)
+
 		feature := provider.Feature{
-			ID:       gid,
+			ID:       id,
+			IDValue:  gid,
 			Geometry: geom,
 			SRID:     plyr.SRID(),
 			Tags:     tags,
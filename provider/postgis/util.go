@@ -116,7 +116,11 @@ func transformVal(valType pgx.Oid, val interface{}) (interface{}, error) {
 		case string:
 			return vt, nil
 		}
-	case pgx.BoolOid, pgx.ByteaOid, pgx.TextOid, pgx.OidOid, pgx.VarcharOid, pgx.JsonbOid:
+	case pgx.BoolOid, pgx.ByteaOid, pgx.TextOid, pgx.OidOid, pgx.VarcharOid, pgx.JsonOid, pgx.JsonbOid:
+		return val, nil
+	// arrays are encoded by the mvt layer
+	case pgx.BoolArrayOid, pgx.Int2ArrayOid, pgx.Int4ArrayOid, pgx.Int8ArrayOid,
+		pgx.Float4ArrayOid, pgx.Float8ArrayOid, pgx.TextArrayOid, pgx.VarcharArrayOid:
 		return val, nil
 	case pgx.Int8Oid, pgx.Int2Oid, pgx.Int4Oid, pgx.Float4Oid, pgx.Float8Oid:
 		switch vt := val.(type) {
@@ -146,7 +150,7 @@ func transformVal(valType pgx.Oid, val interface{}) (interface{}, error) {
 	}
 }
 
-func decipherFields(ctx context.Context, geoFieldname, idFieldname string, descriptions []pgx.FieldDescription, values []interface{}) (gid uint64, geom []byte, tags map[string]interface{}, err error) {
+func decipherFields(ctx context.Context, geoFieldname, idFieldname string, descriptions []pgx.FieldDescription, values []interface{}) (gid interface{}, geom []byte, tags map[string]interface{}, err error) {
 	tags = make(map[string]interface{})
 	var desc pgx.FieldDescription
 	var ok bool
@@ -167,7 +171,8 @@ func decipherFields(ctx context.Context, geoFieldname, idFieldname string, descr
 				return 0, nil, nil, fmt.Errorf("Unable to convert geometry field (%v) into bytes.", geoFieldname)
 			}
 		case idFieldname:
-			gid, err = gId(v)
+			//	the ID is converted by the map layer's id strategy
+			gid = v
 		default:
 			switch desc.DataTypeName {
 			// hstore is a special case
@@ -177,15 +182,16 @@ func decipherFields(ctx context.Context, geoFieldname, idFieldname string, descr
 				if err != nil {
 					return gid, geom, tags, fmt.Errorf("Unable to parse Hstore err: %v", err)
 				}
+				// the hstore is kept as a map under the column name. the map layer merges, flattens
+				// or encodes it depending on the layer's complex_tags setting
+				hstore := make(map[string]interface{}, len(keys))
 				for i, k := range keys {
-					// if the value is Valid (i.e. not null) then add it to our tags map.
+					// if the value is Valid (i.e. not null) then add it to our map.
 					if values[i].Valid {
-						//	we need to check if the key already exists. if it does, then don't overwrite it
-						if _, ok := tags[k]; !ok {
-							tags[k] = values[i].String
-						}
+						hstore[k] = values[i].String
 					}
 				}
+				tags[desc.Name] = hstore
 				continue
 			case "numeric":
 				num, err := strconv.ParseFloat(v.(string), 64)
@@ -206,29 +212,3 @@ func decipherFields(ctx context.Context, geoFieldname, idFieldname string, descr
 	return gid, geom, tags, err
 }
 
-func gId(v interface{}) (gid uint64, err error) {
-	switch aval := v.(type) {
-	case float64:
-		return uint64(aval), nil
-	case int64:
-		return uint64(aval), nil
-	case uint64:
-		return aval, nil
-	case uint:
-		return uint64(aval), nil
-	case int8:
-		return uint64(aval), nil
-	case uint8:
-		return uint64(aval), nil
-	case uint16:
-		return uint64(aval), nil
-	case int32:
-		return uint64(aval), nil
-	case uint32:
-		return uint64(aval), nil
-	case string:
-		return strconv.ParseUint(aval, 10, 64)
-	default:
-		return gid, fmt.Errorf("Unable to convert field into a uint64.")
-	}
-}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/go-spatial/tegola/geom"
@@ -99,3 +100,15 @@ func Cleanup() {
 		}
 	}
 }
+
+// Release frees the resources held by a single provider instance, for example when a config
+// reload replaces it. Providers that hold resources (i.e. connection pools) implement io.Closer.
+// Unlike Cleanup, Release does not affect other instances of the same provider type.
+func Release(t Tiler) error {
+	c, ok := t.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	return c.Close()
+}
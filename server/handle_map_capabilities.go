@@ -10,8 +10,6 @@ import (
 	"github.com/dimfeld/httptreemux"
 
 	"github.com/go-spatial/tegola/atlas"
-	"github.com/go-spatial/tegola/geom"
-	"github.com/go-spatial/tegola/mapbox/tilejson"
 )
 
 type HandleMapCapabilities struct {
@@ -19,13 +17,16 @@ type HandleMapCapabilities struct {
 	mapName string
 	//	the requests extension defaults to "json"
 	extension string
+	//	high-DPI scale (i.e. 2 for @2x)
+	//	defaults to 1
+	scale uint64
 }
 
 //	returns details about a map according to the
 //	tileJSON spec (https://github.com/mapbox/tilejson-spec/tree/master/2.1.0)
 //
 //	URI scheme: /capabilities/:map_name.json
-//		map_name - map name in the config file
+//		map_name - map name in the config file. can be suffixed with a high-DPI scale (i.e. osm@2x)
 func (req HandleMapCapabilities) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	params := httptreemux.ContextParams(r.Context())
@@ -34,7 +35,14 @@ func (req HandleMapCapabilities) ServeHTTP(w http.ResponseWriter, r *http.Reques
 	mapName := params["map_name"]
 	mapNameParts := strings.Split(mapName, ".")
 
-	req.mapName = mapNameParts[0]
+	//	check if we have a high-DPI scale (i.e. @2x)
+	var err error
+	req.mapName, req.scale, err = splitScale(mapNameParts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	//	check if we have a provided extension
 	if len(mapNameParts) > 2 {
 		req.extension = mapNameParts[len(mapNameParts)-1]
@@ -50,17 +58,13 @@ func (req HandleMapCapabilities) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	tileJSON := tilejson.TileJSON{
-		Attribution: &m.Attribution,
-		Bounds:      m.Bounds,
-		Center:      m.Center,
-		Format:      "pbf",
-		Name:        &m.Name,
-		Scheme:      tilejson.SchemeXYZ,
-		TileJSON:    tilejson.Version,
-		Version:     "1.0.0",
-		Grids:       make([]string, 0),
-		Data:        make([]string, 0),
+	//	scale the map for high-DPI requests
+	m = m.ScaleTileSize(req.scale)
+
+	//	tile URLs for high-DPI requests use the scale suffix
+	var scaleSuffix string
+	if req.scale > 1 {
+		scaleSuffix = fmt.Sprintf("@%vx", req.scale)
 	}
 
 	//	parse our query string
@@ -75,79 +79,13 @@ func (req HandleMapCapabilities) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		m = m.AddDebugLayers()
 	}
 
-	for i := range m.Layers {
-		//	check if the layer already exists in our slice. this can happen if the config
-		//	is using the "name" param for a layer to override the providerLayerName
-		var skip bool
-		for j := range tileJSON.VectorLayers {
-			if tileJSON.VectorLayers[j].ID == m.Layers[i].MVTName() {
-				//	we need to use the min and max of all layers with this name
-				if tileJSON.VectorLayers[j].MinZoom > m.Layers[i].MinZoom {
-					tileJSON.VectorLayers[j].MinZoom = m.Layers[i].MinZoom
-				}
-
-				if tileJSON.VectorLayers[j].MaxZoom < m.Layers[i].MaxZoom {
-					tileJSON.VectorLayers[j].MaxZoom = m.Layers[i].MaxZoom
-				}
-
-				skip = true
-				break
-			}
-		}
-		//	entry for layer already exists. move on
-		if skip {
-			continue
-		}
-
-		//	the first layer sets the initial min / max otherwise they default to 0/0
-		if len(tileJSON.VectorLayers) == 0 {
-			tileJSON.MinZoom = m.Layers[i].MinZoom
-			tileJSON.MaxZoom = m.Layers[i].MaxZoom
-		}
-
-		//	check if we have a min zoom lower then our current min
-		if tileJSON.MinZoom > m.Layers[i].MinZoom {
-			tileJSON.MinZoom = m.Layers[i].MinZoom
+	tileJSON := m.TileJSON(func(layerName string) string {
+		if layerName == "" {
+			return fmt.Sprintf("%v://%v/maps/%v/{z}/{x}/{y}%v.pbf%v", scheme(r), hostName(r), req.mapName, scaleSuffix, debugQuery)
 		}
 
-		//	check if we have a max zoom higher then our current max
-		if tileJSON.MaxZoom < m.Layers[i].MaxZoom {
-			tileJSON.MaxZoom = m.Layers[i].MaxZoom
-		}
-
-		//	build our vector layer details
-		layer := tilejson.VectorLayer{
-			Version: 2,
-			Extent:  4096,
-			ID:      m.Layers[i].MVTName(),
-			Name:    m.Layers[i].MVTName(),
-			MinZoom: m.Layers[i].MinZoom,
-			MaxZoom: m.Layers[i].MaxZoom,
-			Tiles: []string{
-				fmt.Sprintf("%v://%v/maps/%v/%v/{z}/{x}/{y}.pbf%v", scheme(r), hostName(r), req.mapName, m.Layers[i].MVTName(), debugQuery),
-			},
-		}
-
-		switch m.Layers[i].GeomType.(type) {
-		case geom.Point, geom.MultiPoint:
-			layer.GeometryType = tilejson.GeomTypePoint
-		case geom.Line, geom.LineString, geom.MultiLineString:
-			layer.GeometryType = tilejson.GeomTypeLine
-		case geom.Polygon, geom.MultiPolygon:
-			layer.GeometryType = tilejson.GeomTypePolygon
-		default:
-			layer.GeometryType = tilejson.GeomTypeUnknown
-			//	TODO: debug log
-		}
-
-		//	add our layer to our tile layer response
-		tileJSON.VectorLayers = append(tileJSON.VectorLayers, layer)
-	}
-
-	tileURL := fmt.Sprintf("%v://%v/maps/%v/{z}/{x}/{y}.pbf%v", scheme(r), hostName(r), req.mapName, debugQuery)
-
-	//	build our URL scheme for the tile grid
-	tileJSON.Tiles = append(tileJSON.Tiles, tileURL)
+		return fmt.Sprintf("%v://%v/maps/%v/%v/{z}/{x}/{y}%v.pbf%v", scheme(r), hostName(r), req.mapName, layerName, scaleSuffix, debugQuery)
+	})
 
 	//	content type
 	w.Header().Add("Content-Type", "application/json")
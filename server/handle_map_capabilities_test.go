@@ -50,6 +50,7 @@ func TestHandleMapCapabilities(t *testing.T) {
 				Grids:    []string{},
 				Data:     []string{},
 				Version:  "1.0.0",
+				TileSize: 256,
 				Template: nil,
 				Legend:   nil,
 				VectorLayers: []tilejson.VectorLayer{
@@ -80,6 +81,60 @@ func TestHandleMapCapabilities(t *testing.T) {
 				},
 			},
 		},
+		{ // high-DPI variant
+			handler:    server.HandleCapabilities{},
+			hostName:   "",
+			uri:        "http://localhost:8080/capabilities/test-map@2x.json",
+			uriPattern: "/capabilities/:map_name",
+			reqMethod:  "GET",
+			expected: tilejson.TileJSON{
+				Attribution: &testMapAttribution,
+				Bounds:      [4]float64{-180.0, -85.0511, 180.0, 85.0511},
+				Center:      testMapCenter,
+				Format:      "pbf",
+				MinZoom:     4,
+				MaxZoom:     testLayer3.MaxZoom, //	the max zoom for the test group is in layer 3
+				Name:        &testMapName,
+				Description: nil,
+				Scheme:      tilejson.SchemeXYZ,
+				TileJSON:    tilejson.Version,
+				Tiles: []string{
+					"http://localhost:8080/maps/test-map/{z}/{x}/{y}@2x.pbf",
+				},
+				Grids:    []string{},
+				Data:     []string{},
+				Version:  "1.0.0",
+				TileSize: 512,
+				Template: nil,
+				Legend:   nil,
+				VectorLayers: []tilejson.VectorLayer{
+					{
+						Version:      2,
+						Extent:       8192,
+						ID:           testLayer1.MVTName(),
+						Name:         testLayer1.MVTName(),
+						GeometryType: tilejson.GeomTypePoint,
+						MinZoom:      testLayer1.MinZoom,
+						MaxZoom:      testLayer3.MaxZoom, //	layer 1 and layer 3 share a name in our test so the zoom range includes the entire zoom range
+						Tiles: []string{
+							fmt.Sprintf("http://localhost:8080/maps/test-map/%v/{z}/{x}/{y}@2x.pbf", testLayer1.MVTName()),
+						},
+					},
+					{
+						Version:      2,
+						Extent:       8192,
+						ID:           testLayer2.MVTName(),
+						Name:         testLayer2.MVTName(),
+						GeometryType: tilejson.GeomTypeLine,
+						MinZoom:      testLayer2.MinZoom,
+						MaxZoom:      testLayer2.MaxZoom,
+						Tiles: []string{
+							fmt.Sprintf("http://localhost:8080/maps/test-map/%v/{z}/{x}/{y}@2x.pbf", testLayer2.MVTName()),
+						},
+					},
+				},
+			},
+		},
 		{
 			handler:    server.HandleCapabilities{},
 			hostName:   "cdn.tegola.io",
@@ -104,6 +159,7 @@ func TestHandleMapCapabilities(t *testing.T) {
 				Grids:    []string{},
 				Data:     []string{},
 				Version:  "1.0.0",
+				TileSize: 256,
 				Template: nil,
 				Legend:   nil,
 				VectorLayers: []tilejson.VectorLayer{
@@ -9,7 +9,6 @@ import (
 
 	"github.com/dimfeld/httptreemux"
 
-	"github.com/go-spatial/tegola"
 	"github.com/go-spatial/tegola/atlas"
 	"github.com/go-spatial/tegola/geom/slippy"
 	"github.com/go-spatial/tegola/internal/log"
@@ -29,6 +28,9 @@ type HandleMapLayerZXY struct {
 	//	the requests extension (i.e. pbf or json)
 	//	defaults to "pbf"
 	extension string
+	//	high-DPI scale (i.e. 2 for @2x)
+	//	defaults to 1
+	scale uint64
 	//	debug
 	debug bool
 }
@@ -61,7 +63,16 @@ func (req *HandleMapLayerZXY) parseURI(r *http.Request) error {
 	//	trim the "y" param in the url in case it has an extension
 	y := params["y"]
 	yParts := strings.Split(y, ".")
-	req.y, err = strconv.Atoi(yParts[0])
+
+	//	check if we have a high-DPI scale (i.e. @2x)
+	yVal, scale, err := splitScale(yParts[0])
+	if err != nil {
+		log.Warnf("invalid Y value (%v)", y)
+		return fmt.Errorf("invalid Y value (%v)", y)
+	}
+	req.scale = scale
+
+	req.y, err = strconv.Atoi(yVal)
 	if err != nil || req.y < 0 {
 		log.Warnf("invalid Y value (%v)", y)
 		return fmt.Errorf("invalid Y value (%v)", y)
@@ -88,7 +99,7 @@ func (req *HandleMapLayerZXY) parseURI(r *http.Request) error {
 //	z, x, y - tile coordinates as described in the Slippy Map Tilenames specification
 //		z - zoom level
 //		x - row
-//		y - column
+//		y - column. can be suffixed with a high-DPI scale (i.e. 4@2x)
 func (req HandleMapLayerZXY) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	//	parse our URI
 	if err := req.parseURI(r); err != nil {
@@ -105,7 +116,14 @@ func (req HandleMapLayerZXY) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tile := slippy.NewTile(uint64(req.z), uint64(req.x), uint64(req.y), TileBuffer, tegola.WebMercator)
+	m = mapTileBuffer(m)
+
+	tile := slippy.NewTile(uint64(req.z), uint64(req.x), uint64(req.y), float64(m.TileBuffer), m.SRID)
+
+	//	scale the map for high-DPI requests
+	if req.scale > 1 {
+		m = m.ScaleTileSize(req.scale)
+	}
 
 	//	filter down the layers we need for this zoom
 	m = m.FilterLayersByZoom(req.z).FilterLayersByName(req.layerName)
@@ -115,7 +133,7 @@ func (req HandleMapLayerZXY) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		m = m.AddDebugLayers()
 	}
 
-	pbyte, err := m.Encode(r.Context(), tile)
+	pbyte, stats, err := m.EncodeWithStats(r.Context(), tile)
 	if err != nil {
 		switch err {
 		case context.Canceled:
@@ -131,6 +149,17 @@ func (req HandleMapLayerZXY) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	//	mimetype for protocol buffers
 	w.Header().Add("Content-Type", "application/x-protobuf")
+
+	//	communicate features were dropped to fit the map's tile size budget
+	if stats.DroppedFeatures > 0 {
+		w.Header().Add("Tegola-Dropped-Features", strconv.Itoa(stats.DroppedFeatures))
+		log.Infof("tile z:%v, x:%v, y:%v dropped %v features to fit within %v bytes", req.z, req.x, req.y, stats.DroppedFeatures, m.MaxTileBytes)
+	}
+
+	if stats.RepairedGeometries > 0 || stats.InvalidDroppedFeatures > 0 {
+		log.Debugf("tile z:%v, x:%v, y:%v repaired %v invalid geometries and dropped %v features with invalid geometries", req.z, req.x, req.y, stats.RepairedGeometries, stats.InvalidDroppedFeatures)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write(pbyte)
 
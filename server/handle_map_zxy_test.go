@@ -64,6 +64,19 @@ func TestHandleMapZXY(t *testing.T) {
 			reqMethod:    "GET",
 			expectedCode: http.StatusBadRequest,
 		},
+		{ // high-DPI variant
+			uri:            "/maps/test-map/10/2/3@2x.pbf",
+			uriPattern:     "/maps/:map_name/:z/:x/:y",
+			reqMethod:      "GET",
+			expectedCode:   http.StatusOK,
+			expectedLayers: []string{"test-layer-2-name", "test-layer"},
+		},
+		{ // unsupported high-DPI scale
+			uri:          "/maps/test-map/10/2/3@9x.pbf",
+			uriPattern:   "/maps/:map_name/:z/:x/:y",
+			reqMethod:    "GET",
+			expectedCode: http.StatusBadRequest,
+		},
 	}
 
 	for i, test := range testcases {
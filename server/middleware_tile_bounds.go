@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/cache"
+)
+
+//	TileBoundsHandler short circuits requests for tiles the map does not serve so they never reach
+//	the cache or the providers. Tiles outside of the map's zoom range or the tile grid respond with
+//	a 404. Tiles that don't intersect the map's bounds respond with a 204.
+func TileBoundsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//	parse our URI into a tile key (pop off the "maps/" prefix)
+		//	5 is the value of len("maps/")
+		key, err := cache.ParseKey(r.URL.Path[5:])
+		if err != nil {
+			//	let the handler report the malformed request
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		m, err := atlas.GetMap(key.MapName)
+		if err != nil {
+			//	let the handler report the missing map
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if key.Z < 0 || key.X < 0 || key.Y < 0 || !m.ValidTile(uint64(key.Z), uint64(key.X), uint64(key.Y)) {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !m.HasTile(uint64(key.Z), uint64(key.X), uint64(key.Y)) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
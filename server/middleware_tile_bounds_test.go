@@ -0,0 +1,71 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/go-spatial/tegola/server"
+)
+
+func TestMiddlewareTileBoundsHandler(t *testing.T) {
+	testcases := []struct {
+		uri          string
+		uriPattern   string
+		reqHandler   http.Handler
+		expectedCode int
+	}{
+		{
+			uri:          "/maps/test-map/10/2/3.pbf",
+			uriPattern:   "/maps/:map_name/:z/:x/:y",
+			reqHandler:   server.HandleMapZXY{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			uri:          "/maps/test-map/test-layer/4/2/3.pbf",
+			uriPattern:   "/maps/:map_name/:layer_name/:z/:x/:y",
+			reqHandler:   server.HandleMapLayerZXY{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			//	beyond the map's max zoom
+			uri:          "/maps/test-map/23/2/3.pbf",
+			uriPattern:   "/maps/:map_name/:z/:x/:y",
+			reqHandler:   server.HandleMapZXY{},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			//	x is outside of the tile grid for zoom 1
+			uri:          "/maps/test-map/1/2/0.pbf",
+			uriPattern:   "/maps/:map_name/:z/:x/:y",
+			reqHandler:   server.HandleMapZXY{},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			uri:          "/maps/test-map/test-layer/2/0/4.pbf",
+			uriPattern:   "/maps/:map_name/:layer_name/:z/:x/:y",
+			reqHandler:   server.HandleMapLayerZXY{},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tc := range testcases {
+		router := httptreemux.New()
+		group := router.NewGroup("/")
+		group.UsingContext().Handler("GET", tc.uriPattern, server.TileBoundsHandler(tc.reqHandler))
+
+		r, err := http.NewRequest("GET", tc.uri, nil)
+		if err != nil {
+			t.Errorf("[%v] error, expected nil got %v", i, err)
+			continue
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		if w.Code != tc.expectedCode {
+			t.Errorf("[%v] status code, expected %v got %v", i, tc.expectedCode, w.Code)
+		}
+	}
+}
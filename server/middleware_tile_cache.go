@@ -2,8 +2,11 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/go-spatial/tegola/cache"
 	"github.com/go-spatial/tegola/internal/log"
@@ -32,14 +35,21 @@ func TileCacheHandler(next http.Handler) http.Handler {
 			return
 		}
 
+		ttl := tileTTL(key)
+
 		//	use the URL path as the key
-		cachedTile, hit, err := cacher.Get(key)
+		cachedTile, hit, stale, err := cache.GetTTL(cacher, key, ttl)
 		if err != nil {
 			log.Errorf("cache middleware: error reading from cache: %v", err)
 			next.ServeHTTP(w, r)
 			return
 		}
 
+		//	expired tiles are regenerated unless they can be served while they are regenerated
+		if stale && !CacheStaleWhileRevalidate {
+			hit = false
+		}
+
 		//	cache miss
 		if !hit {
 			//	buffer which will hold a copy of the response for writing to the cache
@@ -60,7 +70,7 @@ func TileCacheHandler(next http.Handler) http.Handler {
 				return
 			}
 
-			if err := cacher.Set(key, buff.Bytes()); err != nil {
+			if err := cache.SetTTL(cacher, key, buff.Bytes(), ttl); err != nil {
 				log.Warnf("cache response writer err: %v", err)
 			}
 			return
@@ -70,13 +80,103 @@ func TileCacheHandler(next http.Handler) http.Handler {
 		w.Header().Add("Content-Type", "application/x-protobuf")
 
 		//	communicate the cache is being used
-		w.Header().Add("Tegola-Cache", "HIT")
+		if stale {
+			w.Header().Add("Tegola-Cache", "STALE")
+			revalidateTile(next, r, cacher, key, ttl)
+		} else {
+			w.Header().Add("Tegola-Cache", "HIT")
+		}
 
 		w.Write(cachedTile)
 		return
 	})
 }
 
+//	tileTTL returns how long the tile of the key is cached for. 0 means the tile doesn't expire
+func tileTTL(key *cache.Key) time.Duration {
+	if Atlas == nil {
+		return 0
+	}
+
+	m, err := Atlas.Map(key.MapName)
+	if err != nil {
+		return 0
+	}
+
+	m = m.FilterLayersByZoom(key.Z)
+	if key.LayerName != "" {
+		m = m.FilterLayersByName(key.LayerName)
+	}
+
+	return m.TileTTL()
+}
+
+var (
+	//	the keys of the stale tiles being regenerated, so concurrent requests for a stale tile regenerate it once
+	revalidating   = map[string]struct{}{}
+	revalidatingMu sync.Mutex
+)
+
+//	revalidateTile regenerates the stale tile in the background and writes it to the cache
+func revalidateTile(next http.Handler, r *http.Request, cacher cache.Interface, key *cache.Key, ttl time.Duration) {
+	k := key.String()
+
+	revalidatingMu.Lock()
+	if _, ok := revalidating[k]; ok {
+		revalidatingMu.Unlock()
+		return
+	}
+	revalidating[k] = struct{}{}
+	revalidatingMu.Unlock()
+
+	//	the request is done once the stale tile is served, so the tile is regenerated with
+	//	a context which keeps the request's values (i.e. the URL params) but is not canceled
+	req := r.WithContext(detachedContext{r.Context()})
+	keyCopy := *key
+
+	//	the cache and providers used by the regeneration are released by a reload once it completes
+	done := trackInFlight(r)
+
+	go func() {
+		defer done()
+		defer func() {
+			revalidatingMu.Lock()
+			delete(revalidating, k)
+			revalidatingMu.Unlock()
+		}()
+
+		var buff bytes.Buffer
+		next.ServeHTTP(newTileCacheResponseWriter(discardResponseWriter{header: http.Header{}}, &buff), req)
+
+		if buff.Len() == 0 {
+			return
+		}
+
+		if err := cache.SetTTL(cacher, &keyCopy, buff.Bytes(), ttl); err != nil {
+			log.Warnf("cache middleware: error revalidating tile (%v): %v", k, err)
+		}
+	}()
+}
+
+//	detachedContext keeps the values of its parent but is never canceled
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+//	discardResponseWriter is the response of tiles regenerated in the background
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header         { return w.header }
+func (w discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w discardResponseWriter) WriteHeader(int)             {}
+
 func newTileCacheResponseWriter(resp http.ResponseWriter, w io.Writer) http.ResponseWriter {
 	return &tileCacheResponseWriter{
 		resp:  resp,
@@ -6,6 +6,11 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/cache"
+	"github.com/go-spatial/tegola/cache/memory"
 )
 
 func TestTileCacheResponseWriter(t *testing.T) {
@@ -48,3 +53,56 @@ func TestTileCacheResponseWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestRevalidateTileTrackedByReload(t *testing.T) {
+	//	restore the test atlas once we're done
+	previous := &atlas.Atlas{}
+	previous.Swap(atlas.DefaultAtlas)
+	defer atlas.DefaultAtlas.Swap(previous)
+
+	started, release, regenerated := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	regenerate := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("tile"))
+	})
+
+	key := cache.Key{MapName: "osm", Z: 1, X: 1, Y: 1}
+	cacher := memory.NewCache(memory.DefaultMaxBytes, memory.EvictionLRU, 0)
+
+	//	the request returns once the revalidation is started
+	handler := InFlightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revalidateTile(regenerate, r, cacher, &key, time.Hour)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/maps/osm/1/1/1.pbf", nil))
+	<-started
+
+	reloaded := make(chan struct{})
+	go func() {
+		Reload(&atlas.Atlas{})
+		close(reloaded)
+	}()
+
+	select {
+	case <-reloaded:
+		t.Fatal("reload returned before the tile revalidation completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	go func() {
+		close(release)
+		<-reloaded
+		close(regenerated)
+	}()
+
+	select {
+	case <-regenerated:
+	case <-time.After(time.Second):
+		t.Fatal("reload did not return after the tile revalidation completed")
+	}
+
+	if _, hit, _ := cacher.Get(&key); !hit {
+		t.Errorf("expected the revalidated tile to have been written before the reload returned")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/dimfeld/httptreemux"
+	"github.com/go-spatial/tegola/atlas"
 	"github.com/go-spatial/tegola/server"
 )
 
@@ -70,3 +72,73 @@ func TestMiddlewareTileCacheHandler(t *testing.T) {
 		}
 	}
 }
+
+func TestMiddlewareTileCacheHandlerStale(t *testing.T) {
+	defer func(swr bool) {
+		server.CacheStaleWhileRevalidate = swr
+	}(server.CacheStaleWhileRevalidate)
+
+	//	a map whose tiles expire quickly
+	ttlMap := atlas.NewWebMercatorMap("test-ttl-map")
+	ttlMap.Layers = []atlas.Layer{testLayer1}
+	ttlMap.CacheTTL = 50 * time.Millisecond
+	atlas.AddMap(ttlMap)
+
+	router := httptreemux.New()
+	group := router.NewGroup("/")
+	group.UsingContext().Handler("GET", "/maps/:map_name/:z/:x/:y", server.TileCacheHandler(server.HandleMapZXY{}))
+
+	request := func() string {
+		r, err := http.NewRequest("GET", "/maps/test-ttl-map/5/2/3.pbf", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		return w.Header().Get("Tegola-Cache")
+	}
+
+	testcases := []struct {
+		staleWhileRevalidate bool
+		expected             string
+	}{
+		{
+			staleWhileRevalidate: false,
+			expected:             "MISS",
+		},
+		{
+			staleWhileRevalidate: true,
+			expected:             "STALE",
+		},
+	}
+
+	for i, tc := range testcases {
+		server.CacheStaleWhileRevalidate = tc.staleWhileRevalidate
+
+		//	make sure the tile is cached
+		request()
+		if got := request(); got != "HIT" {
+			t.Errorf("[%v] header Tegola-Cache, expected HIT got %v", i, got)
+			continue
+		}
+
+		time.Sleep(ttlMap.CacheTTL)
+
+		if got := request(); got != tc.expected {
+			t.Errorf("[%v] header Tegola-Cache, expected %v got %v", i, tc.expected, got)
+			continue
+		}
+
+		//	the stale tile is regenerated in the background
+		if tc.staleWhileRevalidate {
+			got := request()
+			for start := time.Now(); got != "HIT" && time.Since(start) < time.Second; got = request() {
+				time.Sleep(time.Millisecond)
+			}
+			if got != "HIT" {
+				t.Errorf("[%v] expected the stale tile to be regenerated got %v", i, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-spatial/tegola/atlas"
+)
+
+//	generation tracks the requests which were started between two atlas reloads
+type generation struct {
+	sync.WaitGroup
+}
+
+var (
+	//	guards current
+	generationMu sync.RWMutex
+	//	the generation new requests are added to
+	current = &generation{}
+)
+
+//	generationKey is the request context key of the request's generation
+type generationKey struct{}
+
+//	InFlightHandler tracks the requests being served so Reload can wait for requests
+//	started before a reload to complete
+func InFlightHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		generationMu.RLock()
+		g := current
+		g.Add(1)
+		generationMu.RUnlock()
+
+		defer g.Done()
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), generationKey{}, g)))
+	})
+}
+
+//	trackInFlight adds work started by the request which outlives it (i.e. the regeneration of a stale tile)
+//	to the request's generation, so Reload waits for the work before the resources it uses are released.
+//	done must be called when the work completes
+func trackInFlight(r *http.Request) (done func()) {
+	g, ok := r.Context().Value(generationKey{}).(*generation)
+	if !ok {
+		//	requests which didn't go through InFlightHandler
+		generationMu.RLock()
+		g = current
+		g.Add(1)
+		generationMu.RUnlock()
+
+		return g.Done
+	}
+
+	//	the request holds the generation open so the work can be added to it
+	g.Add(1)
+	return g.Done
+}
+
+//	Reload atomically swaps the maps and cache backend of the server's atlas with those of a.
+//	Reload blocks until the requests that were started before the swap complete, after which
+//	the resources of the previous maps (i.e. provider connections) can safely be released.
+func Reload(a *atlas.Atlas) {
+	generationMu.Lock()
+	previous := current
+	current = &generation{}
+
+	//	the handlers look maps up from the DefaultAtlas and the cache from Atlas
+	atlas.DefaultAtlas.Swap(a)
+	if Atlas != nil && Atlas != atlas.DefaultAtlas {
+		Atlas.Swap(a)
+	}
+	generationMu.Unlock()
+
+	//	wait for the in-flight requests of the previous generation
+	previous.Wait()
+}
@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/tegola/atlas"
+	"github.com/go-spatial/tegola/server"
+)
+
+func TestReloadWaitsForInFlightRequests(t *testing.T) {
+	//	restore the test atlas once we're done
+	previous := &atlas.Atlas{}
+	previous.Swap(atlas.DefaultAtlas)
+	defer atlas.DefaultAtlas.Swap(previous)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := server.InFlightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-started
+
+	reloaded := make(chan struct{})
+	go func() {
+		server.Reload(&atlas.Atlas{})
+		close(reloaded)
+	}()
+
+	select {
+	case <-reloaded:
+		t.Fatal("reload returned before the in-flight request completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("reload did not return after the in-flight request completed")
+	}
+
+	if len(atlas.AllMaps()) != 0 {
+		t.Errorf("expected the reloaded atlas to have no maps, got %v", len(atlas.AllMaps()))
+	}
+}
@@ -2,7 +2,9 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/dimfeld/httptreemux"
@@ -28,12 +30,26 @@ var (
 	//	the "Access-Control-Allow-Origin" CORS header.
 	//	configurable via the tegola config.toml file (set in main.go)
 	CORSAllowedOrigin = "*"
+	//	CacheStaleWhileRevalidate serves expired tiles from the cache while they are regenerated in the background.
+	//	configurable via the tegola config.toml file (set in main.go)
+	CacheStaleWhileRevalidate bool
 	//	reference to the version of atlas to work with
 	Atlas *atlas.Atlas
-	//	tile buffer to use. can be overwritten in the config file
+	//	TileBuffer is the buffer of the maps which don't set a TileBuffer.
+	//
+	//	Deprecated: set the TileBuffer of the atlas.Map (or tile_buffer in the config file) instead
 	TileBuffer float64 = tegola.DefaultTileBuffer
 )
 
+//	mapTileBuffer returns the map with its TileBuffer defaulted to TileBuffer
+func mapTileBuffer(m atlas.Map) atlas.Map {
+	if m.TileBuffer == 0 {
+		m.TileBuffer = uint64(TileBuffer)
+	}
+
+	return m
+}
+
 //	Start starts the tile server binding to the provided port
 func Start(port string) *http.Server {
 	Atlas = atlas.DefaultAtlas
@@ -51,12 +67,12 @@ func Start(port string) *http.Server {
 	group.UsingContext().Handler("OPTIONS", "/capabilities/:map_name", CORSHandler(HandleMapCapabilities{}))
 
 	//	map tiles
-	group.UsingContext().Handler("GET", "/maps/:map_name/:z/:x/:y", CORSHandler(TileCacheHandler(HandleMapZXY{})))
+	group.UsingContext().Handler("GET", "/maps/:map_name/:z/:x/:y", CORSHandler(TileBoundsHandler(TileCacheHandler(HandleMapZXY{}))))
 	group.UsingContext().Handler("OPTIONS", "/maps/:map_name/:z/:x/:y", CORSHandler(HandleMapZXY{}))
 	group.UsingContext().Handler("GET", "/maps/:map_name/style.json", CORSHandler(HandleMapStyle{}))
 
 	//	map layer tiles
-	group.UsingContext().Handler("GET", "/maps/:map_name/:layer_name/:z/:x/:y", CORSHandler(TileCacheHandler(HandleMapLayerZXY{})))
+	group.UsingContext().Handler("GET", "/maps/:map_name/:layer_name/:z/:x/:y", CORSHandler(TileBoundsHandler(TileCacheHandler(HandleMapLayerZXY{}))))
 	group.UsingContext().Handler("OPTIONS", "/maps/:map_name/:layer_name/:z/:x/:y", CORSHandler(HandleMapLayerZXY{}))
 
 	//	static convenience routes
@@ -64,7 +80,7 @@ func Start(port string) *http.Server {
 	group.UsingContext().Handler("GET", "/*path", http.FileServer(assetFS()))
 
 	//	start our server
-	srv := &http.Server{Addr: port, Handler: r}
+	srv := &http.Server{Addr: port, Handler: InFlightHandler(r)}
 	go func() { log.Error(srv.ListenAndServe()) }()
 	return srv
 }
@@ -114,3 +130,19 @@ func scheme(r *http.Request) string {
 
 	return "http"
 }
+
+//	splitScale splits a high-DPI scale suffix (i.e. "@2x") off of the provided value and returns
+//	the remaining value and the scale. if no suffix is present the scale is 1
+func splitScale(val string) (string, uint64, error) {
+	parts := strings.Split(val, "@")
+	if len(parts) == 1 {
+		return val, 1, nil
+	}
+
+	scale, err := strconv.ParseUint(strings.TrimSuffix(parts[1], "x"), 10, 64)
+	if err != nil || len(parts) > 2 || scale < 1 || scale > tegola.MaxTileScale {
+		return val, 1, fmt.Errorf("invalid scale value (%v)", val)
+	}
+
+	return parts[0], scale, nil
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+
+	"github.com/go-spatial/tegola/atlas"
 )
 
 func TestHostName(t *testing.T) {
@@ -101,3 +103,38 @@ func TestScheme(t *testing.T) {
 		}
 	}
 }
+
+func TestMapTileBuffer(t *testing.T) {
+	type tcase struct {
+		tileBuffer float64
+		m          atlas.Map
+		expected   uint64
+	}
+
+	fn := func(t *testing.T, tc tcase) {
+		defer func(tb float64) { TileBuffer = tb }(TileBuffer)
+		TileBuffer = tc.tileBuffer
+
+		if got := mapTileBuffer(tc.m).TileBuffer; got != tc.expected {
+			t.Errorf("tile buffer, expected %v got %v", tc.expected, got)
+		}
+	}
+
+	tests := map[string]tcase{
+		"map not set": {
+			tileBuffer: 32,
+			m:          atlas.Map{},
+			expected:   32,
+		},
+		"map set": {
+			tileBuffer: 32,
+			m:          atlas.Map{TileBuffer: 16},
+			expected:   16,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) { fn(t, tc) })
+	}
+}
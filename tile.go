@@ -11,6 +11,8 @@ const (
 	DefaultEpislon    = 10.0
 	DefaultExtent     = 4096
 	DefaultTileBuffer = 64.0
+	DefaultTileSize   = 256
+	MaxTileScale      = 4
 	MaxZ              = 22
 )
 